@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"casino_backend/internal/service/line"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// writeMarkdown PAR sheet каждого пресета отдельным разделом
+func writeMarkdown(w io.Writer, sheets []*line.ParSheet) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range sheets {
+		fmt.Fprintf(bw, "# PAR sheet: #%d %s\n\n", s.Index, s.Preset)
+
+		fmt.Fprintf(bw, "## Summary\n\n")
+		fmt.Fprintf(bw, "| Metric | Value |\n|---|---|\n")
+		fmt.Fprintf(bw, "| Paylines | %d |\n", s.Lines)
+		fmt.Fprintf(bw, "| Base game RTP (lines) | %s |\n", pct(s.LineRTP))
+		fmt.Fprintf(bw, "| Base game RTP (scatter) | %s |\n", pct(s.ScatterRTP))
+		fmt.Fprintf(bw, "| Base game RTP | %s |\n", pct(s.BaseRTP))
+		fmt.Fprintf(bw, "| Free spins trigger probability | %s (1 in %s) |\n", prob(s.FreeSpinTrigger), oneIn(s.FreeSpinTrigger))
+		fmt.Fprintf(bw, "| Free spins awarded per spin | %s |\n", num(s.FreeSpinsPerSpin))
+		fmt.Fprintf(bw, "| Free spins per trigger (incl. retriggers) | %s |\n", num(s.FreeSpinsPerTrigger))
+		fmt.Fprintf(bw, "| Expected free spins value per trigger (x bet) | %s |\n", num(s.FreeSpinValue))
+		fmt.Fprintf(bw, "| Free spins RTP | %s |\n", pct(s.FreeSpinRTP))
+		fmt.Fprintf(bw, "| Total RTP | %s |\n", pct(s.TotalRTP))
+		fmt.Fprintf(bw, "| Max theoretical win (x bet) | %s |\n", num(s.MaxTheoreticalPayout))
+		fmt.Fprintf(bw, "| Max win cap never binds | %t |\n\n", s.MaxWinCapNeverBinds)
+		if s.FreeSpinsUnbounded {
+			fmt.Fprintf(bw, "> Retriggers award at least one free spin per free spin on average: the free spins RTP diverges.\n\n")
+		}
+
+		symbols := reelSymbols(s)
+		fmt.Fprintf(bw, "## Symbol probabilities per reel position\n\n")
+		fmt.Fprintf(bw, "| Reel | Row |")
+		for _, sym := range symbols {
+			fmt.Fprintf(bw, " %s |", sym)
+		}
+		fmt.Fprintf(bw, "\n|---|---|")
+		for range symbols {
+			fmt.Fprintf(bw, "---|")
+		}
+		fmt.Fprintln(bw)
+		for r := range s.Reels {
+			for row := range s.Reels[r] {
+				fmt.Fprintf(bw, "| %d | %d |", r+1, row+1)
+				for _, sym := range symbols {
+					fmt.Fprintf(bw, " %s |", prob(s.Reels[r][row][sym]))
+				}
+				fmt.Fprintln(bw)
+			}
+		}
+		fmt.Fprintln(bw)
+
+		fmt.Fprintf(bw, "## Line pays\n\n")
+		fmt.Fprintf(bw, "| Symbol | Count | Pay (%% of bet) | Hits per spin | Hit cycle | RTP |\n|---|---|---|---|---|---|\n")
+		for _, p := range s.LinePays {
+			fmt.Fprintf(bw, "| %s | %d | %d | %s | %s | %s |\n", p.Symbol, p.Count, p.Pay, prob(p.HitsPerSpin), oneIn(p.HitsPerSpin), pct(p.RTP))
+		}
+		fmt.Fprintln(bw)
+
+		fmt.Fprintf(bw, "## RTP per symbol\n\n")
+		fmt.Fprintf(bw, "| Symbol | RTP |\n|---|---|\n")
+		for _, p := range s.Symbols {
+			fmt.Fprintf(bw, "| %s | %s |\n", p.Symbol, pct(p.RTP))
+		}
+		fmt.Fprintf(bw, "| B (scatter) | %s |\n\n", pct(s.ScatterRTP))
+
+		fmt.Fprintf(bw, "## Scatter\n\n")
+		fmt.Fprintf(bw, "| Count | Probability | Hit cycle | Pay (%% of bet) | Free spins | RTP |\n|---|---|---|---|---|---|\n")
+		for _, p := range s.Scatters {
+			fmt.Fprintf(bw, "| %d | %s | %s | %d | %d | %s |\n", p.Count, prob(p.Probability), oneIn(p.Probability), p.Pay, p.FreeSpins, pct(p.RTP))
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// writeCSV все пресеты одной таблицей в длинном формате: preset_index, preset, section, key, count, metric, value
+func writeCSV(w io.Writer, sheets []*line.ParSheet) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"preset_index", "preset", "section", "key", "count", "metric", "value"}); err != nil {
+		return err
+	}
+
+	for _, s := range sheets {
+		idx := strconv.Itoa(s.Index)
+		row := func(section, key, count, metric string, value float64) {
+			_ = cw.Write([]string{idx, s.Preset, section, key, count, metric, strconv.FormatFloat(value, 'g', -1, 64)})
+		}
+
+		row("summary", "", "", "paylines", float64(s.Lines))
+		row("summary", "", "", "line_rtp", s.LineRTP)
+		row("summary", "", "", "scatter_rtp", s.ScatterRTP)
+		row("summary", "", "", "base_rtp", s.BaseRTP)
+		row("summary", "", "", "free_spins_trigger", s.FreeSpinTrigger)
+		row("summary", "", "", "free_spins_per_spin", s.FreeSpinsPerSpin)
+		row("summary", "", "", "free_spins_per_trigger", s.FreeSpinsPerTrigger)
+		row("summary", "", "", "free_spins_value", s.FreeSpinValue)
+		row("summary", "", "", "free_spins_rtp", s.FreeSpinRTP)
+		row("summary", "", "", "total_rtp", s.TotalRTP)
+		row("summary", "", "", "max_theoretical_payout", s.MaxTheoreticalPayout)
+
+		for r := range s.Reels {
+			for pos := range s.Reels[r] {
+				for _, sym := range slices.Sorted(maps.Keys(s.Reels[r][pos])) {
+					row("reel", sym, "", fmt.Sprintf("reel%d_row%d", r+1, pos+1), s.Reels[r][pos][sym])
+				}
+			}
+		}
+
+		for _, p := range s.LinePays {
+			count := strconv.Itoa(p.Count)
+			row("line", p.Symbol, count, "pay", float64(p.Pay))
+			row("line", p.Symbol, count, "hits_per_spin", p.HitsPerSpin)
+			row("line", p.Symbol, count, "rtp", p.RTP)
+		}
+
+		for _, p := range s.Symbols {
+			row("symbol", p.Symbol, "", "rtp", p.RTP)
+		}
+
+		for _, p := range s.Scatters {
+			count := strconv.Itoa(p.Count)
+			row("scatter", "B", count, "probability", p.Probability)
+			row("scatter", "B", count, "pay", float64(p.Pay))
+			row("scatter", "B", count, "free_spins", float64(p.FreeSpins))
+			row("scatter", "B", count, "rtp", p.RTP)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// reelSymbols все символы, встречающиеся на барабанах, по порядку
+func reelSymbols(s *line.ParSheet) []string {
+	set := make(map[string]struct{})
+	for r := range s.Reels {
+		for row := range s.Reels[r] {
+			for sym := range s.Reels[r][row] {
+				set[sym] = struct{}{}
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+func pct(v float64) string {
+	return strconv.FormatFloat(v*100, 'f', 4, 64) + "%"
+}
+
+func prob(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+// oneIn частота события в виде «1 из N»
+func oneIn(p float64) string {
+	if p <= 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(1/p, 'g', 6, 64)
+}
@@ -0,0 +1,83 @@
+// Генератор PAR sheet для пресетов line-слота.
+// Считает точные (комбинаторные) вероятности по весам символов, PlayLines и таблице выплат пресета.
+//
+//	go run ./cmd/parsheet -index 10
+//	go run ./cmd/parsheet -format csv -out parsheet.csv
+package main
+
+import (
+	"casino_backend/internal/config/env"
+	"casino_backend/internal/service/line"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Сколько конфигов ждём в YAML (как в ServiceProvider)
+const lineConfigCount = 20
+
+type options struct {
+	index   int
+	format  string
+	out     string
+	lineCfg string
+}
+
+func main() {
+	var opts options
+	flag.IntVar(&opts.index, "index", -1, "индекс пресета (-1 — все по очереди)")
+	flag.StringVar(&opts.format, "format", "md", "формат: md или csv")
+	flag.StringVar(&opts.out, "out", "", "файл для результата (по умолчанию stdout)")
+	flag.StringVar(&opts.lineCfg, "line-config", "config-line.yaml", "путь к конфигу line-слота")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	var write func(w io.Writer, sheets []*line.ParSheet) error
+	switch opts.format {
+	case "md":
+		write = writeMarkdown
+	case "csv":
+		write = writeCSV
+	default:
+		return fmt.Errorf("unknown format %q: expected md or csv", opts.format)
+	}
+
+	cfg, err := env.NewLineConfigFromYAML(opts.lineCfg, lineConfigCount)
+	if err != nil {
+		return err
+	}
+	engine := line.NewEngine(cfg)
+
+	var sheets []*line.ParSheet
+	if opts.index >= 0 {
+		if opts.index >= engine.Count() {
+			return fmt.Errorf("index %d out of range [0, %d)", opts.index, engine.Count())
+		}
+		sheets = append(sheets, engine.ParSheet(opts.index))
+	} else {
+		for i := 0; i < engine.Count(); i++ {
+			sheets = append(sheets, engine.ParSheet(i))
+		}
+	}
+
+	if opts.out == "" {
+		return write(os.Stdout, sheets)
+	}
+
+	f, err := os.Create(opts.out)
+	if err != nil {
+		return err
+	}
+	if err := write(f, sheets); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	return f.Close()
+}
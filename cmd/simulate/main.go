@@ -0,0 +1,226 @@
+// Офлайн-симулятор Монте-Карло для line и cascade слотов.
+// Гоняет раунды через ту же математику, что и сервер (SpinOnce / resolveSpin), без БД и кошелька.
+//
+//	go run ./cmd/simulate -game line -rounds 10000000 -index 10
+//	go run ./cmd/simulate -game cascade -seed 42 -json
+package main
+
+import (
+	"casino_backend/internal/config/env"
+	"casino_backend/internal/service/cascade"
+	"casino_backend/internal/service/line"
+	"casino_backend/pkg/rng"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+)
+
+const (
+	// Предел фриспинов за один раунд (защита от бесконечных ретриггеров в кривом конфиге)
+	maxFreeSpinsPerRound = 10000
+	// Сколько конфигов ждём в YAML (как в ServiceProvider)
+	lineConfigCount    = 20
+	cascadeConfigCount = 20
+)
+
+// game Движок игры, через который симулятор гоняет спины
+type game interface {
+	Count() int
+	Name(idx int) string
+	// spin возвращает выигрыш спина и количество начисленных фриспинов
+	spin(rnd rng.RNG, bet, idx int, freeSpin bool) (win, awarded int, err error)
+}
+
+type lineGame struct{ *line.Engine }
+
+func (g lineGame) spin(rnd rng.RNG, bet, idx int, _ bool) (int, int, error) {
+	res, err := g.Spin(rnd, bet, idx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return res.TotalPayout, res.AwardedFreeSpins, nil
+}
+
+type cascadeGame struct{ *cascade.Engine }
+
+func (g cascadeGame) spin(rnd rng.RNG, bet, idx int, freeSpin bool) (int, int, error) {
+	res := g.Spin(rnd, bet, idx, freeSpin)
+	return res.TotalPayout, res.AwardedFreeSpins, nil
+}
+
+type options struct {
+	game       string
+	rounds     int64
+	index      int
+	seed       uint64
+	bet        int
+	workers    int
+	json       bool
+	lineCfg    string
+	cascadeCfg string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.game, "game", "line", "игра: line или cascade")
+	flag.Int64Var(&opts.rounds, "rounds", 1_000_000, "количество раундов на конфиг")
+	flag.IntVar(&opts.index, "index", -1, "индекс пресета / конфига (-1 — все по очереди)")
+	flag.Uint64Var(&opts.seed, "seed", 0, "seed генератора (0 — криптостойкий генератор, результат не воспроизводится)")
+	flag.IntVar(&opts.bet, "bet", 100, "ставка (положительная и чётная)")
+	flag.IntVar(&opts.workers, "workers", runtime.NumCPU(), "количество параллельных воркеров")
+	flag.BoolVar(&opts.json, "json", false, "вывести результат в JSON")
+	flag.StringVar(&opts.lineCfg, "line-config", "config-line.yaml", "путь к конфигу line-слота")
+	flag.StringVar(&opts.cascadeCfg, "cascade-config", "config-cascade.yaml", "путь к конфигу каскадного слота")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	if opts.rounds <= 0 {
+		return errors.New("rounds must be positive")
+	}
+	if opts.bet <= 0 || opts.bet%2 != 0 {
+		return errors.New("bet must be positive and even")
+	}
+	if opts.workers <= 0 {
+		opts.workers = 1
+	}
+
+	// Каждый воркер получает свой движок: у каскадного слота есть состояние множителей
+	var newGame func() game
+	switch opts.game {
+	case "line":
+		cfg, err := env.NewLineConfigFromYAML(opts.lineCfg, lineConfigCount)
+		if err != nil {
+			return err
+		}
+		newGame = func() game { return lineGame{line.NewEngine(cfg)} }
+	case "cascade":
+		cfg, err := env.NewCascadeConfigFromYAML(opts.cascadeCfg, cascadeConfigCount)
+		if err != nil {
+			return err
+		}
+		newGame = func() game { return cascadeGame{cascade.NewEngine(cfg)} }
+	default:
+		return fmt.Errorf("unknown game %q: expected line or cascade", opts.game)
+	}
+
+	g := newGame()
+	indexes := make([]int, 0, g.Count())
+	if opts.index >= 0 {
+		if opts.index >= g.Count() {
+			return fmt.Errorf("index %d out of range [0, %d)", opts.index, g.Count())
+		}
+		indexes = append(indexes, opts.index)
+	} else {
+		for i := 0; i < g.Count(); i++ {
+			indexes = append(indexes, i)
+		}
+	}
+
+	results := make([]*Stats, 0, len(indexes))
+	for _, idx := range indexes {
+		stats, err := simulate(opts, newGame, idx)
+		if err != nil {
+			return err
+		}
+		results = append(results, stats)
+		if !opts.json {
+			stats.writeText(os.Stdout)
+			fmt.Println()
+		}
+	}
+
+	if opts.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	return nil
+}
+
+// simulate прогоняет раунды одного конфига на нескольких воркерах и сводит статистику
+func simulate(opts options, newGame func() game, idx int) (*Stats, error) {
+	g := newGame()
+	total := newStats(opts.game, idx, g.Name(idx), opts.bet)
+
+	var (
+		wg       sync.WaitGroup
+		mtx      sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < opts.workers; w++ {
+		// Делим раунды поровну, остаток достаётся первым воркерам
+		n := opts.rounds / int64(opts.workers)
+		if int64(w) < opts.rounds%int64(opts.workers) {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(w int, n int64) {
+			defer wg.Done()
+			stats, err := worker(newGame(), workerRNG(opts.seed, idx, w), opts.bet, idx, n, total)
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			total.merge(stats)
+		}(w, n)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	total.finalize()
+	return total, nil
+}
+
+// worker играет n раундов: платный спин и все фриспины, которые он запустил
+func worker(g game, rnd rng.RNG, bet, idx int, n int64, proto *Stats) (*Stats, error) {
+	stats := newStats(proto.Game, proto.ConfigIndex, proto.ConfigName, bet)
+	for i := int64(0); i < n; i++ {
+		win, freeSpins, err := g.spin(rnd, bet, idx, false)
+		if err != nil {
+			return nil, err
+		}
+		triggered := freeSpins > 0
+
+		played := 0
+		for freeSpins > 0 && played < maxFreeSpinsPerRound {
+			fsWin, awarded, err := g.spin(rnd, bet, idx, true)
+			if err != nil {
+				return nil, err
+			}
+			win += fsWin
+			freeSpins += awarded - 1
+			played++
+		}
+
+		stats.add(win, triggered, played)
+	}
+	return stats, nil
+}
+
+// workerRNG генератор воркера: при заданном seed у каждого воркера и конфига свой воспроизводимый поток
+func workerRNG(seed uint64, idx, w int) rng.RNG {
+	if seed == 0 {
+		return rng.NewCrypto()
+	}
+	return rng.NewSeeded(seed + uint64(idx)<<32 + uint64(w))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"casino_backend/pkg/rng"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// fakeGame выигрыш и фриспины берутся из RNG: результат зависит только от seed
+type fakeGame struct{}
+
+func (fakeGame) Count() int      { return 1 }
+func (fakeGame) Name(int) string { return "fake" }
+func (fakeGame) spin(rnd rng.RNG, bet, _ int, _ bool) (int, int, error) {
+	win := rnd.Intn(3) * bet / 2
+	awarded := 0
+	if rnd.Intn(20) == 0 {
+		awarded = 3
+	}
+	return win, awarded, nil
+}
+
+// endlessGame каждый фриспин начисляет ещё один: серия не заканчивается сама
+type endlessGame struct{ fakeGame }
+
+func (endlessGame) spin(_ rng.RNG, bet, _ int, _ bool) (int, int, error) {
+	return bet, 1, nil
+}
+
+func TestStatsFinalize(t *testing.T) {
+	const bet = 100
+	s := newStats("line", 0, "test", bet)
+	s.add(0, false, 0)
+	s.add(200, true, 10)
+	s.add(50, false, 0)
+	s.add(1000, false, 0)
+	s.finalize()
+
+	// Выигрыши в ставках: 0, 2, 0.5, 10
+	if s.Rounds != 4 || s.TotalBet != 400 || s.TotalWin != 1250 || s.FreeSpinsPlayed != 10 {
+		t.Fatalf("totals = %d rounds, bet %d, win %d, free spins %d", s.Rounds, s.TotalBet, s.TotalWin, s.FreeSpinsPlayed)
+	}
+	if s.RTP != 312.5 {
+		t.Errorf("RTP = %v, want 312.5", s.RTP)
+	}
+	if s.HitFrequency != 75 {
+		t.Errorf("HitFrequency = %v, want 75", s.HitFrequency)
+	}
+	if s.FreeSpinTriggerRate != 25 {
+		t.Errorf("FreeSpinTriggerRate = %v, want 25", s.FreeSpinTriggerRate)
+	}
+	if want := math.Sqrt(104.25/4 - 3.125*3.125); math.Abs(s.Volatility-want) > 1e-12 {
+		t.Errorf("Volatility = %v, want %v", s.Volatility, want)
+	}
+	if s.MaxWin != 10 {
+		t.Errorf("MaxWin = %v, want 10", s.MaxWin)
+	}
+
+	wantBuckets := map[string]int64{"0": 1, "0-1": 1, "2-5": 1, "10-50": 1}
+	for _, b := range s.Buckets {
+		if b.Count != wantBuckets[b.Label] {
+			t.Errorf("bucket %s = %d, want %d", b.Label, b.Count, wantBuckets[b.Label])
+		}
+	}
+}
+
+func TestSimulateReproducibleWithSeed(t *testing.T) {
+	opts := options{game: "fake", rounds: 10_001, seed: 42, bet: 100, workers: 3}
+	newGame := func() game { return fakeGame{} }
+
+	first, err := simulate(opts, newGame, 0)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	second, err := simulate(opts, newGame, 0)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+
+	if first.Rounds != opts.rounds {
+		t.Fatalf("Rounds = %d, want %d (split between workers)", first.Rounds, opts.rounds)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("same seed gave different results:\n%+v\n%+v", first, second)
+	}
+
+	opts.seed++
+	other, err := simulate(opts, newGame, 0)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	if other.TotalWin == first.TotalWin && other.FreeSpinsPlayed == first.FreeSpinsPlayed {
+		t.Fatal("different seeds gave identical results")
+	}
+}
+
+func TestWorkerCapsEndlessFreeSpins(t *testing.T) {
+	const bet = 100
+	proto := newStats("fake", 0, "endless", bet)
+
+	stats, err := worker(endlessGame{}, rng.NewSeeded(1), bet, 0, 1, proto)
+	if err != nil {
+		t.Fatalf("worker: %v", err)
+	}
+	if stats.FreeSpinsPlayed != maxFreeSpinsPerRound {
+		t.Fatalf("FreeSpinsPlayed = %d, want cap %d", stats.FreeSpinsPlayed, maxFreeSpinsPerRound)
+	}
+	if want := int64(bet * (1 + maxFreeSpinsPerRound)); stats.TotalWin != want {
+		t.Fatalf("TotalWin = %d, want %d", stats.TotalWin, want)
+	}
+}
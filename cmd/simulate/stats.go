@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// bucketBounds Границы корзин распределения выигрышей (в кратности ставки): [from, to)
+var bucketBounds = [][2]float64{
+	{0, 0},
+	{0, 1},
+	{1, 2},
+	{2, 5},
+	{5, 10},
+	{10, 50},
+	{50, 100},
+	{100, 500},
+	{500, 1000},
+	{1000, math.Inf(1)},
+}
+
+// Bucket Корзина распределения выигрышей за раунд
+type Bucket struct {
+	Label string  `json:"label"`
+	Count int64   `json:"count"`
+	Share float64 `json:"share"` // Доля раундов, %
+}
+
+// Stats Результаты симуляции одного конфига.
+// Раунд — платный спин вместе со всеми фриспинами, которые он запустил
+type Stats struct {
+	Game        string `json:"game"`
+	ConfigIndex int    `json:"config_index"`
+	ConfigName  string `json:"config_name"`
+	Rounds      int64  `json:"rounds"`
+	Bet         int    `json:"bet"`
+
+	TotalBet            int64    `json:"total_bet"`
+	TotalWin            int64    `json:"total_win"`
+	RTP                 float64  `json:"rtp"`                    // %
+	HitFrequency        float64  `json:"hit_frequency"`          // Доля раундов с выигрышем, %
+	Volatility          float64  `json:"volatility"`             // Стандартное отклонение выигрыша за раунд в ставках
+	FreeSpinTriggerRate float64  `json:"free_spin_trigger_rate"` // Доля раундов, запустивших фриспины, %
+	FreeSpinsPlayed     int64    `json:"free_spins_played"`
+	MaxWin              float64  `json:"max_win"` // Максимальный выигрыш за раунд в ставках
+	Buckets             []Bucket `json:"buckets"`
+
+	hits     int64
+	triggers int64
+	sum      float64 // Сумма выигрышей в ставках
+	sumSq    float64 // Сумма квадратов выигрышей в ставках
+	counts   []int64
+}
+
+func newStats(game string, idx int, name string, bet int) *Stats {
+	return &Stats{
+		Game:        game,
+		ConfigIndex: idx,
+		ConfigName:  name,
+		Bet:         bet,
+		counts:      make([]int64, len(bucketBounds)),
+	}
+}
+
+// add учитывает сыгранный раунд
+func (s *Stats) add(win int, triggered bool, freeSpins int) {
+	x := float64(win) / float64(s.Bet)
+
+	s.Rounds++
+	s.TotalBet += int64(s.Bet)
+	s.TotalWin += int64(win)
+	s.FreeSpinsPlayed += int64(freeSpins)
+	s.sum += x
+	s.sumSq += x * x
+	if win > 0 {
+		s.hits++
+	}
+	if triggered {
+		s.triggers++
+	}
+	if x > s.MaxWin {
+		s.MaxWin = x
+	}
+	s.counts[bucketIndex(x)]++
+}
+
+// merge добавляет результаты другого воркера
+func (s *Stats) merge(o *Stats) {
+	s.Rounds += o.Rounds
+	s.TotalBet += o.TotalBet
+	s.TotalWin += o.TotalWin
+	s.FreeSpinsPlayed += o.FreeSpinsPlayed
+	s.hits += o.hits
+	s.triggers += o.triggers
+	s.sum += o.sum
+	s.sumSq += o.sumSq
+	s.MaxWin = math.Max(s.MaxWin, o.MaxWin)
+	for i := range s.counts {
+		s.counts[i] += o.counts[i]
+	}
+}
+
+// finalize считает итоговые показатели
+func (s *Stats) finalize() {
+	if s.Rounds == 0 {
+		return
+	}
+	n := float64(s.Rounds)
+	s.RTP = float64(s.TotalWin) / float64(s.TotalBet) * 100
+	s.HitFrequency = float64(s.hits) / n * 100
+	s.FreeSpinTriggerRate = float64(s.triggers) / n * 100
+
+	mean := s.sum / n
+	s.Volatility = math.Sqrt(math.Max(s.sumSq/n-mean*mean, 0))
+
+	s.Buckets = make([]Bucket, len(bucketBounds))
+	for i, b := range bucketBounds {
+		s.Buckets[i] = Bucket{
+			Label: bucketLabel(b),
+			Count: s.counts[i],
+			Share: float64(s.counts[i]) / n * 100,
+		}
+	}
+}
+
+// writeText печатает отчёт в человекочитаемом виде
+func (s *Stats) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s #%d %s\n", s.Game, s.ConfigIndex, s.ConfigName)
+	fmt.Fprintf(w, "  rounds:            %d (bet %d, free spins played %d)\n", s.Rounds, s.Bet, s.FreeSpinsPlayed)
+	fmt.Fprintf(w, "  RTP:               %.3f%%\n", s.RTP)
+	fmt.Fprintf(w, "  hit frequency:     %.3f%%\n", s.HitFrequency)
+	fmt.Fprintf(w, "  volatility (sd):   %.3f x bet\n", s.Volatility)
+	fmt.Fprintf(w, "  free spin trigger: %.4f%% (1 in %s)\n", s.FreeSpinTriggerRate, oneIn(s.FreeSpinTriggerRate))
+	fmt.Fprintf(w, "  max win:           %.2f x bet\n", s.MaxWin)
+	fmt.Fprintln(w, "  win distribution (x bet):")
+	for _, b := range s.Buckets {
+		fmt.Fprintf(w, "    %-12s %12d  %8.4f%%  %s\n", b.Label, b.Count, b.Share, strings.Repeat("#", int(math.Ceil(b.Share/2))))
+	}
+}
+
+func bucketIndex(x float64) int {
+	if x == 0 {
+		return 0
+	}
+	for i := 1; i < len(bucketBounds); i++ {
+		if x < bucketBounds[i][1] {
+			return i
+		}
+	}
+	return len(bucketBounds) - 1
+}
+
+func bucketLabel(b [2]float64) string {
+	switch {
+	case b[0] == b[1]:
+		return fmt.Sprintf("%g", b[0])
+	case math.IsInf(b[1], 1):
+		return fmt.Sprintf("%g+", b[0])
+	default:
+		return fmt.Sprintf("%g-%g", b[0], b[1])
+	}
+}
+
+func oneIn(percent float64) string {
+	if percent == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", 100/percent)
+}
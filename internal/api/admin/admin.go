@@ -0,0 +1,151 @@
+package admin
+
+import (
+	dto "casino_backend/internal/api/dto/admin"
+	"casino_backend/internal/converter"
+	"casino_backend/internal/middleware"
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/req"
+	"casino_backend/pkg/resp"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type HandlerDeps struct {
+	Serv service.RTPAdminService
+}
+
+type Handler struct {
+	serv service.RTPAdminService
+}
+
+func NewHandler(deps HandlerDeps) *Handler {
+	return &Handler{serv: deps.Serv}
+}
+
+// State возвращает состояние регулятора RTP игры {game}.
+// Параметр запроса `segment` — сегмент игроков (по умолчанию — сегмент default)
+func (h *Handler) State(w http.ResponseWriter, r *http.Request) {
+	st, err := h.serv.State(r.Context(), model.Game(chi.URLParam(r, "game")), r.URL.Query().Get("segment"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRTPOverride) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToRTPStateResponse(*st))
+}
+
+// SetIndex закрепляет конфиг игры {game} и ставит автокорректировку на паузу
+func (h *Handler) SetIndex(w http.ResponseWriter, r *http.Request) {
+	payload, err := req.Decode[dto.SetIndexRequest](r.Body)
+	if err != nil || payload.Index == nil {
+		http.Error(w, "index is required", http.StatusBadRequest)
+		return
+	}
+
+	h.override(w, r, model.RTPOverride{Action: model.RTPActionSetIndex, Index: *payload.Index})
+}
+
+// SetTargetRTP меняет целевой RTP регулятора игры {game}
+func (h *Handler) SetTargetRTP(w http.ResponseWriter, r *http.Request) {
+	payload, err := req.Decode[dto.SetTargetRTPRequest](r.Body)
+	if err != nil || payload.TargetRTP == nil {
+		http.Error(w, "target_rtp is required", http.StatusBadRequest)
+		return
+	}
+
+	h.override(w, r, model.RTPOverride{Action: model.RTPActionSetTargetRTP, Target: *payload.TargetRTP})
+}
+
+// SetWindowSize меняет размер окна регулятора игры {game}
+func (h *Handler) SetWindowSize(w http.ResponseWriter, r *http.Request) {
+	payload, err := req.Decode[dto.SetWindowSizeRequest](r.Body)
+	if err != nil || payload.WindowSize == nil {
+		http.Error(w, "window_size is required", http.StatusBadRequest)
+		return
+	}
+
+	h.override(w, r, model.RTPOverride{Action: model.RTPActionSetWindowSize, Window: *payload.WindowSize})
+}
+
+// SetPaused ставит на паузу или возобновляет автокорректировку регулятора игры {game}
+func (h *Handler) SetPaused(w http.ResponseWriter, r *http.Request) {
+	payload, err := req.Decode[dto.SetPausedRequest](r.Body)
+	if err != nil || payload.Paused == nil {
+		http.Error(w, "paused is required", http.StatusBadRequest)
+		return
+	}
+
+	h.override(w, r, model.RTPOverride{Action: model.RTPActionSetPaused, Paused: *payload.Paused})
+}
+
+// override применяет изменение к игре из пути и сегменту из параметра `segment`
+// и возвращает новое состояние регулятора
+func (h *Handler) override(w http.ResponseWriter, r *http.Request, o model.RTPOverride) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+	// В журнале изменение подписывается администратором, который его внёс
+	o.Actor = "user:" + strconv.Itoa(userID)
+	o.Game = model.Game(chi.URLParam(r, "game"))
+	o.Segment = r.URL.Query().Get("segment")
+
+	err := h.serv.Override(r.Context(), o)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRTPOverride) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.State(w, r)
+}
+
+// AuditLog возвращает журнал изменений регуляторов от новых к старым.
+// Параметры запроса: `game` — фильтр по игре, `cursor` — next_cursor из предыдущего ответа, `limit` — размер страницы.
+func (h *Handler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	var (
+		cursor int64
+		limit  int
+		err    error
+	)
+	game := model.Game(r.URL.Query().Get("game"))
+	if game != "" && game != model.GameLine && game != model.GameCascade {
+		http.Error(w, "invalid game", http.StatusBadRequest)
+		return
+	}
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		cursor, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || cursor < 0 {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.serv.AuditLog(r.Context(), game, cursor, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToAuditLogResponse(*page))
+}
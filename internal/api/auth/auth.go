@@ -3,29 +3,47 @@ package auth
 import (
 	dto "casino_backend/internal/api/dto/auth"
 	"casino_backend/internal/converter"
+	"casino_backend/internal/middleware"
 	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
 	"casino_backend/internal/service"
 	"casino_backend/pkg/req"
 	"casino_backend/pkg/resp"
+	"errors"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type HandlerDeps struct {
-	Serv service.AuthService
+	Serv          service.AuthService
+	TwoFactorServ service.TwoFactorService
+	PasswordServ  service.PasswordService
 }
 
 type Handler struct {
-	serv service.AuthService
+	serv          service.AuthService
+	twoFactorServ service.TwoFactorService
+	passwordServ  service.PasswordService
 }
 
 func NewHandler(deps HandlerDeps) *Handler {
-	return &Handler{serv: deps.Serv}
+	return &Handler{
+		serv:          deps.Serv,
+		twoFactorServ: deps.TwoFactorServ,
+		passwordServ:  deps.PasswordServ,
+	}
 }
 
 // Register создаёт нового пользователя и создаёт сессию.
 // Возвращает в теле JSON с полем `access_token` (HTTP 201).
 // `refresh_token` и `session_id` устанавливаются через cookie.
+// Недопустимые логин, имя или пароль — HTTP 400 с причиной в теле.
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	requestBody, err := req.Decode[dto.RegisterRequest](r.Body)
 	if err != nil {
@@ -37,10 +55,18 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	data, err := h.serv.Register(
 		r.Context(),
 		converter.RegisterRequestToUserModel(&requestBody),
+		clientInfo(r, requestBody.Device),
 	)
 	if err != nil {
 		log.Println("Register error:", err)
-		http.Error(w, "register failed", http.StatusConflict)
+		switch {
+		case errors.Is(err, service.ErrInvalidLogin),
+			errors.Is(err, service.ErrInvalidName),
+			errors.Is(err, service.ErrInvalidPassword):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "register failed", http.StatusConflict)
+		}
 		return
 	}
 
@@ -55,6 +81,10 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 
 // Login создаёт сессию и возвращает access_token в теле ответа (HTTP 200).
 // `refresh_token` и `session_id` устанавливаются через cookie.
+// Неизвестный логин и неверный пароль неразличимы (HTTP 401); после серии неудач
+// вход временно блокируется (HTTP 429 с заголовком Retry-After).
+// С включённой двухфакторной аутентификацией сессия не создаётся: в ответе `two_factor_required`
+// и `pre_auth_token` для LoginTwoFactor.
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	requestBody, err := req.Decode[dto.LoginRequest](r.Body)
 	if err != nil {
@@ -66,10 +96,27 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	data, err := h.serv.Login(
 		r.Context(),
 		converter.LoginRequestToUserModel(&requestBody),
+		clientInfo(r, requestBody.Device),
 	)
 	if err != nil {
 		log.Println("Login error:", err)
-		http.Error(w, "login failed", http.StatusUnauthorized)
+		var locked *service.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			writeLoginLocked(w, locked)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			http.Error(w, service.ErrInvalidCredentials.Error(), http.StatusUnauthorized)
+		default:
+			http.Error(w, "login failed", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if data.PreAuthToken != "" {
+		resp.WriteJSONResponse(w, http.StatusOK, dto.TwoFactorRequiredResponse{
+			TwoFactorRequired: true,
+			PreAuthToken:      data.PreAuthToken,
+		})
 		return
 	}
 
@@ -83,8 +130,9 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 }
 
 // Refresh обновляет access_token по session_id.
-// Ожидает cookie `session_id`. Вызывает сервис обновления и устанавливает обновлённый
-// `refresh_token` через cookie. Возвращает HTTP 200 без тела.
+// Ожидает cookie `session_id` и `refresh_token`. Refresh токен одноразовый: новый устанавливается через cookie.
+// Повторное предъявление старого токена завершает сессию — cookie удаляются.
+// Возвращает в теле JSON с полем `access_token` (HTTP 201).
 func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 	cs, err := r.Cookie("session_id")
 	if err != nil {
@@ -101,19 +149,26 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 	sessionID := cs.Value
 	refreshToken := cr.Value
 
-	accessToken, err := h.serv.Refresh(r.Context(),
+	data, err := h.serv.Refresh(r.Context(),
 		&model.AuthData{
 			SessionID:    sessionID,
 			RefreshToken: refreshToken,
-		})
+		},
+		clientInfo(r, ""))
 	if err != nil {
 		log.Println("Refresh error:", err)
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			deleteSessionIDCookie(w)
+			deleteRefreshTokenCookie(w)
+		}
 		http.Error(w, "refresh failed", http.StatusUnauthorized)
 		return
 	}
 
+	setRefreshTokenCookie(w, data.RefreshToken)
+
 	resp.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
-		"access_token": accessToken,
+		"access_token": data.AccessToken,
 	})
 }
 
@@ -141,6 +196,110 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Sessions возвращает активные сессии пользователя (HTTP 200).
+// Сессия, из которой сделан запрос, помечена `current: true`.
+func (h *Handler) Sessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+	sessionID, _ := middleware.SessionIDFromContext(r.Context())
+
+	sessions, err := h.serv.Sessions(r.Context(), userID)
+	if err != nil {
+		log.Println("Sessions error:", err)
+		http.Error(w, "failed to get sessions", http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToSessionsResponse(sessions, sessionID))
+}
+
+// RevokeSession завершает одну сессию пользователя и возвращает HTTP 204.
+// Если завершена текущая сессия, удаляет `session_id` и `refresh_token` cookie.
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+	currentID, _ := middleware.SessionIDFromContext(r.Context())
+
+	sessionID := chi.URLParam(r, "sessionID")
+	err := h.serv.RevokeSession(r.Context(), userID, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		log.Println("RevokeSession error:", err)
+		http.Error(w, "failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+
+	if sessionID == currentID {
+		deleteSessionIDCookie(w)
+		deleteRefreshTokenCookie(w)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessions завершает все сессии пользователя, включая текущую ("выйти везде").
+// Удаляет `session_id` и `refresh_token` cookie и возвращает HTTP 204.
+func (h *Handler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.serv.RevokeAllSessions(r.Context(), userID)
+	if err != nil {
+		log.Println("RevokeAllSessions error:", err)
+		http.Error(w, "failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	deleteSessionIDCookie(w)
+	deleteRefreshTokenCookie(w)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Ограничения длины данных клиента, которые сохраняются в сессии
+const (
+	maxDeviceLen    = 64
+	maxUserAgentLen = 512
+)
+
+// writeLoginLocked отвечает на временную блокировку входа (HTTP 429 с заголовком Retry-After)
+func writeLoginLocked(w http.ResponseWriter, locked *service.LoginLockedError) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	http.Error(w, service.ErrLoginLocked.Error(), http.StatusTooManyRequests)
+}
+
+// clientInfo собирает данные клиента для сессии
+func clientInfo(r *http.Request, device string) model.ClientInfo {
+	return model.ClientInfo{
+		Device:    truncate(strings.TrimSpace(device), maxDeviceLen),
+		IP:        req.ClientIP(r),
+		UserAgent: truncate(r.UserAgent(), maxUserAgentLen),
+	}
+}
+
+// truncate обрезает строку до n байт, не разрывая UTF-8 символы
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
 // setRefreshTokenCookie устанавливает cookie с refresh_token
 func setRefreshTokenCookie(w http.ResponseWriter, refreshToken string) {
 	http.SetCookie(w, &http.Cookie{
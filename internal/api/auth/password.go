@@ -0,0 +1,89 @@
+package auth
+
+import (
+	dto "casino_backend/internal/api/dto/auth"
+	"casino_backend/internal/middleware"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/req"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// ChangePassword меняет пароль текущего пользователя (HTTP 204).
+// Остальные сессии пользователя завершаются, текущая остаётся.
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+	sessionID, _ := middleware.SessionIDFromContext(r.Context())
+
+	requestBody, err := req.Decode[dto.ChangePasswordRequest](r.Body)
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.passwordServ.ChangePassword(r.Context(), userID, sessionID, requestBody.OldPassword, requestBody.NewPassword)
+	if err != nil {
+		writePasswordError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestPasswordReset отправляет пользователю токен для сброса пароля (HTTP 202).
+// Ответ не зависит от того, существует ли логин.
+func (h *Handler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := req.Decode[dto.PasswordResetRequest](r.Body)
+	if err != nil || requestBody.Login == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	h.passwordServ.RequestReset(r.Context(), requestBody.Login)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword задаёт новый пароль по токену сброса (HTTP 204).
+// Все сессии пользователя завершаются, cookie текущего клиента удаляются.
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := req.Decode[dto.ResetPasswordRequest](r.Body)
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.passwordServ.ResetPassword(r.Context(), requestBody.Token, requestBody.NewPassword)
+	if err != nil {
+		writePasswordError(w, err)
+		return
+	}
+
+	deleteSessionIDCookie(w)
+	deleteRefreshTokenCookie(w)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePasswordError отвечает на ошибку смены или сброса пароля
+func writePasswordError(w http.ResponseWriter, err error) {
+	var locked *service.LoginLockedError
+	switch {
+	case errors.As(err, &locked):
+		writeLoginLocked(w, locked)
+	case errors.Is(err, service.ErrWrongPassword):
+		http.Error(w, service.ErrWrongPassword.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrInvalidResetToken):
+		http.Error(w, service.ErrInvalidResetToken.Error(), http.StatusBadRequest)
+	default:
+		log.Println("password error:", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
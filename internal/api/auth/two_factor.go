@@ -0,0 +1,144 @@
+package auth
+
+import (
+	dto "casino_backend/internal/api/dto/auth"
+	"casino_backend/internal/middleware"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/req"
+	"casino_backend/pkg/resp"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// LoginTwoFactor второй шаг входа: принимает `pre_auth_token` из ответа Login и код
+// из приложения (или код восстановления). Дальше — как Login: access_token в теле, cookie сессии.
+func (h *Handler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := req.Decode[dto.TwoFactorLoginRequest](r.Body)
+	if err != nil {
+		log.Printf("login 2fa: decode request error: %v", err)
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	data, err := h.serv.LoginTwoFactor(
+		r.Context(),
+		requestBody.PreAuthToken,
+		requestBody.Code,
+		clientInfo(r, requestBody.Device),
+	)
+	if err != nil {
+		log.Println("LoginTwoFactor error:", err)
+		var locked *service.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			writeLoginLocked(w, locked)
+		case errors.Is(err, service.ErrInvalidPreAuthToken):
+			http.Error(w, service.ErrInvalidPreAuthToken.Error(), http.StatusUnauthorized)
+		case errors.Is(err, service.ErrInvalidTwoFactorCode):
+			http.Error(w, service.ErrInvalidTwoFactorCode.Error(), http.StatusUnauthorized)
+		default:
+			http.Error(w, "login failed", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setSessionIDCookie(w, data.SessionID)
+
+	setRefreshTokenCookie(w, data.RefreshToken)
+
+	resp.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"access_token": data.AccessToken,
+	})
+}
+
+// EnrollTwoFactor выдаёт секрет и otpauth:// ссылку для приложения-аутентификатора (HTTP 200).
+// Двухфакторная аутентификация включается только после ConfirmTwoFactor.
+func (h *Handler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	enrollment, err := h.twoFactorServ.Enroll(r.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, dto.TwoFactorEnrollResponse{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+	})
+}
+
+// ConfirmTwoFactor включает двухфакторную аутентификацию по коду из приложения и возвращает
+// коды восстановления (HTTP 200). Текущая сессия считается прошедшей второй фактор —
+// access токен с этим признаком выдаётся при следующем /auth/refresh. Остальные сессии завершаются.
+func (h *Handler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+	sessionID, _ := middleware.SessionIDFromContext(r.Context())
+
+	requestBody, err := req.Decode[dto.TwoFactorCodeRequest](r.Body)
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactorServ.Confirm(r.Context(), userID, sessionID, requestBody.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, dto.TwoFactorConfirmResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor отключает двухфакторную аутентификацию по коду из приложения
+// или коду восстановления (HTTP 204). Доступно только из сессии, прошедшей второй фактор;
+// неверные коды ведут к блокировке, как при входе (HTTP 429). Администраторам недоступно.
+func (h *Handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	requestBody, err := req.Decode[dto.TwoFactorCodeRequest](r.Body)
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.twoFactorServ.Disable(r.Context(), userID, requestBody.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTwoFactorError отвечает на ошибку управления двухфакторной аутентификацией
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	var locked *service.LoginLockedError
+	switch {
+	case errors.As(err, &locked):
+		writeLoginLocked(w, locked)
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		log.Println("two-factor error:", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RTPStateResponse struct {
+	Game     string   `json:"game"`
+	Segment  string   `json:"segment"`
+	Segments []string `json:"segments"` // Все сегменты игры
+	Strategy string   `json:"strategy"` // window, pid или ewma
+
+	ConfigIndex int    `json:"config_index"` // 0 — самый высокий RTP
+	ConfigName  string `json:"config_name"`
+	ConfigCount int    `json:"config_count"`
+
+	TargetRTP   float64 `json:"target_rtp"`   // Целевой RTP, %
+	ObservedRTP float64 `json:"observed_rtp"` // Оценка RTP, по которой решает стратегия, %
+
+	TotalSpins  int64   `json:"total_spins"`
+	TotalBet    int64   `json:"total_bet"`
+	TotalPayout int64   `json:"total_payout"`
+	TotalRTP    float64 `json:"total_rtp"` // RTP за всё время, %
+
+	WindowSize  int `json:"window_size"`  // Размер окна
+	WindowSpins int `json:"window_spins"` // Сколько спинов сейчас в окне
+
+	EmergencyMode      bool   `json:"emergency_mode"`
+	EmergencyDirection string `json:"emergency_direction,omitempty"` // high или low
+
+	Paused      bool                 `json:"paused"` // Автокорректировка выключена
+	Adjustments []AdjustmentResponse `json:"adjustments"`
+}
+
+type AdjustmentResponse struct {
+	Timestamp   time.Time `json:"timestamp"`
+	OldIndex    int       `json:"old_index"`
+	NewIndex    int       `json:"new_index"`
+	NewConfig   string    `json:"new_config"`
+	Reason      string    `json:"reason"`
+	ObservedRTP float64   `json:"observed_rtp"`
+	Profit      int64     `json:"profit"` // Прибыль казино на момент корректировки
+}
+
+type SetIndexRequest struct {
+	Index *int `json:"index"` // Конфиг, который закрепить (автокорректировка ставится на паузу)
+}
+
+type SetTargetRTPRequest struct {
+	TargetRTP *float64 `json:"target_rtp"` // %
+}
+
+type SetWindowSizeRequest struct {
+	WindowSize *int `json:"window_size"`
+}
+
+type SetPausedRequest struct {
+	Paused *bool `json:"paused"`
+}
+
+type AuditEntryResponse struct {
+	ID        int64           `json:"id"`
+	Game      string          `json:"game"`
+	Segment   string          `json:"segment"`
+	Action    string          `json:"action"`
+	OldValue  json.RawMessage `json:"old_value"`
+	NewValue  json.RawMessage `json:"new_value"`
+	Actor     string          `json:"actor"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type AuditLogResponse struct {
+	Items      []AuditEntryResponse `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"` // Передать в ?cursor= для следующей страницы
+}
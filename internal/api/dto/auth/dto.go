@@ -1,12 +1,65 @@
 package auth
 
+import "time"
+
 type RegisterRequest struct {
 	Name     string `json:"name"`
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	Device   string `json:"device,omitempty"` // Название устройства для списка сессий
 }
 
 type LoginRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	Device   string `json:"device,omitempty"` // Название устройства для списка сессий
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Сессия, из которой сделан запрос
+}
+
+type TwoFactorRequiredResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	PreAuthToken      string `json:"pre_auth_token"` // Действует 5 минут
+}
+
+type TwoFactorLoginRequest struct {
+	PreAuthToken string `json:"pre_auth_token"`
+	Code         string `json:"code"` // Код из приложения или код восстановления
+	Device       string `json:"device,omitempty"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"` // Для ручного ввода в приложение
+	URI    string `json:"uri"`    // otpauth:// для QR-кода
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Показываются один раз
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type PasswordResetRequest struct {
+	Login string `json:"login"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"` // Токен из уведомления о сбросе
+	NewPassword string `json:"new_password"`
 }
@@ -1,19 +1,23 @@
 package cascade
 
+import "casino_backend/internal/api/dto/fairness"
+
 type CascadeSpinRequest struct {
 	Bet int `json:"bet"` // Размер ставки (положительное чётное число)
 }
 
 type CascadeSpinResponse struct {
-	InitialBoard     [7][7]int     `json:"initial_board"`      // Доска сразу после начального заполнения, до любых каскадов
-	Board            [7][7]int     `json:"board"`              // Итоговая доска: -1 = пусто, 0-6 = обычные, 7 = скаттер
-	Cascades         []CascadeStep `json:"cascades"`           // Все шаги каскада (для анимации)
-	TotalPayout      int           `json:"total_payout"`       // Общая выплата за спин
-	Balance          int           `json:"balance"`            // Баланс после спина
-	ScatterCount     int           `json:"scatter_count"`      // Количество скаттеров на финальной доске
-	AwardedFreeSpins int           `json:"awarded_free_spins"` // Начислено фриспинов в этом спине
-	FreeSpinsLeft    int           `json:"free_spins_left"`    // Остаток фриспинов после спина
-	InFreeSpin       bool          `json:"in_free_spin"`       // Это был фриспин?
+	RoundID          string                  `json:"round_id"`           // ID раунда (для истории)
+	InitialBoard     [7][7]int               `json:"initial_board"`      // Доска сразу после начального заполнения, до любых каскадов
+	Board            [7][7]int               `json:"board"`              // Итоговая доска: -1 = пусто, 0-6 = обычные, 7 = скаттер
+	Cascades         []CascadeStep           `json:"cascades"`           // Все шаги каскада (для анимации)
+	TotalPayout      int                     `json:"total_payout"`       // Общая выплата за спин
+	Balance          int                     `json:"balance"`            // Баланс после спина
+	ScatterCount     int                     `json:"scatter_count"`      // Количество скаттеров на финальной доске
+	AwardedFreeSpins int                     `json:"awarded_free_spins"` // Начислено фриспинов в этом спине
+	FreeSpinsLeft    int                     `json:"free_spins_left"`    // Остаток фриспинов после спина
+	InFreeSpin       bool                    `json:"in_free_spin"`       // Это был фриспин?
+	Fairness         *fairness.ProofResponse `json:"fairness,omitempty"` // Данные для проверки спина (provably-fair режим)
 }
 
 type CascadeStep struct {
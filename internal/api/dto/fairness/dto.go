@@ -0,0 +1,49 @@
+package fairness
+
+import "time"
+
+type SeedResponse struct {
+	ServerSeedHash string    `json:"server_seed_hash"` // SHA-256 серверного сида (hex), публикуется заранее
+	ClientSeed     string    `json:"client_seed"`
+	Nonce          int       `json:"nonce"` // Nonce последнего сыгранного спина (следующий спин — nonce + 1)
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ClientSeedRequest struct {
+	ClientSeed string `json:"client_seed"` // 1-64 символа
+}
+
+type RevealedSeedResponse struct {
+	ServerSeed     string    `json:"server_seed"` // Раскрытый серверный сид
+	ServerSeedHash string    `json:"server_seed_hash"`
+	ClientSeed     string    `json:"client_seed"`
+	Nonce          int       `json:"nonce"` // Сколько спинов сыграно на этой паре сидов
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type RotateResponse struct {
+	Revealed RevealedSeedResponse `json:"revealed"` // Старая пара сидов
+	Next     SeedResponse         `json:"next"`     // Новая активная пара
+}
+
+type VerifyRequest struct {
+	Game        string `json:"game"` // line или cascade
+	ServerSeed  string `json:"server_seed"`
+	ClientSeed  string `json:"client_seed"`
+	Nonce       int    `json:"nonce"`
+	ConfigIndex int    `json:"config_index"` // config_index раунда из /history
+	BonusBuy    bool   `json:"bonus_buy"`    // Раунд — спин купленной бонуски (только line)
+}
+
+type VerifyResponse struct {
+	Game           string        `json:"game"`
+	ServerSeedHash string        `json:"server_seed_hash"`        // Сверить с хэшем, показанным до спина
+	Board          *[5][3]string `json:"board,omitempty"`         // Доска line
+	InitialBoard   *[7][7]int    `json:"initial_board,omitempty"` // Начальная доска cascade
+}
+
+type ProofResponse struct {
+	ServerSeedHash string `json:"server_seed_hash"`
+	ClientSeed     string `json:"client_seed"`
+	Nonce          int    `json:"nonce"`
+}
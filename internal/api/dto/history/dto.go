@@ -0,0 +1,26 @@
+package history
+
+import "time"
+
+type RoundResponse struct {
+	RoundID     string    `json:"round_id"`
+	Game        string    `json:"game"`         // line или cascade
+	Bet         int       `json:"bet"`          // Ставка (для покупки бонуски — её цена)
+	Payout      int       `json:"payout"`       // Выигрыш за раунд
+	InFreeSpin  bool      `json:"in_free_spin"` // Раунд сыгран фриспином
+	BonusBuy    bool      `json:"bonus_buy"`    // Раунд — спин купленной бонуски
+	ConfigIndex int       `json:"config_index"` // Индекс пресета / конфига RTP
+	ConfigName  string    `json:"config_name"`  // Название пресета / конфига RTP
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type HistoryResponse struct {
+	Items      []RoundResponse `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"` // Передать в ?cursor= для следующей страницы
+}
+
+type RoundDetailResponse struct {
+	RoundResponse
+	// Результат спина в том же формате, что и ответ /line/spin, /line/buy-bonus или /cascade/spin
+	Result any `json:"result"`
+}
@@ -1,28 +1,34 @@
 package line
 
+import "casino_backend/internal/api/dto/fairness"
+
 type LineSpinRequest struct {
 	Bet int `json:"bet"` // Размер ставки (положительное целое, >0)
 }
 
 type LineSpinResponse struct {
-	Board            [5][3]string `json:"board"`              // Символы (ID)
-	LineWins         []LineWin    `json:"line_wins"`          // Выигрышные линии
-	ScatterCount     int          `json:"scatter_count"`      // Кол-во скаттеров
-	ScatterPayout    int          `json:"scatter_payout"`     // Выплата по скаттерам
-	AwardedFreeSpins int          `json:"awarded_free_spins"` // Начислено фриспинов в этом спине
-	TotalPayout      int          `json:"total_payout"`       // Общая выплата
-	Balance          int          `json:"balance"`            // Баланс после
-	FreeSpinCount    int          `json:"free_spin_count"`    // Остаток фриспинов
+	RoundID          string                  `json:"round_id"`           // ID раунда (для истории)
+	Board            [5][3]string            `json:"board"`              // Символы (ID)
+	LineWins         []LineWin               `json:"line_wins"`          // Выигрышные линии
+	ScatterCount     int                     `json:"scatter_count"`      // Кол-во скаттеров
+	ScatterPayout    int                     `json:"scatter_payout"`     // Выплата по скаттерам
+	AwardedFreeSpins int                     `json:"awarded_free_spins"` // Начислено фриспинов в этом спине
+	TotalPayout      int                     `json:"total_payout"`       // Общая выплата
+	Balance          int                     `json:"balance"`            // Баланс после
+	FreeSpinCount    int                     `json:"free_spin_count"`    // Остаток фриспинов
+	Fairness         *fairness.ProofResponse `json:"fairness,omitempty"` // Данные для проверки спина (provably-fair режим)
 }
 type BonusSpinResponse struct {
-	Board            [5][3]string `json:"board"`              // Символы (ID)
-	LineWins         []LineWin    `json:"line_wins"`          // Выигрышные линии
-	ScatterCount     int          `json:"scatter_count"`      // Кол-во скаттеров
-	ScatterPayout    int          `json:"scatter_payout"`     // Выплата по скаттерам
-	AwardedFreeSpins int          `json:"awarded_free_spins"` // Начислено фриспинов в этом спине
-	TotalPayout      int          `json:"total_payout"`       // Общая выплата
-	Balance          int          `json:"balance"`            // Баланс после
-	FreeSpinCount    int          `json:"free_spin_count"`    // Остаток фриспинов
+	RoundID          string                  `json:"round_id"`           // ID раунда (для истории)
+	Board            [5][3]string            `json:"board"`              // Символы (ID)
+	LineWins         []LineWin               `json:"line_wins"`          // Выигрышные линии
+	ScatterCount     int                     `json:"scatter_count"`      // Кол-во скаттеров
+	ScatterPayout    int                     `json:"scatter_payout"`     // Выплата по скаттерам
+	AwardedFreeSpins int                     `json:"awarded_free_spins"` // Начислено фриспинов в этом спине
+	TotalPayout      int                     `json:"total_payout"`       // Общая выплата
+	Balance          int                     `json:"balance"`            // Баланс после
+	FreeSpinCount    int                     `json:"free_spin_count"`    // Остаток фриспинов
+	Fairness         *fairness.ProofResponse `json:"fairness,omitempty"` // Данные для проверки спина (provably-fair режим)
 }
 type BonusSpinRequest struct {
 	Bet int `json:"bet"` // Сумма покупки бонуса
@@ -1,5 +1,25 @@
 package pay
 
+import "time"
+
 type DepositRequest struct {
 	Amount int `json:"amount"`
 }
+
+type WithdrawRequest struct {
+	Amount int `json:"amount"` // В копейках
+}
+
+type TransactionResponse struct {
+	ID           int64     `json:"id"`
+	Type         string    `json:"type"`          // bet, win, deposit, bonus_buy, adjustment, withdraw
+	Amount       int       `json:"amount"`        // Изменение баланса: отрицательное для списаний
+	BalanceAfter int       `json:"balance_after"` // Баланс после операции
+	RoundID      string    `json:"round_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TransactionsResponse struct {
+	Items      []TransactionResponse `json:"items"`
+	NextCursor string                `json:"next_cursor,omitempty"` // Передать в ?cursor= для следующей страницы
+}
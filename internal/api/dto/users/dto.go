@@ -0,0 +1,13 @@
+package users
+
+type UserResponse struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Login   string `json:"login"`
+	Balance uint   `json:"balance"` // В копейках
+	Role    string `json:"role"`    // player, support или admin
+}
+
+type SetRoleRequest struct {
+	Role *string `json:"role"`
+}
@@ -0,0 +1,137 @@
+package fairness
+
+import (
+	dto "casino_backend/internal/api/dto/fairness"
+	"casino_backend/internal/converter"
+	"casino_backend/internal/middleware"
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/req"
+	"casino_backend/pkg/resp"
+	"casino_backend/pkg/rng"
+	"errors"
+	"net/http"
+)
+
+type HandlerDeps struct {
+	Serv        service.FairnessService
+	LineServ    service.LineService
+	CascadeServ service.CascadeService
+}
+
+type Handler struct {
+	serv        service.FairnessService
+	lineServ    service.LineService
+	cascadeServ service.CascadeService
+}
+
+func NewHandler(deps HandlerDeps) *Handler {
+	return &Handler{
+		serv:        deps.Serv,
+		lineServ:    deps.LineServ,
+		cascadeServ: deps.CascadeServ,
+	}
+}
+
+// Seed возвращает хэш текущего серверного сида, клиентский сид и nonce игрока
+func (h *Handler) Seed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	seed, err := h.serv.Seed(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToSeedResponse(*seed))
+}
+
+// SetClientSeed меняет клиентский сид игрока
+func (h *Handler) SetClientSeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := req.Decode[dto.ClientSeedRequest](r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	seed, err := h.serv.SetClientSeed(r.Context(), userID, payload.ClientSeed)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidClientSeed) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToSeedResponse(*seed))
+}
+
+// Rotate раскрывает текущий серверный сид и выдаёт новый
+func (h *Handler) Rotate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	rotation, err := h.serv.Rotate(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToRotateResponse(*rotation))
+}
+
+// Verify воспроизводит доску спина по раскрытому серверному сиду, клиентскому сиду и nonce.
+// Не требует авторизации: проверить спин может кто угодно
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	payload, err := req.Decode[dto.VerifyRequest](r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if payload.ServerSeed == "" || payload.Nonce <= 0 {
+		http.Error(w, "server_seed and positive nonce are required", http.StatusBadRequest)
+		return
+	}
+
+	verify := converter.ToFairVerify(payload)
+	response := dto.VerifyResponse{
+		Game:           payload.Game,
+		ServerSeedHash: rng.HashSeed(payload.ServerSeed),
+	}
+
+	switch verify.Game {
+	case model.GameLine:
+		board, err := h.lineServ.VerifyBoard(verify)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response.Board = &board
+	case model.GameCascade:
+		board, err := h.cascadeServ.VerifyBoard(verify)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response.InitialBoard = &board
+	default:
+		http.Error(w, "game must be line or cascade", http.StatusBadRequest)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, response)
+}
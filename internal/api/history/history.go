@@ -0,0 +1,93 @@
+package history
+
+import (
+	"casino_backend/internal/converter"
+	"casino_backend/internal/middleware"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/resp"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type HandlerDeps struct {
+	Serv service.HistoryService
+}
+
+type Handler struct {
+	serv service.HistoryService
+}
+
+func NewHandler(deps HandlerDeps) *Handler {
+	return &Handler{serv: deps.Serv}
+}
+
+// List возвращает историю раундов пользователя (от новых к старым).
+// Параметры запроса: `cursor` — значение next_cursor из предыдущего ответа, `limit` — размер страницы.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	var (
+		cursor int64
+		limit  int
+		err    error
+	)
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		cursor, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || cursor < 0 {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.serv.Rounds(r.Context(), userID, cursor, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToHistoryResponse(*page))
+}
+
+// Get возвращает раунд с полным результатом спина для повтора анимации.
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	round, err := h.serv.Round(r.Context(), userID, chi.URLParam(r, "roundID"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "round not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := converter.ToRoundDetailResponse(*round)
+	if err != nil {
+		log.Printf("history: decode round %s error: %v", round.ID, err)
+		http.Error(w, "failed to decode round", http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, response)
+}
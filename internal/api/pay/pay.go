@@ -2,11 +2,15 @@ package pay
 
 import (
 	dto "casino_backend/internal/api/dto/pay"
+	"casino_backend/internal/converter"
 	"casino_backend/internal/middleware"
+	"casino_backend/internal/repository"
 	"casino_backend/internal/service"
 	"casino_backend/pkg/req"
 	"casino_backend/pkg/resp"
+	"errors"
 	"net/http"
+	"strconv"
 )
 
 type HandlerDeps struct {
@@ -38,6 +42,10 @@ func (h *Handler) Deposit(w http.ResponseWriter, r *http.Request) {
 
 	err = h.serv.Deposit(r.Context(), userID, requestBody.Amount)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidAmount) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -45,6 +53,34 @@ func (h *Handler) Deposit(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// Withdraw выводит средства с баланса. Доступен только сессиям, прошедшим двухфакторную аутентификацию
+func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	requestBody, err := req.Decode[dto.WithdrawRequest](r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.serv.Withdraw(r.Context(), userID, requestBody.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAmount), errors.Is(err, repository.ErrNotEnoughBalance):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.UserIDFromContext(r.Context())
 	if !ok {
@@ -60,3 +96,41 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 
 	resp.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"balance": balance})
 }
+
+// Transactions возвращает журнал операций по кошельку пользователя (от новых к старым).
+// Параметры запроса: `cursor` — значение next_cursor из предыдущего ответа, `limit` — размер страницы.
+func (h *Handler) Transactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	var (
+		cursor int64
+		limit  int
+		err    error
+	)
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		cursor, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || cursor < 0 {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.serv.Transactions(r.Context(), userID, cursor, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToTransactionsResponse(*page))
+}
@@ -0,0 +1,113 @@
+package users
+
+import (
+	dto "casino_backend/internal/api/dto/users"
+	"casino_backend/internal/converter"
+	"casino_backend/internal/middleware"
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/req"
+	"casino_backend/pkg/resp"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type HandlerDeps struct {
+	Serv service.UserAdminService
+}
+
+type Handler struct {
+	serv service.UserAdminService
+}
+
+func NewHandler(deps HandlerDeps) *Handler {
+	return &Handler{serv: deps.Serv}
+}
+
+// Get возвращает аккаунт пользователя {userID}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.serv.GetUser(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteJSONResponse(w, http.StatusOK, converter.ToUserResponse(*user))
+}
+
+// SetRole меняет роль пользователя {userID}; все его сессии завершаются
+func (h *Handler) SetRole(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	payload, err := req.Decode[dto.SetRoleRequest](r.Body)
+	if err != nil || payload.Role == nil {
+		http.Error(w, "role is required", http.StatusBadRequest)
+		return
+	}
+
+	err = h.serv.SetRole(r.Context(), actorID, userID, model.Role(*payload.Role))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRoleChange):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, repository.ErrNotFound):
+			http.Error(w, "user not found", http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.Get(w, r)
+}
+
+// UnlockLogin снимает блокировку входа пользователя {userID} после неудачных попыток (HTTP 204)
+func (h *Handler) UnlockLogin(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.serv.UnlockLogin(r.Context(), actorID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
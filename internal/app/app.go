@@ -3,10 +3,19 @@ package app
 import (
 	"casino_backend/internal/config"
 	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout Сколько ждём завершения активных запросов при остановке
+const shutdownTimeout = 15 * time.Second
+
 type App struct {
 	ServiceProvider *ServiceProvider
 }
@@ -27,12 +36,67 @@ func (s *App) Run() error {
 	}
 	s.initServiceProvider()
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Загружаем состояние регуляторов RTP, накопленное до перезапуска (и другими репликами)
+	rtpState := s.ServiceProvider.RTPStateService(ctx)
+	if err := rtpState.Sync(ctx); err != nil {
+		return err
+	}
+
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	syncDone := make(chan struct{})
+	go func() {
+		defer close(syncDone)
+		rtpState.Run(syncCtx)
+	}()
+
+	// Фоновое удаление истекших сессий, старых ключей идемпотентности, счётчиков входа и корзин лимитов запросов
+	cleanups := []interface{ Run(ctx context.Context) }{
+		s.ServiceProvider.SessionCleanupService(ctx),
+		s.ServiceProvider.IdempotencyCleanupService(ctx),
+		s.ServiceProvider.LoginAttemptCleanupService(ctx),
+	}
+	// Корзины в памяти процесса удаляются при обращении к ним, в Postgres — только фоновой очисткой
+	if s.ServiceProvider.RateLimitCfg().SharedStore() {
+		cleanups = append(cleanups, s.ServiceProvider.RateLimitCleanupService(ctx))
+	}
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	var cleanupWG sync.WaitGroup
+	for _, cleanup := range cleanups {
+		cleanupWG.Go(func() { cleanup.Run(cleanupCtx) })
+	}
+
 	r := s.ServiceProvider.Router(ctx)
+	srv := &http.Server{
+		Addr:    s.ServiceProvider.HTTPCfg().Address(),
+		Handler: r,
+	}
 
-	log.Printf("starting server at %s", s.ServiceProvider.HTTPCfg().Address())
-	err = http.ListenAndServe(s.ServiceProvider.HTTPCfg().Address(), r)
-	if err != nil {
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("starting server at %s", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		log.Printf("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err = srv.Shutdown(shutdownCtx)
+	}
+
+	// Спины больше не идут: финальная синхронизация сохраняет всё, что накопилось с последнего тика
+	stopSync()
+	<-syncDone
+	stopCleanup()
+	cleanupWG.Wait()
+	s.ServiceProvider.DBClient(ctx).Close()
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 	return nil
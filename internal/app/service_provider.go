@@ -1,25 +1,55 @@
 package app
 
 import (
+	adminAPI "casino_backend/internal/api/admin"
 	authAPI "casino_backend/internal/api/auth"
 	cascadeAPI "casino_backend/internal/api/cascade"
+	fairnessAPI "casino_backend/internal/api/fairness"
+	historyAPI "casino_backend/internal/api/history"
 	lineAPI "casino_backend/internal/api/line"
 	payAPI "casino_backend/internal/api/pay"
+	usersAPI "casino_backend/internal/api/users"
 	"casino_backend/internal/config"
 	"casino_backend/internal/config/env"
 	"casino_backend/internal/middleware"
+	"casino_backend/internal/model"
+	"casino_backend/internal/notifier"
 	"casino_backend/internal/repository"
 	"casino_backend/internal/repository/auth_repo"
 	"casino_backend/internal/repository/cascade_repo"
-	"casino_backend/internal/repository/cascade_stats_repo"
+	"casino_backend/internal/repository/fair_seed_repo"
+	"casino_backend/internal/repository/idempotency_repo"
 	"casino_backend/internal/repository/line_repo"
-	"casino_backend/internal/repository/line_state_repo"
+	"casino_backend/internal/repository/login_attempt_repo"
+	"casino_backend/internal/repository/password_reset_repo"
+	"casino_backend/internal/repository/rate_limit_repo"
+	"casino_backend/internal/repository/round_repo"
+	"casino_backend/internal/repository/rtp_audit_repo"
+	"casino_backend/internal/repository/rtp_controller_repo"
+	"casino_backend/internal/repository/rtp_state_repo"
+	"casino_backend/internal/repository/totp_repo"
+	"casino_backend/internal/repository/transaction_repo"
 	"casino_backend/internal/repository/user_repo"
 	"casino_backend/internal/service"
 	"casino_backend/internal/service/auth"
 	"casino_backend/internal/service/cascade"
+	"casino_backend/internal/service/fairness"
+	"casino_backend/internal/service/history"
+	"casino_backend/internal/service/idempotencycleanup"
 	"casino_backend/internal/service/line"
+	"casino_backend/internal/service/loginattemptcleanup"
+	"casino_backend/internal/service/loginprotection"
+	"casino_backend/internal/service/password"
 	payService "casino_backend/internal/service/pay"
+	"casino_backend/internal/service/ratelimitcleanup"
+	"casino_backend/internal/service/rtpadmin"
+	"casino_backend/internal/service/rtpstate"
+	"casino_backend/internal/service/sessioncleanup"
+	"casino_backend/internal/service/twofactor"
+	"casino_backend/internal/service/useradmin"
+	"casino_backend/internal/service/wallet"
+	"casino_backend/pkg/pass"
+	"casino_backend/pkg/rng"
 	"context"
 
 	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
@@ -30,6 +60,16 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	lineConfigPath    = "config-line.yaml"
+	cascadeConfigPath = "config-cascade.yaml"
+	rtpConfigPath     = "config-rtp.yaml"
+
+	// Количество конфигов RTP, между которыми переключаются регуляторы
+	lineConfigCount    = 20
+	cascadeConfigCount = 20
+)
+
 type ServiceProvider struct {
 	//TXManager
 	txManager trm.Manager
@@ -45,26 +85,99 @@ type ServiceProvider struct {
 	authHand  *authAPI.Handler
 	authMw    *middleware.AuthMiddleware
 
+	// Two-factor bits
+	totpRepo      repository.TOTPRepository
+	twoFactorServ service.TwoFactorService
+
+	// Password bits
+	passwordCfg       config.PasswordConfig
+	passwordResetRepo repository.PasswordResetRepository
+	passwordServ      service.PasswordService
+	passwordHasher    *pass.Hasher
+	passwordPolicy    *pass.Policy
+
+	// Notification bits
+	notifierCfg config.NotifierConfig
+	notifier    service.Notifier
+
+	// Login protection bits
+	loginProtectionCfg  config.LoginProtectionConfig
+	loginAttemptRepo    repository.LoginAttemptRepository
+	loginProtectionServ service.LoginProtectionService
+
+	// Session cleanup bits
+	sessionCfg         config.SessionConfig
+	sessionCleanupServ service.SessionCleanupService
+
 	// User bits
 	userRepo repository.UserRepository
 
+	// Rate limit bits
+	rateLimitCfg  config.RateLimitConfig
+	rateLimitRepo repository.RateLimitRepository
+	rateLimitMw   *middleware.RateLimitMiddleware
+
+	rateLimitCleanupServ service.RateLimitCleanupService
+
+	loginAttemptCleanupServ service.LoginAttemptCleanupService
+
+	// Idempotency bits
+	idempotencyCfg         config.IdempotencyConfig
+	idempotencyRepo        repository.IdempotencyRepository
+	idempotencyMw          *middleware.IdempotencyMiddleware
+	idempotencyCleanupServ service.IdempotencyCleanupService
+
+	// Wallet bits
+	transactionRepo repository.TransactionRepository
+	walletServ      service.WalletService
+
 	// Payment bits
 	payServ service.PaymentService
 	payHand *payAPI.Handler
 
+	// History bits
+	roundRepo   repository.RoundRepository
+	historyServ service.HistoryService
+	historyHand *historyAPI.Handler
+
 	// Line bits
-	lineCfg       config.LineConfig
-	lineRepo      repository.LineRepository
-	lineStatsRepo repository.LineStatsRepository
-	lineServ      service.LineService // LineService ждет в конструкторе репозиторий пользователей, но его пока нет
-	lineHand      *lineAPI.Handler
+	lineCfg         config.LineConfig
+	lineRepo        repository.LineRepository
+	lineRTPSegments repository.RTPSegmentsRepository
+	lineServ        service.LineService // LineService ждет в конструкторе репозиторий пользователей, но его пока нет
+	lineHand        *lineAPI.Handler
 
 	// Cascade bits
-	cascadeCfg       config.CascadeConfig
-	cascadeRepo      repository.CascadeRepository
-	cascadeStatsRepo repository.CascadeStatsRepository
-	cascadeServ      service.CascadeService
-	cascadeHand      *cascadeAPI.Handler
+	cascadeCfg         config.CascadeConfig
+	cascadeRepo        repository.CascadeRepository
+	cascadeRTPSegments repository.RTPSegmentsRepository
+	cascadeServ        service.CascadeService
+	cascadeHand        *cascadeAPI.Handler
+
+	// RTP controllers state bits
+	rtpControllerCfg config.RTPControllerConfig
+	rtpStateCfg      config.RTPStateConfig
+	rtpStateRepo     repository.RTPStateRepository
+	rtpStateServ     service.RTPStateService
+
+	// Admin bits
+	rtpAuditRepo repository.RTPAuditRepository
+	rtpAdminServ service.RTPAdminService
+	adminHand    *adminAPI.Handler
+
+	// Users administration bits
+	userAdminServ service.UserAdminService
+	usersHand     *usersAPI.Handler
+
+	// RNG
+	rngCfg      config.RNGConfig
+	rnd         rng.RNG
+	rngProvider service.RNGProvider
+
+	// Provably-fair bits
+	fairSeedRepo repository.FairSeedRepository
+	fairServ     service.FairnessService
+	fairHand     *fairnessAPI.Handler
 
 	// Router and HTTP config
 	httpCfg config.HTTPConfig
@@ -103,14 +216,14 @@ func (sp *ServiceProvider) DBClient(ctx context.Context) *pgxpool.Pool {
 
 func (sp *ServiceProvider) AuthRepo(ctx context.Context) repository.AuthRepository {
 	if sp.authRepo == nil {
-		sp.authRepo = auth_repo.NewAuthRepository(sp.DBClient(ctx))
+		sp.authRepo = auth_repo.NewAuthRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
 	}
 	return sp.authRepo
 }
 
 func (sp *ServiceProvider) UserRepo(ctx context.Context) repository.UserRepository {
 	if sp.userRepo == nil {
-		sp.userRepo = user_repo.NewUserRepository(sp.DBClient(ctx))
+		sp.userRepo = user_repo.NewUserRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
 	}
 	return sp.userRepo
 }
@@ -128,20 +241,184 @@ func (sp *ServiceProvider) JWTConfig() config.JWTConfig {
 
 func (sp *ServiceProvider) AuthService(ctx context.Context) service.AuthService {
 	if sp.authServ == nil {
-		sp.authServ = auth.NewService(
+		authServ, err := auth.NewService(
 			sp.TXManager(ctx),
 			sp.JWTConfig(),
 			sp.UserRepo(ctx),
 			sp.AuthRepo(ctx),
+			sp.LoginProtectionService(ctx),
+			sp.TwoFactorService(ctx),
+			sp.PasswordHasher(),
+			sp.PasswordPolicy(),
 		)
+		if err != nil {
+			panic("failed to create auth service: " + err.Error())
+		}
+		sp.authServ = authServ
 	}
 	return sp.authServ
 }
 
+func (sp *ServiceProvider) TOTPRepo(ctx context.Context) repository.TOTPRepository {
+	if sp.totpRepo == nil {
+		sp.totpRepo = totp_repo.NewTOTPRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.totpRepo
+}
+
+func (sp *ServiceProvider) TwoFactorService(ctx context.Context) service.TwoFactorService {
+	if sp.twoFactorServ == nil {
+		sp.twoFactorServ = twofactor.NewService(
+			sp.TXManager(ctx),
+			sp.TOTPRepo(ctx),
+			sp.UserRepo(ctx),
+			sp.AuthRepo(ctx),
+			sp.LoginProtectionService(ctx),
+		)
+	}
+	return sp.twoFactorServ
+}
+
+func (sp *ServiceProvider) PasswordCfg() config.PasswordConfig {
+	if sp.passwordCfg == nil {
+		cfg, err := env.NewPasswordConfig()
+		if err != nil {
+			panic("failed to get password config: " + err.Error())
+		}
+		sp.passwordCfg = cfg
+	}
+	return sp.passwordCfg
+}
+
+// PasswordHasher хэширует пароли алгоритмом из конфигурации
+func (sp *ServiceProvider) PasswordHasher() *pass.Hasher {
+	if sp.passwordHasher == nil {
+		hasher, err := pass.NewHasher(sp.PasswordCfg().HashAlgorithm(), sp.PasswordCfg().BcryptCost())
+		if err != nil {
+			panic("failed to create password hasher: " + err.Error())
+		}
+		sp.passwordHasher = hasher
+	}
+	return sp.passwordHasher
+}
+
+// PasswordPolicy требования к новым паролям; список скомпрометированных паролей читается один раз при старте
+func (sp *ServiceProvider) PasswordPolicy() *pass.Policy {
+	if sp.passwordPolicy == nil {
+		policy, err := pass.NewPolicy(sp.PasswordCfg().MinLength(), sp.PasswordCfg().BreachedListFile())
+		if err != nil {
+			panic("failed to load password policy: " + err.Error())
+		}
+		sp.passwordPolicy = policy
+	}
+	return sp.passwordPolicy
+}
+
+func (sp *ServiceProvider) PasswordResetRepo(ctx context.Context) repository.PasswordResetRepository {
+	if sp.passwordResetRepo == nil {
+		sp.passwordResetRepo = password_reset_repo.NewPasswordResetRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.passwordResetRepo
+}
+
+func (sp *ServiceProvider) PasswordService(ctx context.Context) service.PasswordService {
+	if sp.passwordServ == nil {
+		sp.passwordServ = password.NewService(
+			sp.TXManager(ctx),
+			sp.PasswordCfg(),
+			sp.UserRepo(ctx),
+			sp.AuthRepo(ctx),
+			sp.PasswordResetRepo(ctx),
+			sp.LoginProtectionService(ctx),
+			sp.Notifier(),
+			sp.PasswordHasher(),
+			sp.PasswordPolicy(),
+		)
+	}
+	return sp.passwordServ
+}
+
+func (sp *ServiceProvider) NotifierCfg() config.NotifierConfig {
+	if sp.notifierCfg == nil {
+		cfg, err := env.NewNotifierConfig()
+		if err != nil {
+			panic("failed to get notifier config: " + err.Error())
+		}
+		sp.notifierCfg = cfg
+	}
+	return sp.notifierCfg
+}
+
+// Notifier доставляет уведомления пользователям. Пока настоящей рассылки нет,
+// сообщения пишутся в файл, а если он не задан — в лог
+func (sp *ServiceProvider) Notifier() service.Notifier {
+	if sp.notifier == nil {
+		if path := sp.NotifierCfg().NotificationFile(); path != "" {
+			sp.notifier = notifier.NewFileNotifier(path)
+		} else {
+			sp.notifier = notifier.NewLogNotifier()
+		}
+	}
+	return sp.notifier
+}
+
+func (sp *ServiceProvider) LoginProtectionCfg() config.LoginProtectionConfig {
+	if sp.loginProtectionCfg == nil {
+		cfg, err := env.NewLoginProtectionConfig()
+		if err != nil {
+			panic("failed to get login protection config: " + err.Error())
+		}
+		sp.loginProtectionCfg = cfg
+	}
+	return sp.loginProtectionCfg
+}
+
+func (sp *ServiceProvider) LoginAttemptRepo(ctx context.Context) repository.LoginAttemptRepository {
+	if sp.loginAttemptRepo == nil {
+		sp.loginAttemptRepo = login_attempt_repo.NewLoginAttemptRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.loginAttemptRepo
+}
+
+// LoginProtectionService учёт неудачных попыток входа и блокировки, общие для входа и проверок второго фактора
+func (sp *ServiceProvider) LoginProtectionService(ctx context.Context) service.LoginProtectionService {
+	if sp.loginProtectionServ == nil {
+		sp.loginProtectionServ = loginprotection.NewService(
+			sp.LoginProtectionCfg(),
+			sp.LoginAttemptRepo(ctx),
+		)
+	}
+	return sp.loginProtectionServ
+}
+
+func (sp *ServiceProvider) SessionCfg() config.SessionConfig {
+	if sp.sessionCfg == nil {
+		cfg, err := env.NewSessionConfig()
+		if err != nil {
+			panic("failed to get session config: " + err.Error())
+		}
+		sp.sessionCfg = cfg
+	}
+	return sp.sessionCfg
+}
+
+// SessionCleanupService периодически удаляет истекшие сессии
+func (sp *ServiceProvider) SessionCleanupService(ctx context.Context) service.SessionCleanupService {
+	if sp.sessionCleanupServ == nil {
+		sp.sessionCleanupServ = sessioncleanup.NewService(
+			sp.AuthRepo(ctx),
+			sp.SessionCfg().CleanupInterval(),
+		)
+	}
+	return sp.sessionCleanupServ
+}
+
 func (sp *ServiceProvider) AuthHandler(ctx context.Context) *authAPI.Handler {
 	if sp.authHand == nil {
 		sp.authHand = authAPI.NewHandler(authAPI.HandlerDeps{
-			Serv: sp.AuthService(ctx),
+			Serv:          sp.AuthService(ctx),
+			TwoFactorServ: sp.TwoFactorService(ctx),
+			PasswordServ:  sp.PasswordService(ctx),
 		})
 	}
 	return sp.authHand
@@ -157,11 +434,121 @@ func (sp *ServiceProvider) AuthMiddleware(ctx context.Context) *middleware.AuthM
 	return sp.authMw
 }
 
+func (sp *ServiceProvider) RateLimitCfg() config.RateLimitConfig {
+	if sp.rateLimitCfg == nil {
+		cfg, err := env.NewRateLimitConfig()
+		if err != nil {
+			panic("failed to get rate limit config: " + err.Error())
+		}
+		sp.rateLimitCfg = cfg
+	}
+	return sp.rateLimitCfg
+}
+
+// RateLimitRepo хранилище лимитов: в Postgres при нескольких репликах, иначе в памяти
+func (sp *ServiceProvider) RateLimitRepo(ctx context.Context) repository.RateLimitRepository {
+	if sp.rateLimitRepo == nil {
+		if sp.RateLimitCfg().SharedStore() {
+			sp.rateLimitRepo = rate_limit_repo.NewRateLimitRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+		} else {
+			sp.rateLimitRepo = rate_limit_repo.NewMemoryRateLimitRepository()
+		}
+	}
+	return sp.rateLimitRepo
+}
+
+// LoginAttemptCleanupService периодически удаляет устаревшие счётчики неудачных попыток входа
+func (sp *ServiceProvider) LoginAttemptCleanupService(ctx context.Context) service.LoginAttemptCleanupService {
+	if sp.loginAttemptCleanupServ == nil {
+		sp.loginAttemptCleanupServ = loginattemptcleanup.NewService(
+			sp.LoginAttemptRepo(ctx),
+			sp.LoginProtectionCfg().FailureWindow(),
+			sp.LoginProtectionCfg().CleanupInterval(),
+		)
+	}
+	return sp.loginAttemptCleanupServ
+}
+
+// RateLimitCleanupService периодически удаляет пополнившиеся корзины лимитов запросов
+func (sp *ServiceProvider) RateLimitCleanupService(ctx context.Context) service.RateLimitCleanupService {
+	if sp.rateLimitCleanupServ == nil {
+		sp.rateLimitCleanupServ = ratelimitcleanup.NewService(
+			sp.RateLimitRepo(ctx),
+			sp.RateLimitCfg().RefillTime(),
+			sp.RateLimitCfg().CleanupInterval(),
+		)
+	}
+	return sp.rateLimitCleanupServ
+}
+
+func (sp *ServiceProvider) RateLimitMiddleware(ctx context.Context) *middleware.RateLimitMiddleware {
+	if sp.rateLimitMw == nil {
+		sp.rateLimitMw = middleware.NewRateLimitMiddleware(sp.RateLimitRepo(ctx))
+	}
+	return sp.rateLimitMw
+}
+
+func (sp *ServiceProvider) IdempotencyRepo(ctx context.Context) repository.IdempotencyRepository {
+	if sp.idempotencyRepo == nil {
+		sp.idempotencyRepo = idempotency_repo.NewIdempotencyRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.idempotencyRepo
+}
+
+func (sp *ServiceProvider) IdempotencyMiddleware(ctx context.Context) *middleware.IdempotencyMiddleware {
+	if sp.idempotencyMw == nil {
+		sp.idempotencyMw = middleware.NewIdempotencyMiddleware(sp.IdempotencyRepo(ctx), sp.IdempotencyCfg().LockTimeout())
+	}
+	return sp.idempotencyMw
+}
+
+func (sp *ServiceProvider) IdempotencyCfg() config.IdempotencyConfig {
+	if sp.idempotencyCfg == nil {
+		cfg, err := env.NewIdempotencyConfig()
+		if err != nil {
+			panic("failed to get idempotency config: " + err.Error())
+		}
+		sp.idempotencyCfg = cfg
+	}
+	return sp.idempotencyCfg
+}
+
+// IdempotencyCleanupService периодически удаляет старые ключи идемпотентности
+func (sp *ServiceProvider) IdempotencyCleanupService(ctx context.Context) service.IdempotencyCleanupService {
+	if sp.idempotencyCleanupServ == nil {
+		sp.idempotencyCleanupServ = idempotencycleanup.NewService(
+			sp.IdempotencyRepo(ctx),
+			sp.IdempotencyCfg().KeyTTL(),
+			sp.IdempotencyCfg().CleanupInterval(),
+		)
+	}
+	return sp.idempotencyCleanupServ
+}
+
+func (sp *ServiceProvider) TransactionRepo(ctx context.Context) repository.TransactionRepository {
+	if sp.transactionRepo == nil {
+		sp.transactionRepo = transaction_repo.NewTransactionRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.transactionRepo
+}
+
+func (sp *ServiceProvider) WalletService(ctx context.Context) service.WalletService {
+	if sp.walletServ == nil {
+		sp.walletServ = wallet.NewService(
+			sp.TXManager(ctx),
+			sp.UserRepo(ctx),
+			sp.TransactionRepo(ctx),
+		)
+	}
+	return sp.walletServ
+}
+
 func (sp *ServiceProvider) PaymentService(ctx context.Context) service.PaymentService {
 	if sp.payServ == nil {
 		sp.payServ = payService.NewService(
-			sp.TXManager(ctx),
+			sp.WalletService(ctx),
 			sp.UserRepo(ctx),
+			sp.TransactionRepo(ctx),
 		)
 	}
 	return sp.payServ
@@ -189,9 +576,87 @@ func (sp *ServiceProvider) TXManager(ctx context.Context) trm.Manager {
 	return sp.txManager
 }
 
+func (sp *ServiceProvider) RoundRepo(ctx context.Context) repository.RoundRepository {
+	if sp.roundRepo == nil {
+		sp.roundRepo = round_repo.NewRoundRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.roundRepo
+}
+
+func (sp *ServiceProvider) HistoryService(ctx context.Context) service.HistoryService {
+	if sp.historyServ == nil {
+		sp.historyServ = history.NewService(sp.RoundRepo(ctx))
+	}
+	return sp.historyServ
+}
+
+func (sp *ServiceProvider) HistoryHandler(ctx context.Context) *historyAPI.Handler {
+	if sp.historyHand == nil {
+		sp.historyHand = historyAPI.NewHandler(historyAPI.HandlerDeps{
+			Serv: sp.HistoryService(ctx),
+		})
+	}
+	return sp.historyHand
+}
+
+func (sp *ServiceProvider) RNGCfg() config.RNGConfig {
+	if sp.rngCfg == nil {
+		cfg, err := env.NewRNGConfig()
+		if err != nil {
+			panic("failed to get rng config: " + err.Error())
+		}
+		sp.rngCfg = cfg
+	}
+	return sp.rngCfg
+}
+
+func (sp *ServiceProvider) RNG() rng.RNG {
+	if sp.rnd == nil {
+		sp.rnd = rng.NewCrypto()
+	}
+	return sp.rnd
+}
+
+// RNGProvider выбирает источник случайности для спинов в зависимости от RNG_MODE
+func (sp *ServiceProvider) RNGProvider(ctx context.Context) service.RNGProvider {
+	if sp.rngProvider == nil {
+		if sp.RNGCfg().ProvablyFair() {
+			sp.rngProvider = fairness.NewService(sp.TXManager(ctx), sp.FairSeedRepo(ctx))
+		} else {
+			sp.rngProvider = fairness.NewStaticProvider(sp.RNG())
+		}
+	}
+	return sp.rngProvider
+}
+
+func (sp *ServiceProvider) FairSeedRepo(ctx context.Context) repository.FairSeedRepository {
+	if sp.fairSeedRepo == nil {
+		sp.fairSeedRepo = fair_seed_repo.NewFairSeedRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.fairSeedRepo
+}
+
+func (sp *ServiceProvider) FairnessService(ctx context.Context) service.FairnessService {
+	if sp.fairServ == nil {
+		sp.fairServ = fairness.NewService(sp.TXManager(ctx), sp.FairSeedRepo(ctx))
+	}
+	return sp.fairServ
+}
+
+func (sp *ServiceProvider) FairnessHandler(ctx context.Context) *fairnessAPI.Handler {
+	if sp.fairHand == nil {
+		sp.fairHand = fairnessAPI.NewHandler(fairnessAPI.HandlerDeps{
+			Serv:        sp.FairnessService(ctx),
+			LineServ:    sp.LineService(ctx),
+			CascadeServ: sp.CascadeService(ctx),
+		})
+	}
+	return sp.fairHand
+}
+
 func (sp *ServiceProvider) LineCfg() config.LineConfig {
 	if sp.lineCfg == nil {
-		cfg, err := env.NewLineConfigFromYAML("config-line.yaml")
+		cfg, err := env.NewLineConfigFromYAML(lineConfigPath, lineConfigCount)
 		if err != nil {
 			panic("failed to get line config: " + err.Error())
 		}
@@ -203,25 +668,33 @@ func (sp *ServiceProvider) LineCfg() config.LineConfig {
 
 func (sp *ServiceProvider) LineRepository(ctx context.Context) repository.LineRepository {
 	if sp.lineRepo == nil {
-		sp.lineRepo = line_repo.NewLineRepository(sp.DBClient(ctx))
+		sp.lineRepo = line_repo.NewLineRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
 	}
 	return sp.lineRepo
 }
 
-func (sp *ServiceProvider) LineStatsRepository() repository.LineStatsRepository {
-	if sp.lineStatsRepo == nil {
-		sp.lineStatsRepo = line_state_repo.NewLineStatsRepository()
+func (sp *ServiceProvider) LineRTPSegments() repository.RTPSegmentsRepository {
+	if sp.lineRTPSegments == nil {
+		segments, err := rtp_controller_repo.NewRTPSegmentsRepository(string(model.GameLine), sp.LineCfg(), sp.RTPControllerCfg())
+		if err != nil {
+			panic("failed to create line rtp controllers: " + err.Error())
+		}
+		sp.lineRTPSegments = segments
 	}
-	return sp.lineStatsRepo
+	return sp.lineRTPSegments
 }
 
 func (sp *ServiceProvider) LineService(ctx context.Context) service.LineService {
 	if sp.lineServ == nil {
 		sp.lineServ = line.NewLineService(
+			sp.LineCfg(),
 			sp.LineRepository(ctx),
 			sp.UserRepo(ctx),
-			sp.LineStatsRepository(),
+			sp.WalletService(ctx),
+			sp.RoundRepo(ctx),
+			sp.LineRTPSegments(),
 			sp.TXManager(ctx),
+			sp.RNGProvider(ctx),
 		)
 	}
 	return sp.lineServ
@@ -238,7 +711,7 @@ func (sp *ServiceProvider) LineHandler(ctx context.Context) *lineAPI.Handler {
 
 func (sp *ServiceProvider) CascadeCfg() config.CascadeConfig {
 	if sp.cascadeCfg == nil {
-		cfg, err := env.NewCascadeConfigFromYAML("config-cascade.yaml")
+		cfg, err := env.NewCascadeConfigFromYAML(cascadeConfigPath, cascadeConfigCount)
 		if err != nil {
 			panic("failed to get cascade config: " + err.Error())
 		}
@@ -249,16 +722,20 @@ func (sp *ServiceProvider) CascadeCfg() config.CascadeConfig {
 
 func (sp *ServiceProvider) CascadeRepository(ctx context.Context) repository.CascadeRepository {
 	if sp.cascadeRepo == nil {
-		sp.cascadeRepo = cascade_repo.NewCascadeRepository(sp.DBClient(ctx))
+		sp.cascadeRepo = cascade_repo.NewCascadeRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
 	}
 	return sp.cascadeRepo
 }
 
-func (sp *ServiceProvider) CascadeStatsRepository() repository.CascadeStatsRepository {
-	if sp.cascadeStatsRepo == nil {
-		sp.cascadeStatsRepo = cascade_stats_repo.NewCascadeStatsRepository()
+func (sp *ServiceProvider) CascadeRTPSegments() repository.RTPSegmentsRepository {
+	if sp.cascadeRTPSegments == nil {
+		segments, err := rtp_controller_repo.NewRTPSegmentsRepository(string(model.GameCascade), sp.CascadeCfg(), sp.RTPControllerCfg())
+		if err != nil {
+			panic("failed to create cascade rtp controllers: " + err.Error())
+		}
+		sp.cascadeRTPSegments = segments
 	}
-	return sp.cascadeStatsRepo
+	return sp.cascadeRTPSegments
 }
 
 func (sp *ServiceProvider) CascadeService(ctx context.Context) service.CascadeService {
@@ -267,8 +744,11 @@ func (sp *ServiceProvider) CascadeService(ctx context.Context) service.CascadeSe
 			sp.CascadeCfg(),
 			sp.CascadeRepository(ctx),
 			sp.UserRepo(ctx),
-			sp.CascadeStatsRepository(),
+			sp.WalletService(ctx),
+			sp.RoundRepo(ctx),
+			sp.CascadeRTPSegments(),
 			sp.TXManager(ctx),
+			sp.RNGProvider(ctx),
 		)
 	}
 	return sp.cascadeServ
@@ -281,6 +761,104 @@ func (sp *ServiceProvider) CascadeHandler(ctx context.Context) *cascadeAPI.Handl
 	return sp.cascadeHand
 }
 
+// RTPControllerCfg настройки регуляторов RTP (стратегия и её параметры) для каждой игры
+func (sp *ServiceProvider) RTPControllerCfg() config.RTPControllerConfig {
+	if sp.rtpControllerCfg == nil {
+		cfg, err := env.NewRTPControllerConfigFromYAML(rtpConfigPath, string(model.GameLine), string(model.GameCascade))
+		if err != nil {
+			panic("failed to get rtp controller config: " + err.Error())
+		}
+		sp.rtpControllerCfg = cfg
+	}
+	return sp.rtpControllerCfg
+}
+
+func (sp *ServiceProvider) RTPStateCfg() config.RTPStateConfig {
+	if sp.rtpStateCfg == nil {
+		cfg, err := env.NewRTPStateConfig()
+		if err != nil {
+			panic("failed to get rtp state config: " + err.Error())
+		}
+		sp.rtpStateCfg = cfg
+	}
+	return sp.rtpStateCfg
+}
+
+func (sp *ServiceProvider) RTPStateRepo(ctx context.Context) repository.RTPStateRepository {
+	if sp.rtpStateRepo == nil {
+		sp.rtpStateRepo = rtp_state_repo.NewRTPStateRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.rtpStateRepo
+}
+
+// RTPStateService синхронизирует регуляторы RTP обеих игр с общим снимком в БД
+func (sp *ServiceProvider) RTPStateService(ctx context.Context) service.RTPStateService {
+	if sp.rtpStateServ == nil {
+		sp.rtpStateServ = rtpstate.NewService(
+			sp.TXManager(ctx),
+			sp.RTPStateRepo(ctx),
+			sp.RTPStateCfg().SyncInterval(),
+			map[model.Game]repository.RTPSegmentsRepository{
+				model.GameLine:    sp.LineRTPSegments(),
+				model.GameCascade: sp.CascadeRTPSegments(),
+			},
+		)
+	}
+	return sp.rtpStateServ
+}
+
+func (sp *ServiceProvider) RTPAuditRepo(ctx context.Context) repository.RTPAuditRepository {
+	if sp.rtpAuditRepo == nil {
+		sp.rtpAuditRepo = rtp_audit_repo.NewRTPAuditRepository(sp.DBClient(ctx), trmpgx.DefaultCtxGetter)
+	}
+	return sp.rtpAuditRepo
+}
+
+func (sp *ServiceProvider) RTPAdminService(ctx context.Context) service.RTPAdminService {
+	if sp.rtpAdminServ == nil {
+		sp.rtpAdminServ = rtpadmin.NewService(
+			sp.TXManager(ctx),
+			map[model.Game]repository.RTPSegmentsRepository{
+				model.GameLine:    sp.LineRTPSegments(),
+				model.GameCascade: sp.CascadeRTPSegments(),
+			},
+			sp.RTPAuditRepo(ctx),
+			sp.RTPStateService(ctx),
+		)
+	}
+	return sp.rtpAdminServ
+}
+
+func (sp *ServiceProvider) AdminHandler(ctx context.Context) *adminAPI.Handler {
+	if sp.adminHand == nil {
+		sp.adminHand = adminAPI.NewHandler(adminAPI.HandlerDeps{
+			Serv: sp.RTPAdminService(ctx),
+		})
+	}
+	return sp.adminHand
+}
+
+func (sp *ServiceProvider) UserAdminService(ctx context.Context) service.UserAdminService {
+	if sp.userAdminServ == nil {
+		sp.userAdminServ = useradmin.NewService(
+			sp.TXManager(ctx),
+			sp.UserRepo(ctx),
+			sp.AuthRepo(ctx),
+			sp.LoginAttemptRepo(ctx),
+		)
+	}
+	return sp.userAdminServ
+}
+
+func (sp *ServiceProvider) UsersHandler(ctx context.Context) *usersAPI.Handler {
+	if sp.usersHand == nil {
+		sp.usersHand = usersAPI.NewHandler(usersAPI.HandlerDeps{
+			Serv: sp.UserAdminService(ctx),
+		})
+	}
+	return sp.usersHand
+}
+
 func (sp *ServiceProvider) HTTPCfg() config.HTTPConfig {
 	if sp.httpCfg == nil {
 		cfg, err := env.NewHTTPConfig()
@@ -301,45 +879,118 @@ func (sp *ServiceProvider) Router(ctx context.Context) chi.Router {
 		r.Use(cors.Handler(cors.Options{
 			AllowedOrigins:   []string{"http://158.160.167.237"},
 			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"Link"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", middleware.IdempotencyKeyHeader},
+			ExposedHeaders:   []string{"Link", "Retry-After", middleware.IdempotentReplayedHeader},
 			AllowCredentials: true,
 			MaxAge:           60 * 15,
 		}))
 
+		authMiddleware := sp.AuthMiddleware(ctx)
+
+		// Ограничение частоты запросов
+		rateLimit := sp.RateLimitMiddleware(ctx)
+		authLimit := rateLimit.Limit(config.RateLimitRouteAuth, sp.RateLimitCfg().Limit(config.RateLimitRouteAuth))
+		spinLimit := rateLimit.Limit(config.RateLimitRouteSpin, sp.RateLimitCfg().Limit(config.RateLimitRouteSpin))
+
 		// Auth endpoints (public)
 		authHandler := sp.AuthHandler(ctx)
 		r.Route("/auth", func(rr chi.Router) {
-			rr.Post("/register", authHandler.Register)
-			rr.Post("/login", authHandler.Login)
-			rr.Post("/refresh", authHandler.Refresh)
+			rr.With(authLimit).Post("/register", authHandler.Register)
+			rr.With(authLimit).Post("/login", authHandler.Login)
+			rr.With(authLimit).Post("/login/2fa", authHandler.LoginTwoFactor)
+			rr.With(authLimit).Post("/refresh", authHandler.Refresh)
 			rr.Post("/logout", authHandler.Logout)
+			rr.With(authLimit).Post("/password/reset-request", authHandler.RequestPasswordReset)
+			rr.With(authLimit).Post("/password/reset", authHandler.ResetPassword)
+
+			// Управление сессиями и двухфакторной аутентификацией текущего пользователя
+			rr.Group(func(sr chi.Router) {
+				sr.Use(authMiddleware.Handle)
+				sr.Get("/sessions", authHandler.Sessions)
+				sr.Delete("/sessions", authHandler.RevokeAllSessions)
+				sr.Delete("/sessions/{sessionID}", authHandler.RevokeSession)
+
+				sr.Post("/2fa/enroll", authHandler.EnrollTwoFactor)
+				sr.With(authLimit).Post("/2fa/confirm", authHandler.ConfirmTwoFactor)
+				sr.With(authLimit, middleware.RequireTwoFactor).Post("/2fa/disable", authHandler.DisableTwoFactor)
+
+				sr.With(authLimit).Put("/password", authHandler.ChangePassword)
+			})
 		})
 
+		// Provably-fair: проверка спина доступна без авторизации, поэтому под лимитом auth
+		fairnessHandler := sp.FairnessHandler(ctx)
+		r.With(authLimit).Post("/fair/verify", fairnessHandler.Verify)
+
 		// Protected routes (require authentication)
-		authMiddleware := sp.AuthMiddleware(ctx)
 		r.Group(func(rr chi.Router) {
 			rr.Use(authMiddleware.Handle)
 
+			// Денежные операции поддерживают заголовок Idempotency-Key
+			idempotency := sp.IdempotencyMiddleware(ctx).Handle
+
 			// Payment endpoints
 			payHandler := sp.PaymentHandler(ctx)
 			rr.Route("/pay", func(pr chi.Router) {
-				pr.Post("/deposit", payHandler.Deposit)
+				pr.With(idempotency).Post("/deposit", payHandler.Deposit)
+				pr.With(middleware.RequireTwoFactor, idempotency).Post("/withdraw", payHandler.Withdraw)
 				pr.Get("/balance", payHandler.GetBalance)
+				pr.Get("/transactions", payHandler.Transactions)
+			})
+
+			// History endpoints
+			historyHandler := sp.HistoryHandler(ctx)
+			rr.Route("/history", func(hr chi.Router) {
+				hr.Get("/", historyHandler.List)
+				hr.Get("/{roundID}", historyHandler.Get)
+			})
+
+			// Provably-fair seeds endpoints
+			rr.Route("/fair", func(fr chi.Router) {
+				fr.Get("/seed", fairnessHandler.Seed)
+				fr.Put("/client-seed", fairnessHandler.SetClientSeed)
+				fr.Post("/rotate", fairnessHandler.Rotate)
 			})
 
 			// Line endpoints
 			lineHandler := sp.LineHandler(ctx)
 			rr.Route("/line", func(lr chi.Router) {
-				lr.Post("/spin", lineHandler.Spin)
-				lr.Post("/buy-bonus", lineHandler.BuyBonus)
+				lr.Use(spinLimit)
+				lr.With(idempotency).Post("/spin", lineHandler.Spin)
+				lr.With(idempotency).Post("/buy-bonus", lineHandler.BuyBonus)
 			})
 
 			// Cascade endpoints
 			cascadeHandler := sp.CascadeHandler(ctx)
 			rr.Route("/cascade", func(cr chi.Router) {
-				cr.Post("/spin", cascadeHandler.Spin)
-				cr.Post("/buy-bonus", cascadeHandler.BuyBonus)
+				cr.Use(spinLimit)
+				cr.With(idempotency).Post("/spin", cascadeHandler.Spin)
+				cr.With(idempotency).Post("/buy-bonus", cascadeHandler.BuyBonus)
+			})
+
+			usersHandler := sp.UsersHandler(ctx)
+
+			// Support endpoints (support, admin)
+			rr.Route("/support", func(sr chi.Router) {
+				sr.Use(middleware.RequireRole(model.RoleSupport, model.RoleAdmin))
+				sr.Get("/users/{userID}", usersHandler.Get)
+				sr.Post("/users/{userID}/unlock", usersHandler.UnlockLogin)
+			})
+
+			// Admin endpoints (admin)
+			adminHandler := sp.AdminHandler(ctx)
+			rr.Route("/admin", func(ar chi.Router) {
+				ar.Use(middleware.RequireRole(model.RoleAdmin))
+				ar.Use(middleware.RequireTwoFactor)
+				ar.Put("/users/{userID}/role", usersHandler.SetRole)
+				ar.Route("/rtp", func(rtp chi.Router) {
+					rtp.Get("/audit", adminHandler.AuditLog)
+					rtp.Get("/{game}", adminHandler.State)
+					rtp.Put("/{game}/index", adminHandler.SetIndex)
+					rtp.Put("/{game}/target-rtp", adminHandler.SetTargetRTP)
+					rtp.Put("/{game}/window-size", adminHandler.SetWindowSize)
+					rtp.Put("/{game}/paused", adminHandler.SetPaused)
+				})
 			})
 		})
 
@@ -15,6 +15,8 @@ func Load(path string) error {
 }
 
 type LineConfig interface {
+	Count() int
+	Name(idx int) string
 	SymbolWeights(idx int) map[string]int
 	WildChance(idx int) float64
 	FreeSpinsByScatter(idx int) map[int]int
@@ -22,6 +24,8 @@ type LineConfig interface {
 }
 
 type CascadeConfig interface {
+	Count() int
+	Name(idx int) string
 	SymbolWeights(idx int) map[int]int
 	BonusProbPerColumn(idx int) float64
 	BonusAwards(idx int) map[int]int
@@ -41,3 +45,101 @@ type JWTConfig interface {
 	AccessTokenDuration() time.Duration
 	RefreshTokenDuration() time.Duration
 }
+
+type RNGConfig interface {
+	ProvablyFair() bool
+}
+
+type RTPStateConfig interface {
+	SyncInterval() time.Duration
+}
+
+// PasswordConfig Сброс пароля, требования к новым паролям и хэширование.
+// Хэши с другим алгоритмом или стоимостью пересчитываются при следующем успешном входе
+type PasswordConfig interface {
+	ResetTokenTTL() time.Duration
+	MinLength() int
+	BreachedListFile() string // Пустой — без проверки по списку скомпрометированных паролей
+	HashAlgorithm() string    // bcrypt или argon2id
+	BcryptCost() int
+}
+
+// NotifierConfig Куда доставлять сообщения пользователям: пустой NotificationFile — в лог приложения
+type NotifierConfig interface {
+	NotificationFile() string
+}
+
+type SessionConfig interface {
+	CleanupInterval() time.Duration
+}
+
+// IdempotencyConfig Ключи идемпотентности: когда резерв считается брошенным, сколько хранить ключи
+// и как часто удалять старые
+type IdempotencyConfig interface {
+	LockTimeout() time.Duration
+	KeyTTL() time.Duration
+	CleanupInterval() time.Duration
+}
+
+// Маршруты с отдельными лимитами запросов
+const (
+	RateLimitRouteAuth = "auth" // Регистрация, вход, обновление токенов — по IP
+	RateLimitRouteSpin = "spin" // Спины и покупка бонусов — по пользователю
+)
+
+// RateLimit Лимит token bucket: в среднем Rate запросов в секунду, не больше Burst подряд
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimitConfig interface {
+	// SharedStore — хранить счётчики в Postgres (общие для всех реплик), иначе в памяти процесса
+	SharedStore() bool
+	Limit(route string) RateLimit
+	// RefillTime — за сколько пополняется до конца самая медленная корзина: после этого корзину можно удалить
+	RefillTime() time.Duration
+	// CleanupInterval — как часто удалять из Postgres пополнившиеся корзины
+	CleanupInterval() time.Duration
+}
+
+// LoginProtectionConfig Защита входа от перебора паролей.
+// После FreeAttempts неудач подряд каждая следующая блокирует вход на BackoffBase, удваиваясь до MaxLockout
+type LoginProtectionConfig interface {
+	LoginFreeAttempts() int // Неудач подряд по одному логину без блокировки
+	IPFreeAttempts() int    // Неудач подряд с одного IP без блокировки
+	BackoffBase() time.Duration
+	MaxLockout() time.Duration
+	FailureWindow() time.Duration   // Пауза, после которой счётчик неудач начинается заново
+	CleanupInterval() time.Duration // Как часто удалять устаревшие счётчики неудач
+}
+
+// RTPControllerSettings Настройки регулятора RTP одной игры
+type RTPControllerSettings struct {
+	Strategy   string  // window, pid или ewma
+	TargetRTP  float64 // Целевой RTP, %
+	WindowSize int     // Размер окна (для ewma — эквивалентный период сглаживания), спинов
+	MinSpins   int     // Сколько спинов нужно в окне, прежде чем принимать решения
+	CheckEvery int     // Периодичность решений, спинов
+	Tolerance  float64 // Допустимое отклонение (для pid — порог управляющего воздействия), п.п.
+	Critical   float64 // Отклонение для аварийного шага (только window, 0 — выключено), п.п.
+	Kp, Ki, Kd float64 // Коэффициенты ПИД-регулятора
+}
+
+// RTPSegment Сегмент игроков со своим регулятором RTP.
+// Спин попадает в первый сегмент, условиям которого соответствует, иначе — в сегмент по умолчанию
+type RTPSegment struct {
+	Name     string
+	Player   string                // new, returning или пусто — любые игроки
+	MinBet   int                   // Нижняя граница ставки включительно
+	MaxBet   int                   // Верхняя граница ставки включительно, 0 — без ограничения
+	Settings RTPControllerSettings // Настройки игры с переопределениями сегмента
+}
+
+type RTPControllerConfig interface {
+	// Settings настройки регулятора сегмента по умолчанию
+	Settings(game string) RTPControllerSettings
+	Segments(game string) []RTPSegment
+	// NewPlayerRounds сколько раундов в игре игрок считается новичком
+	NewPlayerRounds(game string) int
+}
@@ -2,12 +2,14 @@ package env
 
 import (
 	"casino_backend/internal/config"
+	"casino_backend/internal/config/validator"
 	"os"
 
 	"gopkg.in/yaml.v3"
 )
 
 type casdata struct {
+	NameValue         string      `yaml:"name"`
 	SymbolWeightsData map[int]int `yaml:"cascade_symbol_weights"`
 	BonusPerColumn    float64     `yaml:"cascade_bonus_per_column"`
 	BonusAwardsData   map[int]int `yaml:"cascade_bonus_awards"`
@@ -18,7 +20,9 @@ type cascadeConfigs struct {
 	Configs []casdata `yaml:"configs"`
 }
 
-func NewCascadeConfigFromYAML(path string) (config.CascadeConfig, error) {
+// NewCascadeConfigFromYAML загружает конфиги каскадного слота и проверяет их.
+// expectedCount — сколько конфигов должно быть в файле
+func NewCascadeConfigFromYAML(path string, expectedCount int) (config.CascadeConfig, error) {
 	confData, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -29,9 +33,21 @@ func NewCascadeConfigFromYAML(path string) (config.CascadeConfig, error) {
 		return nil, err
 	}
 
+	if err := validator.ValidateCascade(path, &result, expectedCount); err != nil {
+		return nil, err
+	}
+
 	return &result, nil
 }
 
+func (cfg *cascadeConfigs) Count() int {
+	return len(cfg.Configs)
+}
+
+func (cfg *cascadeConfigs) Name(idx int) string {
+	return cfg.Configs[idx].NameValue
+}
+
 func (cfg *cascadeConfigs) SymbolWeights(idx int) map[int]int {
 	return cfg.Configs[idx].SymbolWeightsData
 }
@@ -0,0 +1,65 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"fmt"
+	"time"
+)
+
+const (
+	idempotencyLockTimeoutEnvName     = "IDEMPOTENCY_LOCK_TIMEOUT"
+	idempotencyKeyTTLEnvName          = "IDEMPOTENCY_KEY_TTL"
+	idempotencyCleanupIntervalEnvName = "IDEMPOTENCY_CLEANUP_INTERVAL"
+
+	// Через сколько резерв без ответа можно перехватить: с большим запасом над временем любого обработчика
+	defaultIdempotencyLockTimeout = time.Minute
+	// Сколько хранить ключи по умолчанию: клиенты повторяют запросы в пределах минут, сутки — с запасом
+	defaultIdempotencyKeyTTL = 24 * time.Hour
+	// Период удаления старых ключей по умолчанию
+	defaultIdempotencyCleanupInterval = time.Hour
+)
+
+type idempotencyConfig struct {
+	lockTimeout     time.Duration
+	keyTTL          time.Duration
+	cleanupInterval time.Duration
+}
+
+func NewIdempotencyConfig() (config.IdempotencyConfig, error) {
+	lockTimeout, err := positiveDurationEnv(idempotencyLockTimeoutEnvName, defaultIdempotencyLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	keyTTL, err := positiveDurationEnv(idempotencyKeyTTLEnvName, defaultIdempotencyKeyTTL)
+	if err != nil {
+		return nil, err
+	}
+	cleanupInterval, err := positiveDurationEnv(idempotencyCleanupIntervalEnvName, defaultIdempotencyCleanupInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Иначе ключ может удалиться, пока запрос ещё обрабатывается
+	if keyTTL <= lockTimeout {
+		return nil, fmt.Errorf("%s (%s) must be longer than %s (%s)",
+			idempotencyKeyTTLEnvName, keyTTL, idempotencyLockTimeoutEnvName, lockTimeout)
+	}
+
+	return &idempotencyConfig{
+		lockTimeout:     lockTimeout,
+		keyTTL:          keyTTL,
+		cleanupInterval: cleanupInterval,
+	}, nil
+}
+
+func (c *idempotencyConfig) LockTimeout() time.Duration {
+	return c.lockTimeout
+}
+
+func (c *idempotencyConfig) KeyTTL() time.Duration {
+	return c.keyTTL
+}
+
+func (c *idempotencyConfig) CleanupInterval() time.Duration {
+	return c.cleanupInterval
+}
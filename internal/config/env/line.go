@@ -2,12 +2,15 @@ package env
 
 import (
 	"casino_backend/internal/config"
+	"casino_backend/internal/config/validator"
+	"log"
 	"os"
 
 	"gopkg.in/yaml.v3"
 )
 
 type data struct {
+	NameValue         string                 `yaml:"name"`
 	SymbolWeightsData map[string]int         `yaml:"line_symbol_weights"`
 	WildChanceValue   float64                `yaml:"line_wild_chance_on_reel_2_3_4"`
 	FreeSpinsScatter  map[int]int            `yaml:"line_free_spins_by_scatter"`
@@ -18,7 +21,9 @@ type lineConfig struct {
 	Configs []data `yaml:"configs"`
 }
 
-func NewLineConfigFromYAML(path string) (config.LineConfig, error) {
+// NewLineConfigFromYAML загружает конфиги line-слота и проверяет их.
+// expectedCount — сколько конфигов должно быть в файле
+func NewLineConfigFromYAML(path string, expectedCount int) (config.LineConfig, error) {
 	confData, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -29,9 +34,25 @@ func NewLineConfigFromYAML(path string) (config.LineConfig, error) {
 		return nil, err
 	}
 
+	warnings, err := validator.ValidateLine(path, &result, expectedCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range warnings {
+		log.Printf("%s: %s", path, w)
+	}
+
 	return &result, nil
 }
 
+func (cfg *lineConfig) Count() int {
+	return len(cfg.Configs)
+}
+
+func (cfg *lineConfig) Name(idx int) string {
+	return cfg.Configs[idx].NameValue
+}
+
 func (cfg *lineConfig) SymbolWeights(idx int) map[string]int {
 	return cfg.Configs[idx].SymbolWeightsData
 }
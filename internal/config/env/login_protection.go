@@ -0,0 +1,128 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	loginFreeAttemptsEnvName    = "LOGIN_FREE_ATTEMPTS"
+	loginIPFreeAttemptsEnvName  = "LOGIN_IP_FREE_ATTEMPTS"
+	loginBackoffBaseEnvName     = "LOGIN_BACKOFF_BASE"
+	loginMaxLockoutEnvName      = "LOGIN_MAX_LOCKOUT"
+	loginFailureWindowEnvName   = "LOGIN_FAILURE_WINDOW"
+	loginCleanupIntervalEnvName = "LOGIN_ATTEMPTS_CLEANUP_INTERVAL"
+
+	// Значения по умолчанию
+	defaultLoginFreeAttempts    = 5
+	defaultLoginIPFreeAttempts  = 30
+	defaultLoginBackoffBase     = time.Second
+	defaultLoginMaxLockout      = 15 * time.Minute
+	defaultLoginFailureWindow   = 24 * time.Hour
+	defaultLoginCleanupInterval = time.Hour
+)
+
+type loginProtectionConfig struct {
+	loginFreeAttempts int
+	ipFreeAttempts    int
+	backoffBase       time.Duration
+	maxLockout        time.Duration
+	failureWindow     time.Duration
+	cleanupInterval   time.Duration
+}
+
+func NewLoginProtectionConfig() (config.LoginProtectionConfig, error) {
+	cfg := &loginProtectionConfig{
+		loginFreeAttempts: defaultLoginFreeAttempts,
+		ipFreeAttempts:    defaultLoginIPFreeAttempts,
+		backoffBase:       defaultLoginBackoffBase,
+		maxLockout:        defaultLoginMaxLockout,
+		failureWindow:     defaultLoginFailureWindow,
+		cleanupInterval:   defaultLoginCleanupInterval,
+	}
+
+	var err error
+	if cfg.loginFreeAttempts, err = positiveIntEnv(loginFreeAttemptsEnvName, cfg.loginFreeAttempts); err != nil {
+		return nil, err
+	}
+	if cfg.ipFreeAttempts, err = positiveIntEnv(loginIPFreeAttemptsEnvName, cfg.ipFreeAttempts); err != nil {
+		return nil, err
+	}
+	if cfg.backoffBase, err = positiveDurationEnv(loginBackoffBaseEnvName, cfg.backoffBase); err != nil {
+		return nil, err
+	}
+	if cfg.maxLockout, err = positiveDurationEnv(loginMaxLockoutEnvName, cfg.maxLockout); err != nil {
+		return nil, err
+	}
+	if cfg.failureWindow, err = positiveDurationEnv(loginFailureWindowEnvName, cfg.failureWindow); err != nil {
+		return nil, err
+	}
+	if cfg.cleanupInterval, err = positiveDurationEnv(loginCleanupIntervalEnvName, cfg.cleanupInterval); err != nil {
+		return nil, err
+	}
+
+	if cfg.maxLockout < cfg.backoffBase {
+		return nil, fmt.Errorf("login max lockout %s is less than backoff base %s", cfg.maxLockout, cfg.backoffBase)
+	}
+
+	return cfg, nil
+}
+
+// positiveIntEnv читает положительное целое из переменной окружения name, если она задана
+func positiveIntEnv(name string, def int) (int, error) {
+	raw := os.Getenv(name)
+	if len(raw) == 0 {
+		return def, nil
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if parsed <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %d", name, parsed)
+	}
+	return parsed, nil
+}
+
+// positiveDurationEnv читает положительную длительность из переменной окружения name, если она задана
+func positiveDurationEnv(name string, def time.Duration) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if len(raw) == 0 {
+		return def, nil
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if parsed <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %s", name, parsed)
+	}
+	return parsed, nil
+}
+
+func (c *loginProtectionConfig) LoginFreeAttempts() int {
+	return c.loginFreeAttempts
+}
+
+func (c *loginProtectionConfig) IPFreeAttempts() int {
+	return c.ipFreeAttempts
+}
+
+func (c *loginProtectionConfig) BackoffBase() time.Duration {
+	return c.backoffBase
+}
+
+func (c *loginProtectionConfig) MaxLockout() time.Duration {
+	return c.maxLockout
+}
+
+func (c *loginProtectionConfig) FailureWindow() time.Duration {
+	return c.failureWindow
+}
+
+func (c *loginProtectionConfig) CleanupInterval() time.Duration {
+	return c.cleanupInterval
+}
@@ -0,0 +1,20 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"os"
+)
+
+const notificationFileEnvName = "NOTIFICATION_FILE"
+
+type notifierConfig struct {
+	notificationFile string
+}
+
+func NewNotifierConfig() (config.NotifierConfig, error) {
+	return &notifierConfig{notificationFile: os.Getenv(notificationFileEnvName)}, nil
+}
+
+func (c *notifierConfig) NotificationFile() string {
+	return c.notificationFile
+}
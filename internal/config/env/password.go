@@ -0,0 +1,79 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/pkg/pass"
+	"os"
+	"time"
+)
+
+const (
+	passwordResetTTLEnvName      = "PASSWORD_RESET_TTL"
+	passwordMinLengthEnvName     = "PASSWORD_MIN_LENGTH"
+	passwordBreachedListEnvName  = "PASSWORD_BREACHED_LIST"
+	passwordHashAlgorithmEnvName = "PASSWORD_HASH_ALGORITHM"
+	passwordBcryptCostEnvName    = "PASSWORD_BCRYPT_COST"
+
+	// Срок действия токена сброса пароля по умолчанию
+	defaultPasswordResetTTL = 30 * time.Minute
+	// Минимальная длина пароля по умолчанию
+	defaultPasswordMinLength = 8
+	// Стоимость bcrypt по умолчанию; хэши со старой стоимостью 10 пересчитываются при входе
+	defaultPasswordBcryptCost = 12
+)
+
+type passwordConfig struct {
+	resetTokenTTL    time.Duration
+	minLength        int
+	breachedListFile string
+	hashAlgorithm    string
+	bcryptCost       int
+}
+
+func NewPasswordConfig() (config.PasswordConfig, error) {
+	resetTokenTTL, err := positiveDurationEnv(passwordResetTTLEnvName, defaultPasswordResetTTL)
+	if err != nil {
+		return nil, err
+	}
+	minLength, err := positiveIntEnv(passwordMinLengthEnvName, defaultPasswordMinLength)
+	if err != nil {
+		return nil, err
+	}
+	bcryptCost, err := positiveIntEnv(passwordBcryptCostEnvName, defaultPasswordBcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	hashAlgorithm := os.Getenv(passwordHashAlgorithmEnvName)
+	if len(hashAlgorithm) == 0 {
+		hashAlgorithm = pass.AlgorithmBcrypt
+	}
+
+	return &passwordConfig{
+		resetTokenTTL:    resetTokenTTL,
+		minLength:        minLength,
+		breachedListFile: os.Getenv(passwordBreachedListEnvName),
+		hashAlgorithm:    hashAlgorithm,
+		bcryptCost:       bcryptCost,
+	}, nil
+}
+
+func (c *passwordConfig) ResetTokenTTL() time.Duration {
+	return c.resetTokenTTL
+}
+
+func (c *passwordConfig) MinLength() int {
+	return c.minLength
+}
+
+func (c *passwordConfig) BreachedListFile() string {
+	return c.breachedListFile
+}
+
+func (c *passwordConfig) HashAlgorithm() string {
+	return c.hashAlgorithm
+}
+
+func (c *passwordConfig) BcryptCost() int {
+	return c.bcryptCost
+}
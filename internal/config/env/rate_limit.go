@@ -0,0 +1,96 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rateLimitStoreEnvName           = "RATE_LIMIT_STORE"
+	rateLimitCleanupIntervalEnvName = "RATE_LIMIT_CLEANUP_INTERVAL"
+
+	// Период удаления пополнившихся корзин по умолчанию
+	defaultRateLimitCleanupInterval = 10 * time.Minute
+
+	// Счётчики в памяти процесса: лимит действует на каждую реплику отдельно
+	rateLimitStoreMemory = "memory"
+	// Счётчики в Postgres: лимит общий для всех реплик
+	rateLimitStorePostgres = "postgres"
+)
+
+// Лимиты по умолчанию. Переопределяются переменными RATE_LIMIT_<ROUTE>_RATE и RATE_LIMIT_<ROUTE>_BURST
+var defaultRateLimits = map[string]config.RateLimit{
+	config.RateLimitRouteAuth: {Rate: 1, Burst: 10},
+	config.RateLimitRouteSpin: {Rate: 5, Burst: 20},
+}
+
+type rateLimitConfig struct {
+	store           string
+	limits          map[string]config.RateLimit
+	cleanupInterval time.Duration
+}
+
+func NewRateLimitConfig() (config.RateLimitConfig, error) {
+	store := os.Getenv(rateLimitStoreEnvName)
+	if len(store) == 0 {
+		store = rateLimitStoreMemory
+	}
+	if store != rateLimitStoreMemory && store != rateLimitStorePostgres {
+		return nil, fmt.Errorf("invalid rate limit store %q: expected %s or %s", store, rateLimitStoreMemory, rateLimitStorePostgres)
+	}
+
+	limits := make(map[string]config.RateLimit, len(defaultRateLimits))
+	for route, limit := range defaultRateLimits {
+		prefix := "RATE_LIMIT_" + strings.ToUpper(route)
+
+		if raw := os.Getenv(prefix + "_RATE"); len(raw) != 0 {
+			rate, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s_RATE: %w", prefix, err)
+			}
+			if rate <= 0 {
+				return nil, fmt.Errorf("%s_RATE must be positive, got %g", prefix, rate)
+			}
+			limit.Rate = rate
+		}
+
+		burst, err := positiveIntEnv(prefix+"_BURST", limit.Burst)
+		if err != nil {
+			return nil, err
+		}
+		limit.Burst = burst
+
+		limits[route] = limit
+	}
+
+	cleanupInterval, err := positiveDurationEnv(rateLimitCleanupIntervalEnvName, defaultRateLimitCleanupInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rateLimitConfig{store: store, limits: limits, cleanupInterval: cleanupInterval}, nil
+}
+
+func (c *rateLimitConfig) SharedStore() bool {
+	return c.store == rateLimitStorePostgres
+}
+
+func (c *rateLimitConfig) Limit(route string) config.RateLimit {
+	return c.limits[route]
+}
+
+func (c *rateLimitConfig) RefillTime() time.Duration {
+	var refill time.Duration
+	for _, limit := range c.limits {
+		refill = max(refill, time.Duration(float64(limit.Burst)/limit.Rate*float64(time.Second)))
+	}
+	return refill
+}
+
+func (c *rateLimitConfig) CleanupInterval() time.Duration {
+	return c.cleanupInterval
+}
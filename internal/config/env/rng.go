@@ -0,0 +1,37 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"fmt"
+	"os"
+)
+
+const (
+	rngModeEnvName = "RNG_MODE"
+
+	// Обычный режим: криптостойкий генератор, общий для всех спинов
+	rngModeCrypto = "crypto"
+	// Provably-fair режим: спины генерируются из сидов игрока (HMAC-SHA256)
+	rngModeProvablyFair = "provably_fair"
+)
+
+type rngConfig struct {
+	mode string
+}
+
+func NewRNGConfig() (config.RNGConfig, error) {
+	mode := os.Getenv(rngModeEnvName)
+	if len(mode) == 0 {
+		mode = rngModeCrypto
+	}
+
+	if mode != rngModeCrypto && mode != rngModeProvablyFair {
+		return nil, fmt.Errorf("invalid rng mode %q: expected %s or %s", mode, rngModeCrypto, rngModeProvablyFair)
+	}
+
+	return &rngConfig{mode: mode}, nil
+}
+
+func (c *rngConfig) ProvablyFair() bool {
+	return c.mode == rngModeProvablyFair
+}
@@ -0,0 +1,122 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/config/validator"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+type pidData struct {
+	Kp float64 `yaml:"kp"`
+	Ki float64 `yaml:"ki"`
+	Kd float64 `yaml:"kd"`
+}
+
+type controllerData struct {
+	Strategy   string  `yaml:"strategy"`
+	TargetRTP  float64 `yaml:"target_rtp"`
+	WindowSize int     `yaml:"window_size"`
+	MinSpins   int     `yaml:"min_spins"`
+	CheckEvery int     `yaml:"check_every"`
+	Tolerance  float64 `yaml:"tolerance"`
+	Critical   float64 `yaml:"critical"`
+	PID        pidData `yaml:"pid"`
+
+	NewPlayerRounds int           `yaml:"new_player_rounds"`
+	Segments        []segmentData `yaml:"segments"`
+}
+
+// segmentData Сегмент игроков: условия попадания и переопределения настроек игры (пустые — как у игры)
+type segmentData struct {
+	Name   string `yaml:"name"`
+	Player string `yaml:"player"`
+	MinBet int    `yaml:"min_bet"`
+	MaxBet int    `yaml:"max_bet"`
+
+	Strategy   *string  `yaml:"strategy"`
+	TargetRTP  *float64 `yaml:"target_rtp"`
+	WindowSize *int     `yaml:"window_size"`
+	MinSpins   *int     `yaml:"min_spins"`
+	CheckEvery *int     `yaml:"check_every"`
+	Tolerance  *float64 `yaml:"tolerance"`
+	Critical   *float64 `yaml:"critical"`
+	PID        *pidData `yaml:"pid"`
+}
+
+type rtpControllerConfig map[string]controllerData
+
+// NewRTPControllerConfigFromYAML загружает настройки регуляторов RTP и проверяет их.
+// games — игры, для которых настройки обязательны
+func NewRTPControllerConfigFromYAML(path string, games ...string) (config.RTPControllerConfig, error) {
+	confData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result rtpControllerConfig
+	if err := yaml.Unmarshal(confData, &result); err != nil {
+		return nil, err
+	}
+
+	if err := validator.ValidateRTPController(path, result, games); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (cfg rtpControllerConfig) Settings(game string) config.RTPControllerSettings {
+	c := cfg[game]
+	return config.RTPControllerSettings{
+		Strategy:   c.Strategy,
+		TargetRTP:  c.TargetRTP,
+		WindowSize: c.WindowSize,
+		MinSpins:   c.MinSpins,
+		CheckEvery: c.CheckEvery,
+		Tolerance:  c.Tolerance,
+		Critical:   c.Critical,
+		Kp:         c.PID.Kp,
+		Ki:         c.PID.Ki,
+		Kd:         c.PID.Kd,
+	}
+}
+
+func (cfg rtpControllerConfig) Segments(game string) []config.RTPSegment {
+	base := cfg.Settings(game)
+	segments := make([]config.RTPSegment, len(cfg[game].Segments))
+	for i, seg := range cfg[game].Segments {
+		settings := base
+		override(&settings.Strategy, seg.Strategy)
+		override(&settings.TargetRTP, seg.TargetRTP)
+		override(&settings.WindowSize, seg.WindowSize)
+		override(&settings.MinSpins, seg.MinSpins)
+		override(&settings.CheckEvery, seg.CheckEvery)
+		override(&settings.Tolerance, seg.Tolerance)
+		override(&settings.Critical, seg.Critical)
+		if seg.PID != nil {
+			settings.Kp, settings.Ki, settings.Kd = seg.PID.Kp, seg.PID.Ki, seg.PID.Kd
+		}
+
+		segments[i] = config.RTPSegment{
+			Name:     seg.Name,
+			Player:   seg.Player,
+			MinBet:   seg.MinBet,
+			MaxBet:   seg.MaxBet,
+			Settings: settings,
+		}
+	}
+	return segments
+}
+
+func (cfg rtpControllerConfig) NewPlayerRounds(game string) int {
+	return cfg[game].NewPlayerRounds
+}
+
+// override заменяет значение dst, если оно задано в сегменте
+func override[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
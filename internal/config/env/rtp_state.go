@@ -0,0 +1,39 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	rtpSyncIntervalEnvName = "RTP_SYNC_INTERVAL"
+
+	// Период синхронизации состояния регуляторов RTP с БД по умолчанию
+	defaultRTPSyncInterval = 5 * time.Second
+)
+
+type rtpStateConfig struct {
+	syncInterval time.Duration
+}
+
+func NewRTPStateConfig() (config.RTPStateConfig, error) {
+	syncInterval := defaultRTPSyncInterval
+	if raw := os.Getenv(rtpSyncIntervalEnvName); len(raw) != 0 {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rtp sync interval: %w", err)
+		}
+		if parsed <= 0 {
+			return nil, fmt.Errorf("rtp sync interval must be positive, got %s", parsed)
+		}
+		syncInterval = parsed
+	}
+
+	return &rtpStateConfig{syncInterval: syncInterval}, nil
+}
+
+func (c *rtpStateConfig) SyncInterval() time.Duration {
+	return c.syncInterval
+}
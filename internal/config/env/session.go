@@ -0,0 +1,39 @@
+package env
+
+import (
+	"casino_backend/internal/config"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	sessionCleanupIntervalEnvName = "SESSION_CLEANUP_INTERVAL"
+
+	// Период удаления истекших сессий по умолчанию
+	defaultSessionCleanupInterval = time.Hour
+)
+
+type sessionConfig struct {
+	cleanupInterval time.Duration
+}
+
+func NewSessionConfig() (config.SessionConfig, error) {
+	cleanupInterval := defaultSessionCleanupInterval
+	if raw := os.Getenv(sessionCleanupIntervalEnvName); len(raw) != 0 {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session cleanup interval: %w", err)
+		}
+		if parsed <= 0 {
+			return nil, fmt.Errorf("session cleanup interval must be positive, got %s", parsed)
+		}
+		cleanupInterval = parsed
+	}
+
+	return &sessionConfig{cleanupInterval: cleanupInterval}, nil
+}
+
+func (c *sessionConfig) CleanupInterval() time.Duration {
+	return c.cleanupInterval
+}
@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report Список проблем, найденных в файле конфигурации.
+// Собирает все ошибки сразу, чтобы их можно было исправить за один проход
+type Report struct {
+	Source   string   // Что проверяли (например, config-line.yaml)
+	Issues   []string // Найденные проблемы
+	Warnings []string // Замечания, с которыми конфиг всё же можно загрузить
+}
+
+// addf добавляет проблему, относящуюся ко всему файлу
+func (r *Report) addf(format string, args ...any) {
+	r.Issues = append(r.Issues, fmt.Sprintf(format, args...))
+}
+
+// addConfigf добавляет проблему, относящуюся к конфигу с индексом idx
+func (r *Report) addConfigf(idx int, name, format string, args ...any) {
+	r.Issues = append(r.Issues, fmt.Sprintf("config %d (%s): ", idx, name)+fmt.Sprintf(format, args...))
+}
+
+// warnConfigf добавляет замечание к конфигу с индексом idx
+func (r *Report) warnConfigf(idx int, name, format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf("config %d (%s): ", idx, name)+fmt.Sprintf(format, args...))
+}
+
+// Err возвращает отчёт как ошибку или nil, если проблем нет
+func (r *Report) Err() error {
+	if len(r.Issues) == 0 {
+		return nil
+	}
+	return r
+}
+
+func (r *Report) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is invalid (%d issues):", r.Source, len(r.Issues))
+	for _, issue := range r.Issues {
+		b.WriteString("\n  - ")
+		b.WriteString(issue)
+	}
+	return b.String()
+}
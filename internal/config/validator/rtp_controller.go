@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"casino_backend/internal/config"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+const (
+	RTPStrategyWindow = "window"
+	RTPStrategyPID    = "pid"
+	RTPStrategyEWMA   = "ewma"
+
+	// Допустимый диапазон целевого RTP, %
+	MinTargetRTP = 50
+	MaxTargetRTP = 200
+	// Допустимый размер окна регулятора, спинов
+	MinWindowSize = 10
+	MaxWindowSize = 100_000
+
+	// RTPPlayerNew Сегмент для игроков, сыгравших меньше new_player_rounds раундов в игре
+	RTPPlayerNew = "new"
+	// RTPPlayerReturning Сегмент для остальных игроков
+	RTPPlayerReturning = "returning"
+	// RTPDefaultSegment Сегмент, в который попадают спины, не подошедшие ни под один сегмент из конфига
+	RTPDefaultSegment = "default"
+)
+
+var (
+	// RTPStrategies Поддерживаемые стратегии регулятора RTP
+	RTPStrategies = []string{RTPStrategyWindow, RTPStrategyPID, RTPStrategyEWMA}
+	// RTPPlayers Допустимые значения player у сегмента (пусто — любые игроки)
+	RTPPlayers = []string{RTPPlayerNew, RTPPlayerReturning}
+
+	segmentNameRe = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+)
+
+// ValidateRTPController проверяет настройки регуляторов RTP всех игр из games
+func ValidateRTPController(source string, cfg config.RTPControllerConfig, games []string) error {
+	report := &Report{Source: source}
+
+	for _, game := range games {
+		s := cfg.Settings(game)
+		issue := func(format string, args ...any) {
+			report.addf("%s: %s", game, fmt.Sprintf(format, args...))
+		}
+
+		if s.Strategy == "" {
+			issue("missing controller settings")
+			continue
+		}
+		checkRTPSettings(issue, s)
+
+		if cfg.NewPlayerRounds(game) < 0 {
+			issue("new_player_rounds must not be negative, got %d", cfg.NewPlayerRounds(game))
+		}
+
+		names := make(map[string]int)
+		for i, seg := range cfg.Segments(game) {
+			segIssue := func(format string, args ...any) {
+				issue("segment %d (%s): %s", i, seg.Name, fmt.Sprintf(format, args...))
+			}
+
+			if !segmentNameRe.MatchString(seg.Name) {
+				segIssue("name must match %s", segmentNameRe)
+			}
+			if seg.Name == RTPDefaultSegment {
+				segIssue("name %q is reserved", RTPDefaultSegment)
+			}
+			if prev, ok := names[seg.Name]; ok {
+				segIssue("duplicate name, already used by segment %d", prev)
+			}
+			names[seg.Name] = i
+
+			if seg.Player != "" && !slices.Contains(RTPPlayers, seg.Player) {
+				segIssue("unknown player %q, expected one of %v", seg.Player, RTPPlayers)
+			}
+			if seg.Player != "" && cfg.NewPlayerRounds(game) == 0 {
+				segIssue("player filter requires new_player_rounds > 0")
+			}
+			if seg.MinBet < 0 {
+				segIssue("min_bet must not be negative, got %d", seg.MinBet)
+			}
+			if seg.MaxBet != 0 && seg.MaxBet < seg.MinBet {
+				segIssue("max_bet %d is less than min_bet %d", seg.MaxBet, seg.MinBet)
+			}
+			checkRTPSettings(segIssue, seg.Settings)
+		}
+	}
+
+	return report.Err()
+}
+
+// checkRTPSettings проверяет настройки одного регулятора
+func checkRTPSettings(issue func(format string, args ...any), s config.RTPControllerSettings) {
+	if !slices.Contains(RTPStrategies, s.Strategy) {
+		issue("unknown strategy %q, expected one of %v", s.Strategy, RTPStrategies)
+	}
+	if s.TargetRTP < MinTargetRTP || s.TargetRTP > MaxTargetRTP {
+		issue("target_rtp %v out of range [%d, %d]", s.TargetRTP, MinTargetRTP, MaxTargetRTP)
+	}
+	if s.WindowSize < MinWindowSize || s.WindowSize > MaxWindowSize {
+		issue("window_size %d out of range [%d, %d]", s.WindowSize, MinWindowSize, MaxWindowSize)
+	}
+	if s.MinSpins < 1 || s.MinSpins > s.WindowSize {
+		issue("min_spins %d out of range [1, window_size]", s.MinSpins)
+	}
+	if s.CheckEvery < 1 {
+		issue("check_every must be positive, got %d", s.CheckEvery)
+	}
+	if s.Tolerance <= 0 {
+		issue("tolerance must be positive, got %v", s.Tolerance)
+	}
+	if s.Critical < 0 || (s.Critical > 0 && s.Critical <= s.Tolerance) {
+		issue("critical must be 0 (disabled) or greater than tolerance, got %v", s.Critical)
+	}
+	if s.Strategy == RTPStrategyPID {
+		if s.Kp < 0 || s.Ki < 0 || s.Kd < 0 {
+			issue("pid coefficients must be non-negative")
+		}
+		if s.Kp == 0 && s.Ki == 0 && s.Kd == 0 {
+			issue("pid coefficients are all zero")
+		}
+	}
+}
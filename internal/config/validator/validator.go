@@ -0,0 +1,245 @@
+package validator
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/service/line"
+	"maps"
+	"slices"
+)
+
+var (
+	// LineSymbols Символы line-слота: S1-S8 обычные, W — вайлд, B — скаттер
+	LineSymbols = []string{"S1", "S2", "S3", "S4", "S5", "S6", "S7", "S8", "W", "B"}
+	// linePaySymbols Символы, для которых обязательна строка в таблице выплат
+	linePaySymbols = []string{"S1", "S2", "S3", "S4", "S5", "S6", "S7", "S8", "B"}
+
+	// CascadeSymbols Символы каскадного слота: 0-6 обычные, 7 — бонусный
+	CascadeSymbols = []int{0, 1, 2, 3, 4, 5, 6, 7}
+	// cascadePaySymbols Символы, которые собираются в кластеры и должны быть в таблице выплат
+	cascadePaySymbols = []int{0, 1, 2, 3, 4, 5, 6}
+)
+
+const (
+	// Барабанов в line-слоте: на барабане может быть не больше одного B
+	lineReels = 5
+	// Минимальная длина линии, которая может платить
+	lineMinCount = 2
+	// Размер поля каскадного слота
+	cascadeCells = 7 * 7
+	// Минимум скаттеров для фриспинов
+	minScatters = 3
+	// Допустимый итоговый RTP пресета line-слота, %: регулятору нужны пресеты по обе стороны от целевого RTP,
+	// но не настолько щедрые или скупые, чтобы одно переключение резко меняло выплаты
+	lineMinRTP = 80
+	lineMaxRTP = 125
+	// Минимальная разница RTP соседних пресетов, п.п.: иначе переключение индекса ничего не меняет
+	lineMinRTPStep = 0.1
+)
+
+// ValidateLine проверяет конфиги line-слота: набор символов, веса, таблицу выплат и фриспины.
+// expectedCount — сколько конфигов ждёт регулятор RTP.
+// warnings — замечания к математике пресетов (RTP вне диапазона, нарушенный порядок), которые не мешают загрузке
+func ValidateLine(source string, cfg config.LineConfig, expectedCount int) (warnings []string, err error) {
+	report := &Report{Source: source}
+
+	if cfg.Count() != expectedCount {
+		report.addf("expected %d configs, got %d", expectedCount, cfg.Count())
+	}
+
+	names := make(map[string]int, cfg.Count())
+	for i := 0; i < cfg.Count(); i++ {
+		name := cfg.Name(i)
+		checkName(report, names, i, name)
+
+		// Веса: ровно набор символов слота, без отрицательных, ненулевая сумма
+		weights := cfg.SymbolWeights(i)
+		total := 0
+		for _, sym := range LineSymbols {
+			w, ok := weights[sym]
+			if !ok {
+				report.addConfigf(i, name, "missing weight for symbol %s", sym)
+				continue
+			}
+			if w < 0 {
+				report.addConfigf(i, name, "negative weight %d for symbol %s", w, sym)
+			}
+			total += w
+		}
+		for _, sym := range slices.Sorted(maps.Keys(weights)) {
+			if !slices.Contains(LineSymbols, sym) {
+				report.addConfigf(i, name, "unknown symbol %s in weights", sym)
+			}
+		}
+		if total <= 0 {
+			report.addConfigf(i, name, "symbol weights sum to %d, must be positive", total)
+		}
+
+		if wc := cfg.WildChance(i); wc < 0 || wc > 1 {
+			report.addConfigf(i, name, "wild chance must be in [0, 1], got %v", wc)
+		}
+
+		// Таблица выплат: строка на каждый платящий символ, выплаты не убывают с длиной
+		payTable := cfg.PayoutTable(i)
+		for _, sym := range linePaySymbols {
+			pays, ok := payTable[sym]
+			if !ok || len(pays) == 0 {
+				report.addConfigf(i, name, "payout table has no pays for symbol %s", sym)
+				continue
+			}
+			checkCountPays(report, i, name, "payout for "+sym, pays, lineMinCount, lineReels)
+		}
+		for _, sym := range slices.Sorted(maps.Keys(payTable)) {
+			if !slices.Contains(linePaySymbols, sym) {
+				report.addConfigf(i, name, "unexpected symbol %s in payout table", sym)
+			}
+		}
+
+		// Фриспины: от 3 до 5 скаттеров (на барабане не больше одного B)
+		checkCountPays(report, i, name, "free spins", cfg.FreeSpinsByScatter(i), minScatters, lineReels)
+	}
+
+	// Математику считаем только по корректным конфигам: PAR sheet рассчитан на полный набор символов
+	if len(report.Issues) == 0 {
+		checkLineRTP(report, cfg)
+	}
+
+	return report.Warnings, report.Err()
+}
+
+// checkLineRTP проверяет точный RTP пресетов по PAR sheet: регулятор считает, что с ростом индекса
+// RTP строго убывает, поэтому пресеты должны быть упорядочены с шагом не меньше lineMinRTPStep
+// и лежать в [lineMinRTP, lineMaxRTP]. Нарушения порядка и диапазона — замечания: регулятор с ними работает,
+// но шаг индекса может не менять RTP или менять его не в ту сторону. Бесконечные фриспины — ошибка
+func checkLineRTP(report *Report, cfg config.LineConfig) {
+	engine := line.NewEngine(cfg)
+
+	prev := 0.0
+	for i := 0; i < engine.Count(); i++ {
+		sheet := engine.ParSheet(i)
+		rtp := sheet.TotalRTP * 100
+
+		switch {
+		case sheet.FreeSpinsUnbounded:
+			report.addConfigf(i, sheet.Preset, "free spin retriggers never end: %.3f free spins awarded per spin", sheet.FreeSpinsPerSpin)
+			continue
+		case rtp < lineMinRTP || rtp > lineMaxRTP:
+			report.warnConfigf(i, sheet.Preset, "RTP %.2f%% is outside [%d%%, %d%%]", rtp, lineMinRTP, lineMaxRTP)
+		}
+		if sheet.FreeSpinTrigger == 0 {
+			report.warnConfigf(i, sheet.Preset, "free spins can never trigger: scatter B has no weight")
+		}
+		if i > 0 && prev-rtp < lineMinRTPStep {
+			report.warnConfigf(i, sheet.Preset, "RTP %.2f%% is not lower than %.2f%% of config %d by at least %.1f",
+				rtp, prev, i-1, lineMinRTPStep)
+		}
+		prev = rtp
+	}
+}
+
+// ValidateCascade проверяет конфиги каскадного слота: набор символов, веса, выплаты и награды за бонусы.
+// expectedCount — сколько конфигов ждёт регулятор RTP
+func ValidateCascade(source string, cfg config.CascadeConfig, expectedCount int) error {
+	report := &Report{Source: source}
+
+	if cfg.Count() != expectedCount {
+		report.addf("expected %d configs, got %d", expectedCount, cfg.Count())
+	}
+
+	names := make(map[string]int, cfg.Count())
+	for i := 0; i < cfg.Count(); i++ {
+		name := cfg.Name(i)
+		checkName(report, names, i, name)
+
+		// Веса обычных символов
+		weights := cfg.SymbolWeights(i)
+		total := 0
+		for _, sym := range slices.Sorted(maps.Keys(weights)) {
+			w := weights[sym]
+			if !slices.Contains(CascadeSymbols, sym) {
+				report.addConfigf(i, name, "unknown symbol %d in weights", sym)
+				continue
+			}
+			if w < 0 {
+				report.addConfigf(i, name, "negative weight %d for symbol %d", w, sym)
+			}
+			total += w
+		}
+		for _, sym := range cascadePaySymbols {
+			if _, ok := weights[sym]; !ok {
+				report.addConfigf(i, name, "missing weight for symbol %d", sym)
+			}
+		}
+		if total <= 0 {
+			report.addConfigf(i, name, "symbol weights sum to %d, must be positive", total)
+		}
+
+		if p := cfg.BonusProbPerColumn(i); p < 0 || p >= 1 {
+			report.addConfigf(i, name, "bonus chance per cell must be in [0, 1), got %v", p)
+		}
+
+		// Выплаты: каждый кластерный символ, более редкий символ платит не меньше более частого
+		payTable := cfg.PayoutTable(i)
+		for _, sym := range cascadePaySymbols {
+			pay, ok := payTable[sym]
+			if !ok {
+				report.addConfigf(i, name, "pay table has no pay for symbol %d", sym)
+				continue
+			}
+			if pay < 0 {
+				report.addConfigf(i, name, "negative pay %d for symbol %d", pay, sym)
+			}
+		}
+		for _, sym := range slices.Sorted(maps.Keys(payTable)) {
+			if !slices.Contains(cascadePaySymbols, sym) {
+				report.addConfigf(i, name, "unexpected symbol %d in pay table", sym)
+			}
+		}
+		for _, a := range cascadePaySymbols {
+			for _, b := range cascadePaySymbols {
+				if weights[a] < weights[b] && payTable[a] < payTable[b] {
+					report.addConfigf(i, name, "symbol %d is rarer than %d (weight %d < %d) but pays less (%d < %d)",
+						a, b, weights[a], weights[b], payTable[a], payTable[b])
+				}
+			}
+		}
+
+		// Награды за бонусные символы: от 3 до размера поля
+		checkCountPays(report, i, name, "bonus awards", cfg.BonusAwards(i), minScatters, cascadeCells)
+	}
+
+	return report.Err()
+}
+
+// checkName проверяет, что имя конфига задано и не повторяется
+func checkName(report *Report, names map[string]int, idx int, name string) {
+	if name == "" {
+		report.addConfigf(idx, name, "name is empty")
+		return
+	}
+	if prev, ok := names[name]; ok {
+		report.addConfigf(idx, name, "duplicate name (same as config %d)", prev)
+		return
+	}
+	names[name] = idx
+}
+
+// checkCountPays проверяет таблицу "количество -> значение":
+// ключи в диапазоне [minCount, maxCount], значения неотрицательные и не убывают с ростом количества
+func checkCountPays(report *Report, idx int, name, what string, pays map[int]int, minCount, maxCount int) {
+	counts := slices.Sorted(maps.Keys(pays))
+	prev := -1
+	for _, count := range counts {
+		if count < minCount || count > maxCount {
+			report.addConfigf(idx, name, "%s: count %d out of range [%d, %d]", what, count, minCount, maxCount)
+			continue
+		}
+		v := pays[count]
+		if v < 0 {
+			report.addConfigf(idx, name, "%s: negative value %d for count %d", what, v, count)
+		}
+		if v < prev {
+			report.addConfigf(idx, name, "%s: value for count %d (%d) is less than for a shorter count (%d)", what, count, v, prev)
+		}
+		prev = v
+	}
+}
@@ -0,0 +1,425 @@
+package validator
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testLineConfig конфиг line-слота из пресетов
+type testLineConfig []testLinePreset
+
+type testLinePreset struct {
+	name      string
+	weights   map[string]int
+	wild      float64
+	freeSpins map[int]int
+	payout    map[string]map[int]int
+}
+
+var testLineWeights = map[string]int{"S1": 100, "S2": 100, "S3": 100, "S4": 100, "S5": 40, "S6": 25, "S7": 15, "S8": 8, "B": 12, "W": 0}
+
+var testLinePayout = map[string]map[int]int{
+	"S1": {3: 25, 4: 150, 5: 450},
+	"S2": {3: 25, 4: 150, 5: 450},
+	"S3": {3: 25, 4: 150, 5: 450},
+	"S4": {3: 25, 4: 150, 5: 450},
+	"S5": {3: 75, 4: 250, 5: 1000},
+	"S6": {3: 125, 4: 500, 5: 2500},
+	"S7": {3: 125, 4: 500, 5: 2500},
+	"S8": {2: 50, 3: 250, 4: 1250, 5: 12500},
+	"B":  {3: 100, 4: 500, 5: 2500},
+}
+
+var testLineFreeSpins = map[int]int{3: 10, 4: 15, 5: 20}
+
+func (c testLineConfig) Count() int                                 { return len(c) }
+func (c testLineConfig) Name(idx int) string                        { return c[idx].name }
+func (c testLineConfig) SymbolWeights(idx int) map[string]int       { return c[idx].weights }
+func (c testLineConfig) WildChance(idx int) float64                 { return c[idx].wild }
+func (c testLineConfig) FreeSpinsByScatter(idx int) map[int]int     { return c[idx].freeSpins }
+func (c testLineConfig) PayoutTable(idx int) map[string]map[int]int { return c[idx].payout }
+
+// preset корректный пресет line-слота с шансом вайлда wild
+func preset(name string, wild float64) testLinePreset {
+	return testLinePreset{
+		name:      name,
+		weights:   maps.Clone(testLineWeights),
+		wild:      wild,
+		freeSpins: maps.Clone(testLineFreeSpins),
+		payout:    maps.Clone(testLinePayout),
+	}
+}
+
+// with копия пресета, изменённая fn
+func (p testLinePreset) with(fn func(p *testLinePreset)) testLinePreset {
+	fn(&p)
+	return p
+}
+
+// testCascadeConfig конфиг каскадного слота из пресетов
+type testCascadeConfig []testCascadePreset
+
+type testCascadePreset struct {
+	name    string
+	weights map[int]int
+	bonus   float64
+	awards  map[int]int
+	payout  map[int]int
+}
+
+func (c testCascadeConfig) Count() int                         { return len(c) }
+func (c testCascadeConfig) Name(idx int) string                { return c[idx].name }
+func (c testCascadeConfig) SymbolWeights(idx int) map[int]int  { return c[idx].weights }
+func (c testCascadeConfig) BonusProbPerColumn(idx int) float64 { return c[idx].bonus }
+func (c testCascadeConfig) BonusAwards(idx int) map[int]int    { return c[idx].awards }
+func (c testCascadeConfig) PayoutTable(idx int) map[int]int    { return c[idx].payout }
+
+// cascadePreset корректный пресет каскадного слота, как в config-cascade.yaml
+func cascadePreset(name string) testCascadePreset {
+	return testCascadePreset{
+		name:    name,
+		weights: map[int]int{0: 8, 1: 9, 2: 10, 3: 12, 4: 14, 5: 16, 6: 17, 7: 1},
+		bonus:   0.015,
+		awards:  map[int]int{3: 10, 4: 12, 5: 15, 6: 20, 7: 30},
+		payout:  map[int]int{0: 10, 1: 8, 2: 6, 3: 5, 4: 4, 5: 3, 6: 2},
+	}
+}
+
+func (p testCascadePreset) with(fn func(p *testCascadePreset)) testCascadePreset {
+	fn(&p)
+	return p
+}
+
+// issues проблемы из ошибки валидации (nil — ошибки нет)
+func issues(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	report, ok := err.(*Report)
+	if !ok {
+		t.Fatalf("error %T is not a *Report: %v", err, err)
+	}
+	return report.Issues
+}
+
+func TestValidateLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      testLineConfig
+		expected int // 0 — по числу пресетов
+		want     []string
+	}{
+		{
+			name: "valid",
+			cfg:  testLineConfig{preset("ok", 0.08)},
+		},
+		{
+			name:     "expected count mismatch",
+			cfg:      testLineConfig{preset("ok", 0.08)},
+			expected: 20,
+			want:     []string{"expected 20 configs, got 1"},
+		},
+		{
+			name: "empty and duplicate names",
+			cfg:  testLineConfig{preset("", 0.08), preset("a", 0.08), preset("a", 0.08)},
+			want: []string{
+				"config 0 (): name is empty",
+				"config 2 (a): duplicate name (same as config 1)",
+			},
+		},
+		{
+			name: "missing symbol",
+			cfg:  testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) { delete(p.weights, "S3") })},
+			want: []string{"config 0 (bad): missing weight for symbol S3"},
+		},
+		{
+			name: "unknown symbol",
+			cfg:  testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) { p.weights["X"] = 5 })},
+			want: []string{"config 0 (bad): unknown symbol X in weights"},
+		},
+		{
+			name: "negative weight",
+			cfg:  testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) { p.weights["S1"] = -1 })},
+			want: []string{"config 0 (bad): negative weight -1 for symbol S1"},
+		},
+		{
+			name: "zero weight total",
+			cfg: testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) {
+				for sym := range p.weights {
+					p.weights[sym] = 0
+				}
+			})},
+			want: []string{"config 0 (bad): symbol weights sum to 0, must be positive"},
+		},
+		{
+			name: "wild chance out of range",
+			cfg:  testLineConfig{preset("bad", 1.5)},
+			want: []string{"config 0 (bad): wild chance must be in [0, 1], got 1.5"},
+		},
+		{
+			name: "paytable coverage",
+			cfg: testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) {
+				delete(p.payout, "S5")
+				p.payout["W"] = map[int]int{3: 10}
+			})},
+			want: []string{
+				"config 0 (bad): payout table has no pays for symbol S5",
+				"config 0 (bad): unexpected symbol W in payout table",
+			},
+		},
+		{
+			name: "non-monotonic pays",
+			cfg: testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) {
+				p.payout["S1"] = map[int]int{3: 25, 4: 10, 5: 450}
+			})},
+			want: []string{"config 0 (bad): payout for S1: value for count 4 (10) is less than for a shorter count (25)"},
+		},
+		{
+			name: "pay count out of range",
+			cfg: testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) {
+				p.payout["S1"] = map[int]int{1: 5, 3: 25, 6: 500}
+			})},
+			want: []string{
+				"config 0 (bad): payout for S1: count 1 out of range [2, 5]",
+				"config 0 (bad): payout for S1: count 6 out of range [2, 5]",
+			},
+		},
+		{
+			name: "free spins out of range and decreasing",
+			cfg: testLineConfig{preset("bad", 0.08).with(func(p *testLinePreset) {
+				p.freeSpins = map[int]int{2: 5, 3: 10, 4: 8}
+			})},
+			want: []string{
+				"config 0 (bad): free spins: count 2 out of range [3, 5]",
+				"config 0 (bad): free spins: value for count 4 (8) is less than for a shorter count (10)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.expected
+			if expected == 0 {
+				expected = len(tt.cfg)
+			}
+			_, err := ValidateLine("test", tt.cfg, expected)
+			if got := issues(t, err); !slices.Equal(got, tt.want) {
+				t.Fatalf("ValidateLine() issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestValidateLineReport все проблемы файла собираются в один отчёт
+func TestValidateLineReport(t *testing.T) {
+	cfg := testLineConfig{
+		preset("ok", 0.08),
+		preset("bad", 0.08).with(func(p *testLinePreset) {
+			delete(p.weights, "B")
+			p.payout["S8"] = map[int]int{2: 50, 3: 40}
+		}),
+	}
+
+	_, err := ValidateLine("config-line.yaml", cfg, 3)
+	want := `config-line.yaml is invalid (3 issues):
+  - expected 3 configs, got 2
+  - config 1 (bad): missing weight for symbol B
+  - config 1 (bad): payout for S8: value for count 3 (40) is less than for a shorter count (50)`
+	if err == nil || err.Error() != want {
+		t.Fatalf("ValidateLine() = %v, want:\n%s", err, want)
+	}
+}
+
+func TestValidateCascade(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      testCascadeConfig
+		expected int // 0 — по числу пресетов
+		want     []string
+	}{
+		{
+			name: "valid",
+			cfg:  testCascadeConfig{cascadePreset("ok")},
+		},
+		{
+			name:     "expected count mismatch",
+			cfg:      testCascadeConfig{cascadePreset("ok")},
+			expected: 20,
+			want:     []string{"expected 20 configs, got 1"},
+		},
+		{
+			name: "empty and duplicate names",
+			cfg:  testCascadeConfig{cascadePreset(""), cascadePreset("a"), cascadePreset("a")},
+			want: []string{
+				"config 0 (): name is empty",
+				"config 2 (a): duplicate name (same as config 1)",
+			},
+		},
+		{
+			name: "missing symbol",
+			cfg:  testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) { delete(p.weights, 0) })},
+			want: []string{"config 0 (bad): missing weight for symbol 0"},
+		},
+		{
+			name: "unknown symbol",
+			cfg:  testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) { p.weights[9] = 3 })},
+			want: []string{"config 0 (bad): unknown symbol 9 in weights"},
+		},
+		{
+			name: "negative weight",
+			cfg:  testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) { p.weights[7] = -1 })},
+			want: []string{"config 0 (bad): negative weight -1 for symbol 7"},
+		},
+		{
+			name: "zero weight total",
+			cfg: testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) {
+				for sym := range p.weights {
+					p.weights[sym] = 0
+				}
+			})},
+			want: []string{"config 0 (bad): symbol weights sum to 0, must be positive"},
+		},
+		{
+			name: "bonus chance out of range",
+			cfg:  testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) { p.bonus = 1 })},
+			want: []string{"config 0 (bad): bonus chance per cell must be in [0, 1), got 1"},
+		},
+		{
+			name: "pay table coverage",
+			cfg: testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) {
+				delete(p.payout, 6)
+				p.payout[7] = 50
+			})},
+			want: []string{
+				"config 0 (bad): pay table has no pay for symbol 6",
+				"config 0 (bad): unexpected symbol 7 in pay table",
+			},
+		},
+		{
+			name: "negative pay",
+			cfg:  testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) { p.payout[6] = -2 })},
+			want: []string{"config 0 (bad): negative pay -2 for symbol 6"},
+		},
+		{
+			name: "rarer symbol pays less",
+			cfg:  testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) { p.payout[1] = 20 })},
+			want: []string{"config 0 (bad): symbol 0 is rarer than 1 (weight 8 < 9) but pays less (10 < 20)"},
+		},
+		{
+			name: "bonus award keys out of range",
+			cfg: testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) {
+				p.awards = map[int]int{2: 5, 3: 10, 50: 100}
+			})},
+			want: []string{
+				"config 0 (bad): bonus awards: count 2 out of range [3, 49]",
+				"config 0 (bad): bonus awards: count 50 out of range [3, 49]",
+			},
+		},
+		{
+			name: "decreasing bonus awards",
+			cfg: testCascadeConfig{cascadePreset("bad").with(func(p *testCascadePreset) {
+				p.awards = map[int]int{3: 10, 4: 5}
+			})},
+			want: []string{"config 0 (bad): bonus awards: value for count 4 (5) is less than for a shorter count (10)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.expected
+			if expected == 0 {
+				expected = len(tt.cfg)
+			}
+			err := ValidateCascade("test", tt.cfg, expected)
+			if got := issues(t, err); !slices.Equal(got, tt.want) {
+				t.Fatalf("ValidateCascade() issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestValidateCascadeReport все проблемы файла собираются в один отчёт
+func TestValidateCascadeReport(t *testing.T) {
+	cfg := testCascadeConfig{
+		cascadePreset("bad").with(func(p *testCascadePreset) {
+			p.weights[8] = 1
+			p.bonus = -0.1
+		}),
+		cascadePreset("bad"),
+	}
+
+	err := ValidateCascade("config-cascade.yaml", cfg, 20)
+	want := `config-cascade.yaml is invalid (4 issues):
+  - expected 20 configs, got 2
+  - config 0 (bad): unknown symbol 8 in weights
+  - config 0 (bad): bonus chance per cell must be in [0, 1), got -0.1
+  - config 1 (bad): duplicate name (same as config 0)`
+	if err == nil || err.Error() != want {
+		t.Fatalf("ValidateCascade() = %v, want:\n%s", err, want)
+	}
+}
+
+func TestValidateLineRTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      testLineConfig
+		wantWarn string
+	}{
+		{
+			name: "decreasing within band",
+			cfg:  testLineConfig{preset("high", 0.1057), preset("low", 0.0624)},
+		},
+		{
+			name:     "not decreasing",
+			cfg:      testLineConfig{preset("low", 0.0624), preset("high", 0.1057)},
+			wantWarn: "config 1 (high): RTP 120.00% is not lower than 81.98% of config 0",
+		},
+		{
+			name:     "equal RTP",
+			cfg:      testLineConfig{preset("a", 0.08), preset("b", 0.08)},
+			wantWarn: "is not lower than",
+		},
+		{
+			name:     "above band",
+			cfg:      testLineConfig{preset("hot", 0.2)},
+			wantWarn: "config 0 (hot): RTP 241.51% is outside [80%, 125%]",
+		},
+		{
+			name:     "below band",
+			cfg:      testLineConfig{preset("cold", 0)},
+			wantWarn: "config 0 (cold): RTP 43.21% is outside [80%, 125%]",
+		},
+		{
+			name: "no scatter weight",
+			cfg: testLineConfig{
+				preset("high", 0.1057),
+				preset("no_scatter", 0.08).with(func(p *testLinePreset) { p.weights["B"] = 0 }),
+			},
+			wantWarn: "config 1 (no_scatter): free spins can never trigger",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Математика пресетов не мешает загрузке: только замечания
+			warnings, err := ValidateLine("test", tt.cfg, len(tt.cfg))
+			if err != nil {
+				t.Fatalf("ValidateLine: %v", err)
+			}
+			got := strings.Join(warnings, "\n")
+			if tt.wantWarn == "" {
+				if got != "" {
+					t.Fatalf("ValidateLine() warnings = %q, want none", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.wantWarn) {
+				t.Fatalf("ValidateLine() warnings = %q, want one containing %q", got, tt.wantWarn)
+			}
+		})
+	}
+}
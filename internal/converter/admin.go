@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"casino_backend/internal/api/dto/admin"
+	"casino_backend/internal/model"
+	"strconv"
+)
+
+func ToRTPStateResponse(st model.RTPControllerState) admin.RTPStateResponse {
+	adjustments := make([]admin.AdjustmentResponse, len(st.Adjustments))
+	for i, a := range st.Adjustments {
+		adjustments[i] = admin.AdjustmentResponse{
+			Timestamp:   a.Timestamp,
+			OldIndex:    a.OldIndex,
+			NewIndex:    a.NewIndex,
+			NewConfig:   a.NewConfig,
+			Reason:      a.Reason,
+			ObservedRTP: a.ObservedRTP,
+			Profit:      a.Profit,
+		}
+	}
+
+	return admin.RTPStateResponse{
+		Game:               string(st.Game),
+		Segment:            st.Segment,
+		Segments:           st.Segments,
+		Strategy:           st.Strategy,
+		ConfigIndex:        st.ConfigIndex,
+		ConfigName:         st.ConfigName,
+		ConfigCount:        st.ConfigCount,
+		TargetRTP:          st.TargetRTP,
+		ObservedRTP:        st.ObservedRTP,
+		TotalSpins:         st.TotalSpins,
+		TotalBet:           st.TotalBet,
+		TotalPayout:        st.TotalPayout,
+		TotalRTP:           st.TotalRTP,
+		WindowSize:         st.WindowSize,
+		WindowSpins:        st.WindowSpins,
+		EmergencyMode:      st.EmergencyMode,
+		EmergencyDirection: st.EmergencyDirection,
+		Paused:             st.Paused,
+		Adjustments:        adjustments,
+	}
+}
+
+func ToAuditLogResponse(page model.RTPAuditPage) admin.AuditLogResponse {
+	items := make([]admin.AuditEntryResponse, len(page.Items))
+	for i, e := range page.Items {
+		items[i] = admin.AuditEntryResponse{
+			ID:        e.ID,
+			Game:      string(e.Game),
+			Segment:   e.Segment,
+			Action:    string(e.Action),
+			OldValue:  e.OldValue,
+			NewValue:  e.NewValue,
+			Actor:     e.Actor,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+
+	var nextCursor string
+	if page.NextCursor > 0 {
+		nextCursor = strconv.FormatInt(page.NextCursor, 10)
+	}
+
+	return admin.AuditLogResponse{
+		Items:      items,
+		NextCursor: nextCursor,
+	}
+}
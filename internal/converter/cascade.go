@@ -14,6 +14,7 @@ func ToCascadeSpin(req cascade.CascadeSpinRequest) model.CascadeSpin {
 // Основной конвертер результата спина
 func ToCascadeSpinResponse(resp model.CascadeSpinResult) cascade.CascadeSpinResponse {
 	return cascade.CascadeSpinResponse{
+		RoundID:          resp.RoundID,
 		InitialBoard:     resp.InitialBoard,
 		Board:            resp.Board,
 		Cascades:         toCascadeSteps(resp.Cascades),
@@ -23,6 +24,7 @@ func ToCascadeSpinResponse(resp model.CascadeSpinResult) cascade.CascadeSpinResp
 		AwardedFreeSpins: resp.AwardedFreeSpins,
 		FreeSpinsLeft:    resp.FreeSpinsLeft,
 		InFreeSpin:       resp.InFreeSpin,
+		Fairness:         toProofResponse(resp.Fairness),
 	}
 }
 
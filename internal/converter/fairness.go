@@ -0,0 +1,50 @@
+package converter
+
+import (
+	"casino_backend/internal/api/dto/fairness"
+	"casino_backend/internal/model"
+)
+
+func ToSeedResponse(seed model.FairSeed) fairness.SeedResponse {
+	return fairness.SeedResponse{
+		ServerSeedHash: seed.ServerSeedHash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          seed.Nonce,
+		CreatedAt:      seed.CreatedAt,
+	}
+}
+
+func ToRotateResponse(rotation model.FairRotation) fairness.RotateResponse {
+	return fairness.RotateResponse{
+		Revealed: fairness.RevealedSeedResponse{
+			ServerSeed:     rotation.Revealed.ServerSeed,
+			ServerSeedHash: rotation.Revealed.ServerSeedHash,
+			ClientSeed:     rotation.Revealed.ClientSeed,
+			Nonce:          rotation.Revealed.Nonce,
+			CreatedAt:      rotation.Revealed.CreatedAt,
+		},
+		Next: ToSeedResponse(rotation.Next),
+	}
+}
+
+func ToFairVerify(req fairness.VerifyRequest) model.FairVerify {
+	return model.FairVerify{
+		Game:        model.Game(req.Game),
+		ServerSeed:  req.ServerSeed,
+		ClientSeed:  req.ClientSeed,
+		Nonce:       req.Nonce,
+		ConfigIndex: req.ConfigIndex,
+		BonusBuy:    req.BonusBuy,
+	}
+}
+
+func toProofResponse(proof *model.FairnessProof) *fairness.ProofResponse {
+	if proof == nil {
+		return nil
+	}
+	return &fairness.ProofResponse{
+		ServerSeedHash: proof.ServerSeedHash,
+		ClientSeed:     proof.ClientSeed,
+		Nonce:          proof.Nonce,
+	}
+}
@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"casino_backend/internal/api/dto/history"
+	"casino_backend/internal/model"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+func ToHistoryResponse(page model.GameRoundPage) history.HistoryResponse {
+	items := make([]history.RoundResponse, len(page.Items))
+	for i, round := range page.Items {
+		items[i] = toRoundResponse(round)
+	}
+
+	var nextCursor string
+	if page.NextCursor > 0 {
+		nextCursor = strconv.FormatInt(page.NextCursor, 10)
+	}
+
+	return history.HistoryResponse{
+		Items:      items,
+		NextCursor: nextCursor,
+	}
+}
+
+// ToRoundDetailResponse восстанавливает результат спина из истории в формате ответа игры
+func ToRoundDetailResponse(round model.GameRound) (history.RoundDetailResponse, error) {
+	var result any
+	switch {
+	case round.Game == model.GameLine && round.BonusBuy:
+		var res model.BonusSpinResult
+		if err := json.Unmarshal(round.Result, &res); err != nil {
+			return history.RoundDetailResponse{}, err
+		}
+		result = ToBonusSpinResponse(res)
+	case round.Game == model.GameLine:
+		var res model.SpinResult
+		if err := json.Unmarshal(round.Result, &res); err != nil {
+			return history.RoundDetailResponse{}, err
+		}
+		result = ToLineSpinResponse(res)
+	case round.Game == model.GameCascade:
+		var res model.CascadeSpinResult
+		if err := json.Unmarshal(round.Result, &res); err != nil {
+			return history.RoundDetailResponse{}, err
+		}
+		result = ToCascadeSpinResponse(res)
+	default:
+		return history.RoundDetailResponse{}, fmt.Errorf("unknown game %q", round.Game)
+	}
+
+	return history.RoundDetailResponse{
+		RoundResponse: toRoundResponse(round),
+		Result:        result,
+	}, nil
+}
+
+func toRoundResponse(round model.GameRound) history.RoundResponse {
+	return history.RoundResponse{
+		RoundID:     round.ID,
+		Game:        string(round.Game),
+		Bet:         round.Bet,
+		Payout:      round.Payout,
+		InFreeSpin:  round.InFreeSpin,
+		BonusBuy:    round.BonusBuy,
+		ConfigIndex: round.ConfigIndex,
+		ConfigName:  round.ConfigName,
+		CreatedAt:   round.CreatedAt,
+	}
+}
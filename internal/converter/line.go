@@ -13,13 +13,16 @@ func ToLineSpin(req line.LineSpinRequest) model.LineSpin {
 
 func ToLineSpinResponse(resp model.SpinResult) line.LineSpinResponse {
 	return line.LineSpinResponse{
+		RoundID:          resp.RoundID,
 		Board:            resp.Board,
 		LineWins:         toLineWins(resp.LineWins),
 		ScatterCount:     resp.ScatterCount,
+		ScatterPayout:    resp.ScatterPayout,
 		AwardedFreeSpins: resp.AwardedFreeSpins,
 		TotalPayout:      resp.TotalPayout,
 		Balance:          resp.Balance,
 		FreeSpinCount:    resp.FreeSpinCount,
+		Fairness:         toProofResponse(resp.Fairness),
 	}
 }
 
@@ -31,13 +34,16 @@ func ToBonusSpin(req line.BonusSpinRequest) model.BonusSpin {
 
 func ToBonusSpinResponse(resp model.BonusSpinResult) line.BonusSpinResponse {
 	return line.BonusSpinResponse{
+		RoundID:          resp.RoundID,
 		Board:            resp.Board,
 		LineWins:         toLineWins(resp.LineWins),
 		ScatterCount:     resp.ScatterCount,
+		ScatterPayout:    resp.ScatterPayout,
 		AwardedFreeSpins: resp.AwardedFreeSpins,
 		TotalPayout:      resp.TotalPayout,
 		Balance:          resp.Balance,
 		FreeSpinCount:    resp.FreeSpinCount,
+		Fairness:         toProofResponse(resp.Fairness),
 	}
 }
 
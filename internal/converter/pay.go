@@ -0,0 +1,37 @@
+package converter
+
+import (
+	"casino_backend/internal/api/dto/pay"
+	"casino_backend/internal/model"
+	"strconv"
+)
+
+func ToTransactionsResponse(page model.TransactionPage) pay.TransactionsResponse {
+	items := make([]pay.TransactionResponse, len(page.Items))
+	for i, t := range page.Items {
+		// Для игрока списание с его счёта — отрицательная сумма
+		amount := t.Amount
+		if t.DebitAccount == model.UserAccount(t.UserID) {
+			amount = -amount
+		}
+
+		items[i] = pay.TransactionResponse{
+			ID:           t.ID,
+			Type:         string(t.Type),
+			Amount:       amount,
+			BalanceAfter: t.BalanceAfter,
+			RoundID:      t.RoundID,
+			CreatedAt:    t.CreatedAt,
+		}
+	}
+
+	var nextCursor string
+	if page.NextCursor > 0 {
+		nextCursor = strconv.FormatInt(page.NextCursor, 10)
+	}
+
+	return pay.TransactionsResponse{
+		Items:      items,
+		NextCursor: nextCursor,
+	}
+}
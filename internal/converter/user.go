@@ -2,6 +2,7 @@ package converter
 
 import (
 	dto "casino_backend/internal/api/dto/auth"
+	"casino_backend/internal/api/dto/users"
 	"casino_backend/internal/model"
 )
 
@@ -19,3 +20,31 @@ func LoginRequestToUserModel(req *dto.LoginRequest) *model.User {
 		Password: req.Password,
 	}
 }
+
+// ToSessionsResponse помечает сессию currentID как текущую
+func ToSessionsResponse(sessions []model.Session, currentID string) []dto.SessionResponse {
+	items := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		items[i] = dto.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		}
+	}
+	return items
+}
+
+func ToUserResponse(user model.User) users.UserResponse {
+	return users.UserResponse{
+		ID:      user.ID,
+		Name:    user.Name,
+		Login:   user.Login,
+		Balance: user.Balance,
+		Role:    string(user.Role),
+	}
+}
@@ -13,6 +13,8 @@ type contextKey string
 const (
 	CtxUserIDKey    contextKey = "user_id"
 	CtxSessionIDKey contextKey = "session_id"
+	CtxRoleKey      contextKey = "role"
+	CtxTwoFactorKey contextKey = "two_factor"
 )
 
 type AuthMiddleware struct {
@@ -65,6 +67,8 @@ func (m *AuthMiddleware) Handle(next http.Handler) http.Handler {
 		// 4. Put data into context
 		ctx := context.WithValue(r.Context(), CtxUserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, CtxSessionIDKey, claims.SessionID)
+		ctx = context.WithValue(ctx, CtxRoleKey, claims.Role)
+		ctx = context.WithValue(ctx, CtxTwoFactorKey, claims.TwoFactor)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -74,3 +78,8 @@ func UserIDFromContext(ctx context.Context) (int, bool) {
 	id, ok := ctx.Value(CtxUserIDKey).(int)
 	return id, ok
 }
+
+func SessionIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(CtxSessionIDKey).(string)
+	return id, ok
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"bytes"
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader Заголовок с ключом идемпотентности
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader Заголовок, которым помечаются повторно отданные ответы
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// Максимальная длина ключа (ограничение колонки в БД)
+	maxIdempotencyKeyLength = 255
+)
+
+type IdempotencyMiddleware struct {
+	repo        repository.IdempotencyRepository
+	lockTimeout time.Duration
+}
+
+// NewIdempotencyMiddleware lockTimeout — через сколько резерв ключа без ответа считается брошенным
+// и повтор запроса выполняется заново
+func NewIdempotencyMiddleware(repo repository.IdempotencyRepository, lockTimeout time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		repo:        repo,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Handle сохраняет первый ответ на запрос с заголовком Idempotency-Key и отдаёт его на повторы.
+// Должен стоять после AuthMiddleware: ключи хранятся отдельно для каждого пользователя.
+// Запросы без заголовка обрабатываются как обычно.
+func (m *IdempotencyMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "idempotency key is too long", http.StatusBadRequest)
+			return
+		}
+
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "user not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		// 1. Читаем тело, чтобы посчитать хэш запроса, и возвращаем его обработчику
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hashRequest(r, body)
+
+		// 2. Резервируем ключ
+		existing, err := m.repo.ReserveKey(r.Context(), userID, key, requestHash, m.lockTimeout)
+		if err != nil {
+			log.Printf("idempotency: reserve key error: %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		// 3. Ключ уже использовался
+		if existing != nil {
+			switch {
+			case existing.RequestHash != requestHash:
+				http.Error(w, "idempotency key was used with a different request", http.StatusUnprocessableEntity)
+			case !existing.Completed:
+				http.Error(w, "request with this idempotency key is in progress", http.StatusConflict)
+			default:
+				replay(w, existing)
+			}
+			return
+		}
+
+		// 4. Первый запрос: выполняем и запоминаем ответ
+		ctx := context.WithoutCancel(r.Context())
+		rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		func() {
+			// Паника в обработчике тоже освобождает ключ, иначе повтор ждал бы lockTimeout
+			defer func() {
+				if p := recover(); p != nil {
+					if err := m.repo.DeleteKey(ctx, userID, key); err != nil {
+						log.Printf("idempotency: delete key error: %v", err)
+					}
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		}()
+
+		// Ошибка сервера не меняет состояние (транзакция откатилась) — освобождаем ключ для повтора
+		if rec.statusCode >= http.StatusInternalServerError {
+			if err := m.repo.DeleteKey(ctx, userID, key); err != nil {
+				log.Printf("idempotency: delete key error: %v", err)
+			}
+			return
+		}
+
+		err = m.repo.SaveResponse(ctx, &model.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			StatusCode:  rec.statusCode,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			// Резерв останется без ответа, и повтор после lockTimeout выполнится заново
+			log.Printf("idempotency: save response error: %v", err)
+		}
+	})
+}
+
+// replay отдаёт сохранённый ответ без изменений
+func replay(w http.ResponseWriter, rec *model.IdempotencyRecord) {
+	if rec.ContentType != "" {
+		w.Header().Set("Content-Type", rec.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(rec.StatusCode)
+	_, _ = w.Write(rec.Body)
+}
+
+// hashRequest считает хэш метода, пути и тела запроса
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder пишет ответ клиенту и одновременно запоминает статус и тело
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if !rr.wroteHeader {
+		rr.statusCode = statusCode
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/repository"
+	"casino_backend/pkg/req"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type RateLimitMiddleware struct {
+	repo repository.RateLimitRepository
+}
+
+func NewRateLimitMiddleware(repo repository.RateLimitRepository) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		repo: repo,
+	}
+}
+
+// Limit ограничивает частоту запросов к маршруту route по алгоритму token bucket.
+// Запросы считаются по пользователю, если до этого отработал AuthMiddleware, иначе по IP клиента.
+// При превышении лимита отвечает HTTP 429 с заголовком Retry-After.
+// Если хранилище недоступно, запрос пропускается: недоступность лимитера не должна останавливать сервис
+func (m *RateLimitMiddleware) Limit(route string, limit config.RateLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := route + ":ip:" + req.ClientIP(r)
+			if userID, ok := UserIDFromContext(r.Context()); ok {
+				key = route + ":user:" + strconv.Itoa(userID)
+			}
+
+			allowed, retryAfter, err := m.repo.Take(r.Context(), key, limit.Rate, limit.Burst)
+			if err != nil {
+				log.Printf("rate limit: take token error: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
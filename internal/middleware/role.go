@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"casino_backend/internal/model"
+	"context"
+	"net/http"
+	"slices"
+)
+
+// RequireRole пропускает только пользователей с одной из ролей roles.
+// Ставится после AuthMiddleware: роль берётся из access токена
+func RequireRole(roles ...model.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := RoleFromContext(r.Context())
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !slices.Contains(roles, role) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RoleFromContext возвращает роль пользователя, положенную AuthMiddleware
+func RoleFromContext(ctx context.Context) (model.Role, bool) {
+	role, ok := ctx.Value(CtxRoleKey).(model.Role)
+	return role, ok
+}
+
+// RequireTwoFactor пропускает только сессии, прошедшие двухфакторную аутентификацию.
+// Ставится после AuthMiddleware: признак берётся из access токена
+func RequireTwoFactor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		twoFactor, _ := r.Context().Value(CtxTwoFactorKey).(bool)
+		if !twoFactor {
+			http.Error(w, "two-factor authentication required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -31,15 +31,17 @@ type CascadeStep struct {
 
 // CascadeSpinResult представляет результат спина с каскадами
 type CascadeSpinResult struct {
-	InitialBoard     [7][7]int     // Доска сразу после начального заполнения, до любых каскадов
-	Board            [7][7]int     // Итоговая доска после всех каскадов
-	Cascades         []CascadeStep // Все шаги обновления доски
-	TotalPayout      int           // Выигрыш за весь спин в деньгах
-	Balance          int           // Баланс после спина в деньгах
-	ScatterCount     int           // Количество бонусов, выпавших за спин
-	AwardedFreeSpins int           // Количество начисленных фриспинов
-	FreeSpinsLeft    int           // Остаток фриспинов после спина
-	InFreeSpin       bool          // Находится ли игрок в режиме фриспинов
+	RoundID          string         // ID раунда
+	InitialBoard     [7][7]int      // Доска сразу после начального заполнения, до любых каскадов
+	Board            [7][7]int      // Итоговая доска после всех каскадов
+	Cascades         []CascadeStep  // Все шаги обновления доски
+	TotalPayout      int            // Выигрыш за весь спин в деньгах
+	Balance          int            // Баланс после спина в деньгах
+	ScatterCount     int            // Количество бонусов, выпавших за спин
+	AwardedFreeSpins int            // Количество начисленных фриспинов
+	FreeSpinsLeft    int            // Остаток фриспинов после спина
+	InFreeSpin       bool           // Находится ли игрок в режиме фриспинов
+	Fairness         *FairnessProof // Данные для проверки спина (только в provably-fair режиме)
 }
 
 // CascadeData содержит информацию о балансе и количестве фриспинов игрока
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// FairSeed Пара сидов игрока в provably-fair режиме.
+// Серверный сид держится в секрете, пока не будет заменён ротацией; игрок заранее видит только его хэш
+type FairSeed struct {
+	UserID         int
+	ServerSeed     string
+	ServerSeedHash string // hex(SHA-256(ServerSeed)) — публикуется до спинов
+	ClientSeed     string // Задаётся игроком
+	Nonce          int    // Сколько спинов сыграно на этой паре сидов
+	CreatedAt      time.Time
+}
+
+// FairnessProof Данные спина, по которым игрок проверит результат после раскрытия серверного сида
+type FairnessProof struct {
+	ServerSeedHash string
+	ClientSeed     string
+	Nonce          int
+}
+
+// FairRotation Результат ротации сидов
+type FairRotation struct {
+	Revealed FairSeed // Старая пара вместе с раскрытым серверным сидом
+	Next     FairSeed // Новая пара (серверный сид скрыт)
+}
+
+// FairVerify Запрос на воспроизведение доски по раскрытому серверному сиду
+type FairVerify struct {
+	Game        Game
+	ServerSeed  string
+	ClientSeed  string
+	Nonce       int
+	ConfigIndex int  // Индекс пресета / конфига RTP из истории раунда
+	BonusBuy    bool // Для line: спин купленной бонуски генерирует доску иначе
+}
@@ -0,0 +1,12 @@
+package model
+
+// IdempotencyRecord Сохранённый результат запроса с заголовком Idempotency-Key
+type IdempotencyRecord struct {
+	UserID      int
+	Key         string
+	RequestHash string // Хэш метода, пути и тела первого запроса
+	Completed   bool   // Ответ сохранён (false — первый запрос ещё обрабатывается)
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
@@ -5,14 +5,17 @@ type LineSpin struct {
 }
 
 type SpinResult struct {
+	RoundID          string
 	Board            [5][3]string
 	LineWins         []LineWin
 	ScatterCount     int
+	ScatterPayout    int // Выплата за B в любом месте поля
 	AwardedFreeSpins int
 	TotalPayout      int
 	Balance          int
 	FreeSpinCount    int
 	InFreeSpin       bool
+	Fairness         *FairnessProof // Только в provably-fair режиме
 }
 
 type LineWin struct {
@@ -32,11 +35,14 @@ type BonusSpin struct {
 }
 
 type BonusSpinResult struct {
+	RoundID          string
 	Board            [5][3]string
 	LineWins         []LineWin
 	ScatterCount     int
+	ScatterPayout    int // Выплата за B в любом месте поля
 	AwardedFreeSpins int
 	TotalPayout      int
 	Balance          int
 	FreeSpinCount    int
+	Fairness         *FairnessProof // Только в provably-fair режиме
 }
@@ -0,0 +1,17 @@
+package model
+
+// Ключи учёта неудачных попыток входа: по логину и по IP считаются отдельно
+const (
+	loginAttemptPrefix = "login:"
+	ipAttemptPrefix    = "ip:"
+)
+
+// LoginAttemptKey ключ попыток входа по логину
+func LoginAttemptKey(login string) string {
+	return loginAttemptPrefix + login
+}
+
+// IPAttemptKey ключ попыток входа с IP
+func IPAttemptKey(ip string) string {
+	return ipAttemptPrefix + ip
+}
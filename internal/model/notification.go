@@ -0,0 +1,9 @@
+package model
+
+// Notification Сообщение пользователю, доставляемое через service.Notifier
+type Notification struct {
+	UserID  int
+	Login   string
+	Subject string
+	Body    string
+}
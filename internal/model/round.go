@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Game Тип игры
+type Game string
+
+const (
+	GameLine    Game = "line"    // Слоты 5x3
+	GameCascade Game = "cascade" // Каскадные слоты 7x7
+)
+
+// GameRound Сохранённый игровой раунд
+type GameRound struct {
+	ID          string
+	Seq         int64 // Порядковый номер раунда (курсор пагинации)
+	UserID      int
+	Game        Game
+	Bet         int  // Сколько поставлено (для покупки бонуски — цена бонуски)
+	Payout      int  // Выигрыш за раунд
+	InFreeSpin  bool // Раунд сыгран фриспином
+	BonusBuy    bool // Раунд — спин купленной бонуски
+	ConfigIndex int  // Индекс пресета / конфига RTP
+	ConfigName  string
+	Result      []byte // Результат спина в JSON (SpinResult, BonusSpinResult или CascadeSpinResult)
+	CreatedAt   time.Time
+}
+
+// GameRoundPage Страница истории раундов
+type GameRoundPage struct {
+	Items      []GameRound
+	NextCursor int64 // Seq, с которого начинать следующую страницу (0 — страниц больше нет)
+}
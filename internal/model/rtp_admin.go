@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RTPAuditAction Действие администратора над регулятором RTP
+type RTPAuditAction string
+
+const (
+	RTPActionSetIndex      RTPAuditAction = "set_index"       // Закрепление пресета / конфига
+	RTPActionSetTargetRTP  RTPAuditAction = "set_target_rtp"  // Изменение целевого RTP
+	RTPActionSetWindowSize RTPAuditAction = "set_window_size" // Изменение размера окна
+	RTPActionSetPaused     RTPAuditAction = "set_paused"      // Пауза / возобновление автокорректировки
+)
+
+// RTPControllerState Состояние регулятора RTP игры
+type RTPControllerState struct {
+	Game     Game
+	Segment  string
+	Segments []string // Все сегменты игры
+	Strategy string   // window, pid или ewma
+
+	ConfigIndex int
+	ConfigName  string
+	ConfigCount int
+
+	TargetRTP   float64
+	ObservedRTP float64 // Оценка RTP, по которой решает стратегия
+
+	TotalSpins  int64
+	TotalBet    int64
+	TotalPayout int64
+	TotalRTP    float64
+
+	WindowSize  int
+	WindowSpins int // Сколько спинов сейчас в окне
+
+	EmergencyMode      bool
+	EmergencyDirection string
+
+	Paused      bool
+	Adjustments []RTPAdjustment
+}
+
+// RTPAdjustment Автоматическое переключение конфига регулятором
+type RTPAdjustment struct {
+	Timestamp   time.Time
+	OldIndex    int
+	NewIndex    int
+	NewConfig   string
+	Reason      string
+	ObservedRTP float64
+	Profit      int64
+}
+
+// RTPOverride Изменение настройки регулятора администратором
+type RTPOverride struct {
+	Game    Game
+	Segment string // Пусто — сегмент по умолчанию
+	Action  RTPAuditAction
+	Actor   string // Кто внёс изменение
+	Index   int
+	Target  float64
+	Window  int
+	Paused  bool
+}
+
+// RTPAuditEntry Запись журнала изменений регуляторов RTP
+type RTPAuditEntry struct {
+	ID        int64
+	Game      Game
+	Segment   string
+	Action    RTPAuditAction
+	OldValue  json.RawMessage
+	NewValue  json.RawMessage
+	Actor     string
+	CreatedAt time.Time
+}
+
+// RTPAuditPage Страница журнала изменений регуляторов RTP
+type RTPAuditPage struct {
+	Items      []RTPAuditEntry
+	NextCursor int64 // ID, с которого начинать следующую страницу (0 — страниц больше нет)
+}
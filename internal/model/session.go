@@ -7,10 +7,27 @@ type Session struct {
 	UserID       int
 	RefreshToken string
 	ExpiresAt    time.Time
+
+	Device     string // Название устройства, которое передал клиент при входе
+	IP         string // IP последнего входа или обновления токенов
+	UserAgent  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time // Время входа или последнего обновления токенов
+	TwoFactor  bool      // При входе пройдена двухфакторная аутентификация
+}
+
+// ClientInfo Данные клиента, с которого выполняется вход или обновление токенов
+type ClientInfo struct {
+	Device    string
+	IP        string
+	UserAgent string
 }
 
 type AuthData struct {
 	AccessToken  string
 	RefreshToken string
 	SessionID    string
+	// PreAuthToken выдаётся вместо сессии, если у пользователя включена двухфакторная аутентификация:
+	// вход завершается предъявлением его вместе с кодом
+	PreAuthToken string
 }
@@ -0,0 +1,15 @@
+package model
+
+// TOTP Секрет двухфакторной аутентификации пользователя
+type TOTP struct {
+	UserID   int
+	Secret   string
+	Enabled  bool  // Подтверждён кодом из приложения
+	LastStep int64 // Период последнего принятого кода
+}
+
+// TOTPEnrollment Данные для добавления аккаунта в приложение-аутентификатор
+type TOTPEnrollment struct {
+	Secret string // Для ручного ввода
+	URI    string // otpauth:// для QR-кода
+}
@@ -4,16 +4,43 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Role Роль пользователя, от неё зависит доступ к маршрутам
+type Role string
+
+const (
+	RolePlayer  Role = "player"  // Игрок (по умолчанию при регистрации)
+	RoleSupport Role = "support" // Поддержка: просмотр аккаунтов игроков
+	RoleAdmin   Role = "admin"   // Администратор: всё, включая регуляторы RTP и роли
+)
+
+// Valid проверяет, что роль из известных
+func (r Role) Valid() bool {
+	switch r {
+	case RolePlayer, RoleSupport, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
 	ID       int
 	Name     string
 	Login    string
 	Password string
 	Balance  uint
+	Role     Role
 }
 
 type UserClaims struct {
 	UserID    int    `json:"user_id"`
 	SessionID string `json:"session_id"`
+	Role      Role   `json:"role"`
+	TwoFactor bool   `json:"two_factor"` // Сессия прошла двухфакторную аутентификацию
+	jwt.RegisteredClaims
+}
+
+// PreAuthClaims Токен промежуточного шага входа: пароль проверен, код второго фактора — ещё нет
+type PreAuthClaims struct {
+	UserID int `json:"user_id"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// TransactionType Тип операции по кошельку
+type TransactionType string
+
+const (
+	TransactionBet        TransactionType = "bet"        // Списание ставки
+	TransactionWin        TransactionType = "win"        // Начисление выигрыша
+	TransactionDeposit    TransactionType = "deposit"    // Пополнение баланса
+	TransactionBonusBuy   TransactionType = "bonus_buy"  // Покупка бонуски
+	TransactionAdjustment TransactionType = "adjustment" // Ручная корректировка
+	TransactionWithdraw   TransactionType = "withdraw"   // Вывод средств
+)
+
+const (
+	// HouseAccount Счёт казино (ставки и выигрыши)
+	HouseAccount = "house"
+	// CashierAccount Счёт платёжного шлюза (пополнения)
+	CashierAccount = "cashier"
+)
+
+// UserAccount возвращает счёт кошелька игрока
+func UserAccount(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+// Transaction Запись в журнале кошелька
+type Transaction struct {
+	ID            int64
+	UserID        int
+	Type          TransactionType
+	DebitAccount  string // Счёт, с которого списаны деньги
+	CreditAccount string // Счёт, на который зачислены деньги
+	Amount        int    // Сумма операции (всегда положительная)
+	BalanceAfter  int    // Баланс игрока после операции
+	RoundID       string // ID игрового раунда (пусто, если операция не связана со спином)
+	CreatedAt     time.Time
+}
+
+// TransactionPage Страница журнала кошелька
+type TransactionPage struct {
+	Items      []Transaction
+	NextCursor int64 // ID, с которого начинать следующую страницу (0 — страниц больше нет)
+}
@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.Notifier = (*fileNotifier)(nil)
+
+// fileNotifier дописывает сообщения в файл по одному JSON на строку — для локальной разработки и тестов
+type fileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNotifier(path string) *fileNotifier {
+	return &fileNotifier{path: path}
+}
+
+type fileRecord struct {
+	Time    time.Time `json:"time"`
+	UserID  int       `json:"user_id"`
+	Login   string    `json:"login"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+func (n *fileNotifier) Notify(_ context.Context, msg model.Notification) error {
+	line, err := json.Marshal(fileRecord{
+		Time:    time.Now(),
+		UserID:  msg.UserID,
+		Login:   msg.Login,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,23 @@
+package notifier
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"context"
+	"log"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.Notifier = (*logNotifier)(nil)
+
+// logNotifier пишет сообщения в лог приложения — для локальной разработки
+type logNotifier struct{}
+
+func NewLogNotifier() *logNotifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(_ context.Context, msg model.Notification) error {
+	log.Printf("notification for user %d (%s): %s\n%s", msg.UserID, msg.Login, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,103 @@
+package pgtest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// baselineSchema users и sessions в том виде, в каком их создавала прежняя версия migrations.sql
+const baselineSchema = `
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    login VARCHAR(50) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE sessions (
+    session_id TEXT PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_hash TEXT NOT NULL,
+    expired_time TIMESTAMP NOT NULL
+);
+
+CREATE TABLE line_game_state (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    free_spins_count INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE sugar_rush_state (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    free_spins_count INT NOT NULL DEFAULT 0,
+    multipliers JSONB NOT NULL DEFAULT '[]'::jsonb,
+    hits JSONB NOT NULL DEFAULT '[]'::jsonb
+);`
+
+// TestMigrationsUpgradeBaseline migrations.sql обновляет базу прежней версии и может применяться повторно
+func TestMigrationsUpgradeBaseline(t *testing.T) {
+	pool := newSchema(t)
+	ctx := context.Background()
+
+	if _, err := pool.Exec(ctx, baselineSchema); err != nil {
+		t.Fatalf("create baseline schema: %v", err)
+	}
+	var userID int
+	err := pool.QueryRow(ctx,
+		"INSERT INTO users (name, login, password_hash, balance) VALUES ('Old', 'old_user', '-', 100) RETURNING id",
+	).Scan(&userID)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	expired := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = pool.Exec(ctx,
+		"INSERT INTO sessions (session_id, user_id, refresh_hash, expired_time) VALUES ('s1', $1, 'h', $2::timestamp)",
+		userID, expired.Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	// Повторное применение ничего не ломает и не сдвигает время
+	applyMigrations(t, pool)
+	applyMigrations(t, pool)
+
+	var (
+		role      string
+		device    string
+		twoFactor bool
+		gotExpiry time.Time
+		colType   string
+	)
+	err = pool.QueryRow(ctx, `
+		SELECT u.role, s.device, s.two_factor, s.expired_time
+		FROM sessions s JOIN users u ON u.id = s.user_id
+		WHERE s.session_id = 's1'`,
+	).Scan(&role, &device, &twoFactor, &gotExpiry)
+	if err != nil {
+		t.Fatalf("read upgraded rows: %v", err)
+	}
+	if role != "player" || device != "" || twoFactor {
+		t.Fatalf("upgraded row = role %q, device %q, two_factor %v; want defaults", role, device, twoFactor)
+	}
+	if !gotExpiry.Equal(expired) {
+		t.Fatalf("expired_time = %v, want %v", gotExpiry, expired)
+	}
+
+	err = pool.QueryRow(ctx, `
+		SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'sessions' AND column_name = 'expired_time'`,
+	).Scan(&colType)
+	if err != nil {
+		t.Fatalf("read column type: %v", err)
+	}
+	if colType != "timestamp with time zone" {
+		t.Fatalf("expired_time type = %q, want timestamp with time zone", colType)
+	}
+
+	// Ограничение на баланс добавлено и к старой таблице
+	if _, err = pool.Exec(ctx, "UPDATE users SET balance = -1 WHERE id = $1", userID); err == nil {
+		t.Fatal("negative balance accepted after upgrade")
+	}
+}
@@ -0,0 +1,119 @@
+// Package pgtest готовит БД для интеграционных тестов.
+// Тесты запускаются, только если задан TEST_PG_DSN; каждый тест получает свою схему с таблицами из migrations.sql,
+// которая удаляется по его завершении. Например:
+//
+//	TEST_PG_DSN="host=localhost port=5432 dbname=casino-db user=casino-user password=... sslmode=disable" go test ./...
+package pgtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const dsnEnvName = "TEST_PG_DSN"
+
+// New подключается к TEST_PG_DSN и создаёт схему из migrations.sql. Без TEST_PG_DSN тест пропускается
+func New(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	pool := newSchema(t)
+	applyMigrations(t, pool)
+	return pool
+}
+
+// newSchema подключается к TEST_PG_DSN с пустой схемой, которая удаляется по завершении теста
+func newSchema(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(dsnEnvName)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping Postgres integration test", dsnEnvName)
+	}
+
+	ctx := context.Background()
+	schema := "test_" + uuid.New().String()[:8]
+
+	admin, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect to test db: %v", err)
+	}
+	if _, err = admin.Exec(ctx, fmt.Sprintf("CREATE SCHEMA %q", schema)); err != nil {
+		admin.Close()
+		t.Fatalf("create schema: %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("parse %s: %v", dsnEnvName, err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("connect to test db: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+		if _, err := admin.Exec(context.Background(), fmt.Sprintf("DROP SCHEMA %q CASCADE", schema)); err != nil {
+			t.Logf("drop schema %s: %v", schema, err)
+		}
+		admin.Close()
+	})
+
+	return pool
+}
+
+// applyMigrations применяет migrations.sql
+func applyMigrations(t testing.TB, pool *pgxpool.Pool) {
+	t.Helper()
+
+	migrations, err := os.ReadFile(migrationsPath(t))
+	if err != nil {
+		t.Fatalf("read migrations: %v", err)
+	}
+	if _, err = pool.Exec(context.Background(), string(migrations)); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+}
+
+// CreateUser создаёт игрока с балансом balance и возвращает его ID
+func CreateUser(t testing.TB, pool *pgxpool.Pool, balance int) int {
+	t.Helper()
+
+	var id int
+	err := pool.QueryRow(context.Background(),
+		"INSERT INTO users (name, login, password_hash, balance) VALUES ($1, $2, $3, $4) RETURNING id",
+		"Test", "user_"+uuid.New().String()[:8], "-", balance,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return id
+}
+
+// migrationsPath ищет migrations.sql в корне модуля, поднимаясь от каталога теста
+func migrationsPath(t testing.TB) string {
+	t.Helper()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.Join(dir, "migrations.sql")
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatalf("migrations.sql not found")
+		}
+		dir = parent
+	}
+}
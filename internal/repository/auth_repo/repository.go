@@ -4,8 +4,11 @@ import (
 	"casino_backend/internal/model"
 	"casino_backend/internal/repository"
 	"context"
+	"errors"
 
 	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -15,25 +18,38 @@ const (
 	colUserID      = "user_id"
 	colRefreshHash = "refresh_hash"
 	colExpiredTime = "expired_time"
+	colDevice      = "device"
+	colIP          = "ip"
+	colUserAgent   = "user_agent"
+	colCreatedAt   = "created_at"
+	colLastUsedAt  = "last_used_at"
+	colTwoFactor   = "two_factor"
+
+	usedTable = "used_refresh_tokens"
 )
 
+// notExpired Условие на действующую сессию: истекшие не находятся, даже пока их не удалила фоновая очистка
+var notExpired = sq.Expr(colExpiredTime + " > now()")
+
 type repo struct {
-	dbc *pgxpool.Pool
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
 }
 
-func NewAuthRepository(dbc *pgxpool.Pool) repository.AuthRepository {
+func NewAuthRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.AuthRepository {
 	return &repo{
-		dbc: dbc,
+		dbc:    dbc,
+		getter: getter,
 	}
 }
 
 // CreateSession - создает сессию в БД
-// Принимает model.Session - (ID, UserID, RefreshToken, ExpiresAt)
+// Принимает model.Session - (ID, UserID, RefreshToken, ExpiresAt, Device, IP, UserAgent, TwoFactor)
 func (r *repo) CreateSession(ctx context.Context, session *model.Session) error {
 	// Формируем запрос
 	query := sq.Insert(table).
-		Columns(colSessionID, colUserID, colRefreshHash, colExpiredTime).
-		Values(session.ID, session.UserID, session.RefreshToken, session.ExpiresAt).
+		Columns(colSessionID, colUserID, colRefreshHash, colExpiredTime, colDevice, colIP, colUserAgent, colTwoFactor).
+		Values(session.ID, session.UserID, session.RefreshToken, session.ExpiresAt, session.Device, session.IP, session.UserAgent, session.TwoFactor).
 		PlaceholderFormat(sq.Dollar)
 
 	sqlStr, args, err := query.ToSql()
@@ -41,7 +57,7 @@ func (r *repo) CreateSession(ctx context.Context, session *model.Session) error
 		return err
 	}
 
-	_, err = r.dbc.Exec(ctx, sqlStr, args...)
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
@@ -49,13 +65,59 @@ func (r *repo) CreateSession(ctx context.Context, session *model.Session) error
 	return nil
 }
 
-// GetRefreshTokenBySessionID - получить refresh token по session ID из БД
-// Возвращает refresh token в виде строки
-func (r *repo) GetRefreshTokenBySessionID(ctx context.Context, sessionID string) (string, error) {
+// RotateRefreshToken - заменяет refresh токен сессии, если текущий совпадает с oldHash,
+// и запоминает старый как использованный. Обновляет IP и время последнего использования сессии.
+// Возвращает false, если сессии нет, она истекла или токен уже другой.
+// Должен вызываться внутри транзакции
+func (r *repo) RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash, ip string) (bool, error) {
 	// Формируем запрос
-	query := sq.Select(colRefreshHash).
-		From(table).
-		Where(sq.Eq{colSessionID: sessionID}).
+	query := sq.Update(table).
+		Set(colRefreshHash, newHash).
+		Set(colIP, ip).
+		Set(colLastUsedAt, sq.Expr("now()")).
+		Where(sq.Eq{colSessionID: sessionID, colRefreshHash: oldHash}).
+		Where(notExpired).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	// Формируем запрос
+	usedQuery := sq.Insert(usedTable).
+		Columns(colRefreshHash, colSessionID).
+		Values(oldHash, sessionID).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err = usedQuery.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetSessionIDByUsedRefreshToken - возвращает сессию, в которой refresh токен с хэшем refreshHash
+// уже был заменён при ротации. Если такого токена нет — repository.ErrNotFound
+func (r *repo) GetSessionIDByUsedRefreshToken(ctx context.Context, refreshHash string) (string, error) {
+	// Формируем запрос
+	query := sq.Select(colSessionID).
+		From(usedTable).
+		Where(sq.Eq{colRefreshHash: refreshHash}).
 		PlaceholderFormat(sq.Dollar)
 
 	sqlStr, args, err := query.ToSql()
@@ -63,22 +125,26 @@ func (r *repo) GetRefreshTokenBySessionID(ctx context.Context, sessionID string)
 		return "", err
 	}
 
-	var refreshHash string
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&refreshHash)
+	var sessionID string
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&sessionID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", repository.ErrNotFound
+		}
 		return "", err
 	}
 
-	return refreshHash, nil
+	return sessionID, nil
 }
 
 // GetUserIDBySessionID - получить ID пользователя по session ID из БД
-// возвращает ID пользователя
+// возвращает ID пользователя. Истекшая сессия не находится — repository.ErrNotFound
 func (r *repo) GetUserIDBySessionID(ctx context.Context, sessionID string) (int, error) {
 	// Формируем запрос
 	query := sq.Select(colUserID).
 		From(table).
 		Where(sq.Eq{colSessionID: sessionID}).
+		Where(notExpired).
 		PlaceholderFormat(sq.Dollar)
 
 	sqlStr, args, err := query.ToSql()
@@ -87,8 +153,11 @@ func (r *repo) GetUserIDBySessionID(ctx context.Context, sessionID string) (int,
 	}
 
 	var userID int
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&userID)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&userID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repository.ErrNotFound
+		}
 		return 0, err
 	}
 
@@ -108,7 +177,7 @@ func (r *repo) DeleteSession(ctx context.Context, sessionID string) error {
 		return err
 	}
 
-	_, err = r.dbc.Exec(ctx, sqlStr, args...)
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
@@ -116,13 +185,15 @@ func (r *repo) DeleteSession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
-// GetUserBySessionID - возвращает model пользователя (ID, Name, Login, Password, Balance) по session ID
+// GetUserBySessionID - возвращает model пользователя (ID, Name, Login, Password, Balance, Role) по session ID.
+// Истекшая сессия не находится — repository.ErrNotFound
 func (r *repo) GetUserBySessionID(ctx context.Context, sessionID string) (*model.User, error) {
 	// Формируем запрос
-	query := sq.Select("u.id", "u.name", "u.login", "u.password_hash", "u.balance").
+	query := sq.Select("u.id", "u.name", "u.login", "u.password_hash", "u.balance", "u.role").
 		From(table + " s").
 		Join("users u ON s." + colUserID + " = u.id").
 		Where(sq.Eq{"s." + colSessionID: sessionID}).
+		Where(sq.Expr("s." + colExpiredTime + " > now()")).
 		PlaceholderFormat(sq.Dollar)
 
 	sqlStr, args, err := query.ToSql()
@@ -132,11 +203,192 @@ func (r *repo) GetUserBySessionID(ctx context.Context, sessionID string) (*model
 
 	var user model.User
 	var balance int64
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&user.ID, &user.Name, &user.Login, &user.Password, &balance)
+	var role string
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&user.ID, &user.Name, &user.Login, &user.Password, &balance, &role)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
 		return nil, err
 	}
 
 	user.Balance = uint(balance)
+	user.Role = model.Role(role)
 	return &user, nil
 }
+
+// DeleteUserSessions - удаляет все сессии пользователя (например, после смены роли,
+// чтобы токены со старой ролью перестали действовать)
+func (r *repo) DeleteUserSessions(ctx context.Context, userID int) error {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Eq{colUserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListUserSessions - возвращает действующие сессии пользователя от последних использованных к давним
+// (без хэша refresh токена)
+func (r *repo) ListUserSessions(ctx context.Context, userID int) ([]model.Session, error) {
+	// Формируем запрос
+	query := sq.Select(colSessionID, colUserID, colExpiredTime, colDevice, colIP, colUserAgent, colCreatedAt, colLastUsedAt, colTwoFactor).
+		From(table).
+		Where(sq.Eq{colUserID: userID}).
+		Where(notExpired).
+		OrderBy(colLastUsedAt + " DESC").
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]model.Session, 0)
+	for rows.Next() {
+		var session model.Session
+		err = rows.Scan(&session.ID, &session.UserID, &session.ExpiresAt, &session.Device, &session.IP,
+			&session.UserAgent, &session.CreatedAt, &session.LastUsedAt, &session.TwoFactor)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// DeleteUserSession - удаляет сессию пользователя.
+// Если у пользователя нет такой сессии — repository.ErrNotFound
+func (r *repo) DeleteUserSession(ctx context.Context, userID int, sessionID string) error {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Eq{colSessionID: sessionID, colUserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredSessions - удаляет истекшие сессии вместе с их использованными refresh токенами.
+// Возвращает количество удалённых сессий
+func (r *repo) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Expr(colExpiredTime + " <= now()")).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetSession - возвращает действующую сессию (без хэша refresh токена).
+// Истекшая сессия не находится — repository.ErrNotFound
+func (r *repo) GetSession(ctx context.Context, sessionID string) (*model.Session, error) {
+	// Формируем запрос
+	query := sq.Select(colSessionID, colUserID, colExpiredTime, colDevice, colIP, colUserAgent, colCreatedAt, colLastUsedAt, colTwoFactor).
+		From(table).
+		Where(sq.Eq{colSessionID: sessionID}).
+		Where(notExpired).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var session model.Session
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&session.ID, &session.UserID,
+		&session.ExpiresAt, &session.Device, &session.IP, &session.UserAgent, &session.CreatedAt,
+		&session.LastUsedAt, &session.TwoFactor)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// SetSessionTwoFactor - отмечает, что сессия прошла двухфакторную аутентификацию
+func (r *repo) SetSessionTwoFactor(ctx context.Context, sessionID string) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colTwoFactor, true).
+		Where(sq.Eq{colSessionID: sessionID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteOtherUserSessions - удаляет все сессии пользователя, кроме keepSessionID
+func (r *repo) DeleteOtherUserSessions(ctx context.Context, userID int, keepSessionID string) error {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Eq{colUserID: userID}).
+		Where(sq.NotEq{colSessionID: keepSessionID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
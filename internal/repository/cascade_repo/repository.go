@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -41,12 +42,14 @@ var (
 )
 
 type repo struct {
-	dbc *pgxpool.Pool
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
 }
 
-func NewCascadeRepository(dbc *pgxpool.Pool) repository.CascadeRepository {
+func NewCascadeRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.CascadeRepository {
 	return &repo{
-		dbc: dbc,
+		dbc:    dbc,
+		getter: getter,
 	}
 }
 
@@ -64,7 +67,7 @@ func (r *repo) GetFreeSpinCount(ctx context.Context, id int) (int, error) {
 	}
 
 	var count int
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&count)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -84,7 +87,7 @@ func (r *repo) UpdateFreeSpinCount(ctx context.Context, id int, count int) error
 		return err
 	}
 
-	res, err := r.dbc.Exec(ctx, sqlStr, args...)
+	res, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
@@ -103,7 +106,7 @@ func (r *repo) UpdateFreeSpinCount(ctx context.Context, id int, count int) error
 			return err
 		}
 
-		_, err = r.dbc.Exec(ctx, sqlStr, args...)
+		_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 		if err != nil {
 			return err
 		}
@@ -125,7 +128,7 @@ func (r *repo) GetMultiplierState(ctx context.Context, id int) ([7][7]int, [7][7
 	}
 
 	var multJSON, hitsJSON []byte
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&multJSON, &hitsJSON)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&multJSON, &hitsJSON)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return defltMult, defltHits, nil
@@ -172,7 +175,7 @@ func (r *repo) SetMultiplierState(ctx context.Context, id int, multMtrx, hitsMtr
 		return err
 	}
 
-	res, err := r.dbc.Exec(ctx, sqlStr, args...)
+	res, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
@@ -190,7 +193,7 @@ func (r *repo) SetMultiplierState(ctx context.Context, id int, multMtrx, hitsMtr
 			return err
 		}
 
-		_, err = r.dbc.Exec(ctx, sqlStr, args...)
+		_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 		if err != nil {
 			return err
 		}
@@ -222,7 +225,7 @@ func (r *repo) ResetMultiplierState(ctx context.Context, id int) error {
 		return err
 	}
 
-	res, err := r.dbc.Exec(ctx, sqlStr, args...)
+	res, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
@@ -240,7 +243,7 @@ func (r *repo) ResetMultiplierState(ctx context.Context, id int) error {
 			return err
 		}
 
-		_, err = r.dbc.Exec(ctx, sqlStr, args...)
+		_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 		if err != nil {
 			return err
 		}
@@ -261,7 +264,7 @@ func (r *repo) CreateCascadeGameState(ctx context.Context, id int) error {
 		return err
 	}
 
-	_, err = r.dbc.Exec(ctx, sqlStr, args...)
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
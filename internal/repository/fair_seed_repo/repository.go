@@ -0,0 +1,173 @@
+package fair_seed_repo
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table             = "fair_seeds"
+	tableRevealed     = "fair_seeds_revealed"
+	colUserID         = "user_id"
+	colServerSeed     = "server_seed"
+	colServerSeedHash = "server_seed_hash"
+	colClientSeed     = "client_seed"
+	colNonce          = "nonce"
+	colCreatedAt      = "created_at"
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewFairSeedRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.FairSeedRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// GetSeed - возвращает активную пару сидов игрока.
+// Если пары ещё нет, возвращает repository.ErrNotFound
+func (r *repo) GetSeed(ctx context.Context, userID int) (*model.FairSeed, error) {
+	return r.getSeed(ctx, userID, "")
+}
+
+// GetSeedForUpdate - то же, что GetSeed, но с блокировкой строки до конца транзакции
+func (r *repo) GetSeedForUpdate(ctx context.Context, userID int) (*model.FairSeed, error) {
+	return r.getSeed(ctx, userID, "FOR UPDATE")
+}
+
+// CreateSeed - создаёт пару сидов игрока, если её ещё нет.
+// Если пара уже создана (например, параллельным запросом), ничего не делает
+func (r *repo) CreateSeed(ctx context.Context, seed *model.FairSeed) error {
+	// Формируем запрос
+	query := sq.Insert(table).
+		Columns(colUserID, colServerSeed, colServerSeedHash, colClientSeed, colNonce, colCreatedAt).
+		Values(seed.UserID, seed.ServerSeed, seed.ServerSeedHash, seed.ClientSeed, seed.Nonce, seed.CreatedAt).
+		Suffix("ON CONFLICT (" + colUserID + ") DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSeed - заменяет активную пару сидов игрока
+func (r *repo) UpdateSeed(ctx context.Context, seed *model.FairSeed) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colServerSeed, seed.ServerSeed).
+		Set(colServerSeedHash, seed.ServerSeedHash).
+		Set(colClientSeed, seed.ClientSeed).
+		Set(colNonce, seed.Nonce).
+		Set(colCreatedAt, seed.CreatedAt).
+		Where(sq.Eq{colUserID: seed.UserID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IncrementNonce - атомарно увеличивает nonce активной пары сидов.
+// Возвращает новое значение — nonce очередного спина
+func (r *repo) IncrementNonce(ctx context.Context, userID int) (int, error) {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colNonce, sq.Expr(colNonce+" + 1")).
+		Where(sq.Eq{colUserID: userID}).
+		Suffix("RETURNING " + colNonce).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var nonce int
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&nonce)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repository.ErrNotFound
+		}
+		return 0, err
+	}
+
+	return nonce, nil
+}
+
+// ArchiveSeed - сохраняет раскрытую пару сидов, чтобы игрок мог проверить сыгранные на ней спины
+func (r *repo) ArchiveSeed(ctx context.Context, seed *model.FairSeed) error {
+	// Формируем запрос
+	query := sq.Insert(tableRevealed).
+		Columns(colUserID, colServerSeed, colServerSeedHash, colClientSeed, colNonce, colCreatedAt).
+		Values(seed.UserID, seed.ServerSeed, seed.ServerSeedHash, seed.ClientSeed, seed.Nonce, seed.CreatedAt).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getSeed - чтение активной пары сидов с необязательным суффиксом запроса (например, FOR UPDATE)
+func (r *repo) getSeed(ctx context.Context, userID int, suffix string) (*model.FairSeed, error) {
+	// Формируем запрос
+	query := sq.Select(colUserID, colServerSeed, colServerSeedHash, colClientSeed, colNonce, colCreatedAt).
+		From(table).
+		Where(sq.Eq{colUserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	if suffix != "" {
+		query = query.Suffix(suffix)
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var seed model.FairSeed
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).
+		Scan(&seed.UserID, &seed.ServerSeed, &seed.ServerSeedHash, &seed.ClientSeed, &seed.Nonce, &seed.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &seed, nil
+}
@@ -0,0 +1,189 @@
+package idempotency_repo
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table           = "idempotency_keys"
+	colUserID       = "user_id"
+	colKey          = "idempotency_key"
+	colRequestHash  = "request_hash"
+	colStatusCode   = "status_code"
+	colContentType  = "content_type"
+	colResponseBody = "response_body"
+	colCreatedAt    = "created_at"
+	colLockedAt     = "locked_at"
+
+	// Сколько раз ReserveKey пробует зарезервировать ключ, который освобождают параллельно
+	maxReserveAttempts = 3
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewIdempotencyRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.IdempotencyRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// ReserveKey - резервирует ключ идемпотентности за пользователем.
+// Резерв того же запроса без ответа, взятый раньше чем lockTimeout назад, считается брошенным
+// (обработчик упал или не смог сохранить ответ) и перехватывается.
+// Если ключ уже занят, возвращает существующую запись, иначе nil
+func (r *repo) ReserveKey(ctx context.Context, userID int, key, requestHash string, lockTimeout time.Duration) (*model.IdempotencyRecord, error) {
+	// Формируем запрос на вставку, если ключа ещё нет, или на перехват брошенного резерва
+	query := sq.Insert(table).
+		Columns(colUserID, colKey, colRequestHash).
+		Values(userID, key, requestHash).
+		Suffix("ON CONFLICT ("+colUserID+", "+colKey+") DO UPDATE SET "+
+			colLockedAt+" = now() "+
+			"WHERE "+table+"."+colStatusCode+" IS NULL "+
+			"AND "+table+"."+colRequestHash+" = EXCLUDED."+colRequestHash+" "+
+			"AND "+table+"."+colLockedAt+" < now() - make_interval(secs => ?)", lockTimeout.Seconds()).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		res, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		// Ключ успешно зарезервирован
+		if res.RowsAffected() == 1 {
+			return nil, nil
+		}
+
+		rec, err := r.getRecord(ctx, userID, key)
+		if err == nil {
+			return rec, nil
+		}
+		// Ключ освободили между вставкой и чтением — пробуем зарезервировать ещё раз
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("idempotency key %q is being reserved and released concurrently", key)
+}
+
+// SaveResponse - сохраняет ответ на запрос с ключом идемпотентности
+func (r *repo) SaveResponse(ctx context.Context, rec *model.IdempotencyRecord) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colStatusCode, rec.StatusCode).
+		Set(colContentType, rec.ContentType).
+		Set(colResponseBody, rec.Body).
+		Where(sq.Eq{colUserID: rec.UserID, colKey: rec.Key}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteKey - освобождает ключ идемпотентности (например, если запрос завершился ошибкой сервера)
+func (r *repo) DeleteKey(ctx context.Context, userID int, key string) error {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Eq{colUserID: userID, colKey: key}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getRecord - получение записи по пользователю и ключу
+func (r *repo) getRecord(ctx context.Context, userID int, key string) (*model.IdempotencyRecord, error) {
+	// Формируем запрос
+	query := sq.Select(colRequestHash, colStatusCode, colContentType, colResponseBody).
+		From(table).
+		Where(sq.Eq{colUserID: userID, colKey: key}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		statusCode  *int
+		contentType *string
+	)
+	rec := &model.IdempotencyRecord{
+		UserID: userID,
+		Key:    key,
+	}
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).
+		Scan(&rec.RequestHash, &statusCode, &contentType, &rec.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != nil {
+		rec.Completed = true
+		rec.StatusCode = *statusCode
+	}
+	if contentType != nil {
+		rec.ContentType = *contentType
+	}
+
+	return rec, nil
+}
+
+// DeleteExpiredKeys - удаляет ключи старше ttl: и с сохранёнными ответами, и брошенные резервы.
+// Возвращает количество удалённых ключей
+func (r *repo) DeleteExpiredKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Expr(colCreatedAt+" < now() - make_interval(secs => ?)", ttl.Seconds())).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
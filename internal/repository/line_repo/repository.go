@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,12 +18,14 @@ const (
 )
 
 type repo struct {
-	dbc *pgxpool.Pool
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
 }
 
-func NewLineRepository(dbc *pgxpool.Pool) repository.LineRepository {
+func NewLineRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.LineRepository {
 	return &repo{
-		dbc: dbc,
+		dbc:    dbc,
+		getter: getter,
 	}
 }
 
@@ -41,7 +44,7 @@ func (r *repo) GetFreeSpinCount(ctx context.Context, id int) (int, error) {
 	}
 
 	var count int
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&count)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&count)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, nil
@@ -66,7 +69,7 @@ func (r *repo) UpdateFreeSpinCount(ctx context.Context, id int, count int) error
 		return err
 	}
 
-	res, err := r.dbc.Exec(ctx, sqlStr, args...)
+	res, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
@@ -85,7 +88,7 @@ func (r *repo) UpdateFreeSpinCount(ctx context.Context, id int, count int) error
 			return err
 		}
 
-		_, err = r.dbc.Exec(ctx, sqlStr, args...)
+		_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 		if err != nil {
 			return err
 		}
@@ -106,7 +109,7 @@ func (r *repo) CreateLineGameState(ctx context.Context, id int) error {
 		return err
 	}
 
-	_, err = r.dbc.Exec(ctx, sqlStr, args...)
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,148 @@
+package login_attempt_repo
+
+import (
+	"casino_backend/internal/repository"
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table            = "login_attempts"
+	colKey           = "key"
+	colFailures      = "failures"
+	colLastFailureAt = "last_failure_at"
+	colLockedUntil   = "locked_until"
+)
+
+// Время считается по часам БД, чтобы расхождение часов реплик не влияло на блокировки
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewLoginAttemptRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.LoginAttemptRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// LockRemaining - возвращает оставшееся время самой долгой блокировки из keys (0 — блокировки нет)
+func (r *repo) LockRemaining(ctx context.Context, keys ...string) (time.Duration, error) {
+	// Формируем запрос
+	query := sq.Select("COALESCE(EXTRACT(EPOCH FROM MAX(" + colLockedUntil + ") - now()), 0)::float8").
+		From(table).
+		Where(sq.Eq{colKey: keys}).
+		Where(sq.Expr(colLockedUntil + " > now()")).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var seconds float64
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RecordFailure - увеличивает счётчик неудачных попыток key и возвращает его.
+// Если последняя неудача была раньше, чем resetAfter назад, счётчик начинается с 1
+func (r *repo) RecordFailure(ctx context.Context, key string, resetAfter time.Duration) (int, error) {
+	// Формируем запрос
+	query := sq.Insert(table).
+		Columns(colKey, colFailures).
+		Values(key, 1).
+		Suffix("ON CONFLICT ("+colKey+") DO UPDATE SET "+
+			colFailures+" = CASE WHEN "+table+"."+colLastFailureAt+" < now() - make_interval(secs => ?) "+
+			"THEN 1 ELSE "+table+"."+colFailures+" + 1 END, "+
+			colLastFailureAt+" = now() "+
+			"RETURNING "+colFailures, resetAfter.Seconds()).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var failures int
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+// Lock - блокирует key на d от текущего момента, не сокращая уже действующую блокировку
+func (r *repo) Lock(ctx context.Context, key string, d time.Duration) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colLockedUntil, sq.Expr("GREATEST("+colLockedUntil+", now() + make_interval(secs => ?))", d.Seconds())).
+		Where(sq.Eq{colKey: key}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reset - удаляет счётчик и блокировку key
+func (r *repo) Reset(ctx context.Context, key string) error {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Eq{colKey: key}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteStale - удаляет счётчики без неудач дольше window и без действующей блокировки:
+// следующая неудача по такому ключу всё равно начала бы счёт заново
+func (r *repo) DeleteStale(ctx context.Context, window time.Duration) (int64, error) {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Expr(colLastFailureAt+" < now() - make_interval(secs => ?)", window.Seconds())).
+		Where(sq.Or{
+			sq.Eq{colLockedUntil: nil},
+			sq.Expr(colLockedUntil + " <= now()"),
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,98 @@
+package password_reset_repo
+
+import (
+	"casino_backend/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table        = "password_reset_tokens"
+	colTokenHash = "token_hash"
+	colUserID    = "user_id"
+	colExpiresAt = "expires_at"
+	colUsedAt    = "used_at"
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewPasswordResetRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.PasswordResetRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// CreateResetToken - удаляет прежние неиспользованные токены пользователя и сохраняет новый.
+// Срок действия считается по часам БД. Должен вызываться внутри транзакции
+func (r *repo) CreateResetToken(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error {
+	// Формируем запрос
+	deleteQuery := sq.Delete(table).
+		Where(sq.Eq{colUserID: userID, colUsedAt: nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := deleteQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	// Формируем запрос
+	insertQuery := sq.Insert(table).
+		Columns(colTokenHash, colUserID, colExpiresAt).
+		Values(tokenHash, userID, sq.Expr("now() + make_interval(secs => ?)", ttl.Seconds())).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err = insertQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConsumeResetToken - помечает действующий токен использованным и возвращает ID пользователя.
+// Условие в UPDATE не даёт использовать токен дважды даже при параллельных запросах
+func (r *repo) ConsumeResetToken(ctx context.Context, tokenHash string) (int, error) {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colUsedAt, sq.Expr("now()")).
+		Where(sq.Eq{colTokenHash: tokenHash, colUsedAt: nil}).
+		Where(sq.Expr(colExpiresAt + " > now()")).
+		Suffix("RETURNING " + colUserID).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repository.ErrNotFound
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,98 @@
+package rate_limit_repo
+
+import (
+	"casino_backend/internal/repository"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// pruneInterval Как часто из памяти удаляются корзины, успевшие пополниться до конца
+const pruneInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // Когда корзина пополнится до конца и её можно забыть
+}
+
+// memoryRepo корзины в памяти процесса: лимит действует на каждую реплику отдельно
+type memoryRepo struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+func NewMemoryRateLimitRepository() repository.RateLimitRepository {
+	return &memoryRepo{
+		buckets:   make(map[string]*bucket),
+		lastPrune: time.Now(),
+	}
+}
+
+// Take - забирает токен из корзины key; новая корзина создаётся полной
+func (r *memoryRepo) Take(_ context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune(now)
+
+	b, ok := r.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		r.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second)))
+
+	if allowed {
+		return true, 0, nil
+	}
+	return false, retryAfter(b.tokens, rate), nil
+}
+
+// DeleteIdleBuckets - удаляет корзины, не менявшиеся дольше idle
+func (r *memoryRepo) DeleteIdleBuckets(_ context.Context, idle time.Duration) (int64, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, b := range r.buckets {
+		if now.Sub(b.updated) > idle {
+			delete(r.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// prune удаляет полные корзины: новая корзина создаётся полной, так что они ничем не отличаются от отсутствующих
+func (r *memoryRepo) prune(now time.Time) {
+	if now.Sub(r.lastPrune) < pruneInterval {
+		return
+	}
+	r.lastPrune = now
+
+	for key, b := range r.buckets {
+		if !now.Before(b.full) {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// retryAfter через сколько в корзине с tokens токенами появится целый токен
+func retryAfter(tokens, rate float64) time.Duration {
+	return time.Duration((1 - tokens) / rate * float64(time.Second))
+}
@@ -0,0 +1,88 @@
+package rate_limit_repo
+
+import (
+	"casino_backend/internal/repository"
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table         = "rate_limit_buckets"
+	colKey        = "key"
+	colTokens     = "tokens"
+	colAllowed    = "allowed"
+	colUpdatedAt  = "updated_at"
+	refilledQuery = "LEAST(?::float8, b." + colTokens + " + EXTRACT(EPOCH FROM now() - b." + colUpdatedAt + ")::float8 * ?::float8)"
+)
+
+// repo корзины в Postgres: лимит общий для всех реплик.
+// Пополнение и списание выполняются одним запросом по часам БД
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewRateLimitRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.RateLimitRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// Take - забирает токен из корзины key; новая корзина создаётся полной
+func (r *repo) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	// Формируем запрос
+	query := sq.Insert(table+" AS b").
+		Columns(colKey, colTokens, colAllowed).
+		Values(key, burst-1, true).
+		Suffix("ON CONFLICT ("+colKey+") DO UPDATE SET "+
+			colTokens+" = CASE WHEN "+refilledQuery+" >= 1 THEN "+refilledQuery+" - 1 ELSE "+refilledQuery+" END, "+
+			colAllowed+" = "+refilledQuery+" >= 1, "+
+			colUpdatedAt+" = now() "+
+			"RETURNING "+colTokens+", "+colAllowed,
+			burst, rate, burst, rate, burst, rate, burst, rate).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return false, 0, err
+	}
+
+	var (
+		tokens  float64
+		allowed bool
+	)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&tokens, &allowed)
+	if err != nil {
+		return false, 0, err
+	}
+	if allowed {
+		return true, 0, nil
+	}
+
+	return false, retryAfter(tokens, rate), nil
+}
+
+// DeleteIdleBuckets - удаляет корзины, не менявшиеся дольше idle. Если idle не меньше времени полного пополнения,
+// такие корзины полные и ничем не отличаются от отсутствующих
+func (r *repo) DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error) {
+	// Формируем запрос
+	query := sq.Delete(table).
+		Where(sq.Expr(colUpdatedAt+" < now() - make_interval(secs => ?)", idle.Seconds())).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
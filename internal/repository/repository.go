@@ -2,8 +2,17 @@ package repository
 
 import (
 	"casino_backend/internal/model"
-	repoModel "casino_backend/internal/repository/line_state_repo/model"
+	rtpModel "casino_backend/internal/repository/rtp_controller_repo/model"
 	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotEnoughBalance возвращается при попытке списать больше, чем есть на балансе
+	ErrNotEnoughBalance = errors.New("not enough balance")
+	// ErrNotFound возвращается, если запрошенная запись не найдена
+	ErrNotFound = errors.New("not found")
 )
 
 type LineRepository interface {
@@ -25,27 +34,160 @@ type CascadeRepository interface {
 
 type AuthRepository interface {
 	CreateSession(ctx context.Context, session *model.Session) error
-	GetRefreshTokenBySessionID(ctx context.Context, sessionID string) (refreshToken string, err error)
+	RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash, ip string) (rotated bool, err error)
+	GetSessionIDByUsedRefreshToken(ctx context.Context, refreshHash string) (sessionID string, err error)
 	GetUserIDBySessionID(ctx context.Context, sessionID string) (userID int, err error)
 	DeleteSession(ctx context.Context, sessionID string) error
 	GetUserBySessionID(ctx context.Context, sessionID string) (*model.User, error)
+	DeleteUserSessions(ctx context.Context, userID int) error
+	ListUserSessions(ctx context.Context, userID int) ([]model.Session, error)
+	DeleteUserSession(ctx context.Context, userID int, sessionID string) error
+	DeleteExpiredSessions(ctx context.Context) (deleted int64, err error)
+	GetSession(ctx context.Context, sessionID string) (*model.Session, error)
+	SetSessionTwoFactor(ctx context.Context, sessionID string) error
+	DeleteOtherUserSessions(ctx context.Context, userID int, keepSessionID string) error
+}
+
+// PasswordResetRepository одноразовые токены сброса пароля (хранятся SHA-256 хэши)
+type PasswordResetRepository interface {
+	// CreateResetToken сохраняет токен, действующий ttl, и отзывает прежние неиспользованные токены пользователя
+	CreateResetToken(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error
+	// ConsumeResetToken помечает токен использованным и возвращает его пользователя.
+	// Неизвестный, истекший или уже использованный токен — repository.ErrNotFound
+	ConsumeResetToken(ctx context.Context, tokenHash string) (userID int, err error)
+}
+
+// TOTPRepository секреты двухфакторной аутентификации и коды восстановления
+type TOTPRepository interface {
+	GetTOTP(ctx context.Context, userID int) (*model.TOTP, error)
+	// SavePendingTOTP сохраняет новый неподтверждённый секрет; включённый секрет не перезаписывается
+	SavePendingTOTP(ctx context.Context, userID int, secret string) error
+	EnableTOTP(ctx context.Context, userID int, step int64) error
+	// UseTOTPStep запоминает период принятого кода; false, если код этого или более позднего периода уже принимался
+	UseTOTPStep(ctx context.Context, userID int, step int64) (used bool, err error)
+	DeleteTOTP(ctx context.Context, userID int) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	// UseRecoveryCode удаляет код восстановления; false, если такого кода нет
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (used bool, err error)
+}
+
+// LoginAttemptRepository учёт неудачных попыток входа и блокировок.
+// key — логин или IP с префиксом ('login:', 'ip:')
+type LoginAttemptRepository interface {
+	// LockRemaining возвращает, сколько ещё действует самая долгая блокировка из keys (0 — блокировки нет)
+	LockRemaining(ctx context.Context, keys ...string) (time.Duration, error)
+	// RecordFailure учитывает неудачную попытку и возвращает число неудач подряд.
+	// Если с прошлой неудачи прошло больше resetAfter, счёт начинается заново
+	RecordFailure(ctx context.Context, key string, resetAfter time.Duration) (failures int, err error)
+	// Lock блокирует key на d от текущего момента; более долгая блокировка не сокращается
+	Lock(ctx context.Context, key string, d time.Duration) error
+	// Reset сбрасывает счётчик и блокировку key
+	Reset(ctx context.Context, key string) error
+	// DeleteStale удаляет счётчики без неудач дольше window и без действующей блокировки, возвращает их количество
+	DeleteStale(ctx context.Context, window time.Duration) (int64, error)
+}
+
+// RateLimitRepository хранилище корзин token bucket для ограничения частоты запросов
+type RateLimitRepository interface {
+	// Take забирает токен из корзины key (вместимость burst, пополнение rate токенов в секунду).
+	// Если токена нет, allowed = false, а retryAfter — через сколько он появится
+	Take(ctx context.Context, key string, rate float64, burst int) (allowed bool, retryAfter time.Duration, err error)
+	// DeleteIdleBuckets удаляет корзины, не менявшиеся дольше idle, и возвращает их количество
+	DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error)
 }
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *model.User) (id int, err error)
 	GetUserByLogin(ctx context.Context, login string) (*model.User, error)
+	GetUserByID(ctx context.Context, id int) (*model.User, error)
+	// GetUserWithPasswordByID как GetUserByID, но вместе с хэшем пароля
+	GetUserWithPasswordByID(ctx context.Context, id int) (*model.User, error)
+	SetRole(ctx context.Context, id int, role model.Role) error
+	SetPassword(ctx context.Context, id int, passwordHash string) error
 
 	GetBalance(ctx context.Context, id int) (int, error)
+	GetBalanceForUpdate(ctx context.Context, id int) (int, error)
 	UpdateBalance(ctx context.Context, id int, amount int) error
+	Debit(ctx context.Context, id int, amount int) (balance int, err error)
+	Credit(ctx context.Context, id int, amount int) (balance int, err error)
 }
 
-type LineStatsRepository interface {
-	CasinoState() repoModel.CasinoState
-	UpdateState(bet, payout float64)
-	SmartAutoAdjust() bool
+type TransactionRepository interface {
+	CreateTransaction(ctx context.Context, t *model.Transaction) (id int64, err error)
+	ListTransactions(ctx context.Context, userID int, cursor int64, limit int) ([]model.Transaction, error)
+}
+
+type IdempotencyRepository interface {
+	// ReserveKey резервирует ключ; брошенный резерв того же запроса старше lockTimeout перехватывается
+	ReserveKey(ctx context.Context, userID int, key, requestHash string, lockTimeout time.Duration) (existing *model.IdempotencyRecord, err error)
+	SaveResponse(ctx context.Context, rec *model.IdempotencyRecord) error
+	DeleteKey(ctx context.Context, userID int, key string) error
+	// DeleteExpiredKeys удаляет ключи старше ttl и возвращает их количество
+	DeleteExpiredKeys(ctx context.Context, ttl time.Duration) (deleted int64, err error)
+}
+
+type RoundRepository interface {
+	CreateRound(ctx context.Context, round *model.GameRound) error
+	ListRounds(ctx context.Context, userID int, cursor int64, limit int) ([]model.GameRound, error)
+	GetRound(ctx context.Context, userID int, roundID string) (*model.GameRound, error)
+	// CountRounds количество раундов игрока в игре, но не больше limit
+	CountRounds(ctx context.Context, userID int, game model.Game, limit int) (int, error)
+}
+
+type FairSeedRepository interface {
+	GetSeed(ctx context.Context, userID int) (*model.FairSeed, error)
+	GetSeedForUpdate(ctx context.Context, userID int) (*model.FairSeed, error)
+	CreateSeed(ctx context.Context, seed *model.FairSeed) error
+	UpdateSeed(ctx context.Context, seed *model.FairSeed) error
+	IncrementNonce(ctx context.Context, userID int) (nonce int, err error)
+	ArchiveSeed(ctx context.Context, seed *model.FairSeed) error
+}
+
+// RTPControllerRepository регулятор RTP одной игры: выбирает индекс конфига по стратегии из настроек
+type RTPControllerRepository interface {
+	SyncableStats
+	Count() int
+	Name(idx int) string
+
+	Index() int
+	// Record учитывает спин: bet — фактически списанная ставка (0 для фриспина), payout — выигрыш
+	Record(bet, payout int)
+	State() rtpModel.State
+}
+
+// RTPSegmentsRepository регуляторы RTP одной игры по сегментам игроков (из настроек игры)
+type RTPSegmentsRepository interface {
+	// Select регулятор сегмента, в который попадает спин со ставкой bet; newPlayer — игрок ещё новичок
+	Select(bet int, newPlayer bool) RTPControllerRepository
+	Segment(name string) (RTPControllerRepository, bool)
+	// Segments имена сегментов, первым — сегмент по умолчанию
+	Segments() []string
+	// NewPlayerRounds сколько раундов игрок считается новичком; 0 — сегменты не зависят от этого
+	NewPlayerRounds() int
+}
+
+// SyncableStats регулятор RTP в памяти, состояние которого периодически сливается со снимком в БД.
+// Несколько реплик бэкенда делят один снимок: каждая добавляет в него свои изменения с прошлой синхронизации
+type SyncableStats interface {
+	// MergeSnapshot сливает изменения с прошлой синхронизации в сохранённый снимок (nil — снимка ещё нет).
+	// override — изменение администратора поверх слитого снимка (nil — без изменений).
+	// Возвращает новый снимок для записи в БД и commit, который нужно вызвать после успешной записи:
+	// он делает снимок текущим состоянием, сохраняя изменения, накопленные во время синхронизации.
+	// До commit локальное состояние не меняется, в том числе изменением override
+	MergeSnapshot(stored []byte, override RTPOverride) (snapshot []byte, commit func(), err error)
+}
+
+// RTPOverride изменение настроек регулятора администратором (индекс, пауза, целевой RTP, окно)
+type RTPOverride func(st *rtpModel.State)
+
+type RTPStateRepository interface {
+	EnsureState(ctx context.Context, game model.Game, segment string) error
+	GetStateForUpdate(ctx context.Context, game model.Game, segment string) ([]byte, error)
+	SaveState(ctx context.Context, game model.Game, segment string, state []byte) error
 }
 
-type CascadeStatsRepository interface {
-	GetConfigIndex() (int, error)
-	UpdateStats(totalPayout int, bet int) error
+type RTPAuditRepository interface {
+	CreateEntry(ctx context.Context, entry *model.RTPAuditEntry) error
+	ListEntries(ctx context.Context, game model.Game, cursor int64, limit int) ([]model.RTPAuditEntry, error)
 }
@@ -0,0 +1,175 @@
+package round_repo
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table          = "game_rounds"
+	colID          = "id"
+	colSeq         = "seq"
+	colUserID      = "user_id"
+	colGame        = "game"
+	colBet         = "bet"
+	colPayout      = "payout"
+	colInFreeSpin  = "in_free_spin"
+	colBonusBuy    = "bonus_buy"
+	colConfigIndex = "config_index"
+	colConfigName  = "config_name"
+	colResult      = "result"
+	colCreatedAt   = "created_at"
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewRoundRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.RoundRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// CreateRound - сохраняет сыгранный раунд
+func (r *repo) CreateRound(ctx context.Context, round *model.GameRound) error {
+	// Формируем запрос
+	query := sq.Insert(table).
+		Columns(colID, colUserID, colGame, colBet, colPayout, colInFreeSpin, colBonusBuy,
+			colConfigIndex, colConfigName, colResult).
+		Values(round.ID, round.UserID, string(round.Game), int64(round.Bet), int64(round.Payout), round.InFreeSpin,
+			round.BonusBuy, round.ConfigIndex, round.ConfigName, round.Result).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListRounds - возвращает раунды пользователя от новых к старым (без результата спина).
+// cursor - seq раунда, с которого (не включая) продолжать выдачу; 0 - с самого нового
+func (r *repo) ListRounds(ctx context.Context, userID int, cursor int64, limit int) ([]model.GameRound, error) {
+	// Формируем запрос
+	query := sq.Select(colID, colSeq, colUserID, colGame, colBet, colPayout, colInFreeSpin, colBonusBuy,
+		colConfigIndex, colConfigName, colCreatedAt).
+		From(table).
+		Where(sq.Eq{colUserID: userID}).
+		OrderBy(colSeq + " DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	if cursor > 0 {
+		query = query.Where(sq.Lt{colSeq: cursor})
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rounds := make([]model.GameRound, 0, limit)
+	for rows.Next() {
+		var (
+			round       model.GameRound
+			game        string
+			bet, payout int64
+		)
+		err = rows.Scan(&round.ID, &round.Seq, &round.UserID, &game, &bet, &payout, &round.InFreeSpin,
+			&round.BonusBuy, &round.ConfigIndex, &round.ConfigName, &round.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		round.Game = model.Game(game)
+		round.Bet = int(bet)
+		round.Payout = int(payout)
+		rounds = append(rounds, round)
+	}
+
+	return rounds, rows.Err()
+}
+
+// GetRound - возвращает раунд пользователя вместе с результатом спина.
+// Если раунда нет (или он принадлежит другому пользователю) — repository.ErrNotFound
+func (r *repo) GetRound(ctx context.Context, userID int, roundID string) (*model.GameRound, error) {
+	// Формируем запрос
+	query := sq.Select(colID, colSeq, colUserID, colGame, colBet, colPayout, colInFreeSpin, colBonusBuy,
+		colConfigIndex, colConfigName, colResult, colCreatedAt).
+		From(table).
+		Where(sq.Eq{colID: roundID, colUserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		round       model.GameRound
+		game        string
+		bet, payout int64
+	)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&round.ID, &round.Seq,
+		&round.UserID, &game, &bet, &payout, &round.InFreeSpin, &round.BonusBuy, &round.ConfigIndex,
+		&round.ConfigName, &round.Result, &round.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	round.Game = model.Game(game)
+	round.Bet = int(bet)
+	round.Payout = int(payout)
+	return &round, nil
+}
+
+// CountRounds - возвращает количество раундов пользователя в игре, но не больше limit.
+// Ограничение нужно, чтобы не пересчитывать всю историю давних игроков
+func (r *repo) CountRounds(ctx context.Context, userID int, game model.Game, limit int) (int, error) {
+	// Формируем запрос
+	rounds := sq.Select("1").
+		From(table).
+		Where(sq.Eq{colUserID: userID, colGame: string(game)}).
+		Limit(uint64(limit))
+
+	query := sq.Select("count(*)").
+		FromSelect(rounds, "r").
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,101 @@
+package rtp_audit_repo
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table        = "rtp_audit_log"
+	colID        = "id"
+	colGame      = "game"
+	colSegment   = "segment"
+	colAction    = "action"
+	colOldValue  = "old_value"
+	colNewValue  = "new_value"
+	colActor     = "actor"
+	colCreatedAt = "created_at"
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewRTPAuditRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.RTPAuditRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// CreateEntry - записывает изменение регулятора RTP в журнал
+func (r *repo) CreateEntry(ctx context.Context, entry *model.RTPAuditEntry) error {
+	// Формируем запрос
+	query := sq.Insert(table).
+		Columns(colGame, colSegment, colAction, colOldValue, colNewValue, colActor).
+		Values(string(entry.Game), entry.Segment, string(entry.Action), string(entry.OldValue), string(entry.NewValue), entry.Actor).
+		Suffix("RETURNING " + colID + ", " + colCreatedAt).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// ListEntries - возвращает записи журнала от новых к старым.
+// game — фильтр по игре (пустая строка — все игры), cursor — ID, после которого продолжать (0 — с начала)
+func (r *repo) ListEntries(ctx context.Context, game model.Game, cursor int64, limit int) ([]model.RTPAuditEntry, error) {
+	// Формируем запрос
+	query := sq.Select(colID, colGame, colSegment, colAction, colOldValue, colNewValue, colActor, colCreatedAt).
+		From(table).
+		OrderBy(colID + " DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	if game != "" {
+		query = query.Where(sq.Eq{colGame: string(game)})
+	}
+	if cursor > 0 {
+		query = query.Where(sq.Lt{colID: cursor})
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]model.RTPAuditEntry, 0, limit)
+	for rows.Next() {
+		var (
+			entry          model.RTPAuditEntry
+			game, action   string
+			oldVal, newVal []byte
+		)
+		err = rows.Scan(&entry.ID, &game, &entry.Segment, &action, &oldVal, &newVal, &entry.Actor, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entry.Game = model.Game(game)
+		entry.Action = model.RTPAuditAction(action)
+		entry.OldValue = oldVal
+		entry.NewValue = newVal
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// State Состояние регулятора RTP одной игры.
+// Сериализуется в JSON для снимка в БД (таблица rtp_controller_state)
+type State struct {
+	Strategy string `json:"strategy"` // Стратегия, которая вела регулятор при записи снимка
+
+	Index      int     `json:"index"`       // Индекс текущего конфига (0 — самый высокий RTP)
+	TargetRTP  float64 `json:"target_rtp"`  // Целевой RTP, %
+	WindowSize int     `json:"window_size"` // Размер окна, спинов
+	Paused     bool    `json:"paused"`      // Автокорректировка выключена администратором
+
+	TotalSpins  int64 `json:"total_spins"`  // Сколько всего спинов сделано
+	TotalBet    int64 `json:"total_bet"`    // Сумма всех ставок
+	TotalPayout int64 `json:"total_payout"` // Сумма всех выплат
+
+	Window       []Spin `json:"window"`        // Последние WindowSize спинов
+	WindowBet    int64  `json:"window_bet"`    // Сумма ставок в окне
+	WindowPayout int64  `json:"window_payout"` // Сумма выплат в окне
+
+	EWMABet    float64 `json:"ewma_bet"`    // Экспоненциальное среднее ставки
+	EWMAPayout float64 `json:"ewma_payout"` // Экспоненциальное среднее выплаты
+
+	ObservedRTP float64 `json:"observed_rtp"` // RTP, по которому принимает решения стратегия, %
+
+	// Внутреннее состояние стратегий
+	SinceCheck         int     `json:"since_check"`         // Спинов с последнего решения
+	EmergencyMode      bool    `json:"emergency_mode"`      // window: аварийный режим
+	EmergencyDirection string  `json:"emergency_direction"` // window: high или low
+	Integral           float64 `json:"integral"`            // pid: накопленная ошибка
+	PrevError          float64 `json:"prev_error"`          // pid: ошибка на прошлом решении
+
+	Adjustments []Adjustment `json:"adjustments"` // Лог автоматических переключений
+}
+
+// Spin Ставка и выплата спина в окне
+type Spin struct {
+	Bet    int64 `json:"bet"`
+	Payout int64 `json:"payout"`
+}
+
+// Adjustment Автоматическое переключение конфига
+type Adjustment struct {
+	Timestamp   time.Time `json:"timestamp"`
+	OldIndex    int       `json:"old_index"`
+	NewIndex    int       `json:"new_index"`
+	NewConfig   string    `json:"new_config"`
+	Reason      string    `json:"reason"`
+	ObservedRTP float64   `json:"observed_rtp"`
+	Profit      int64     `json:"profit"` // Прибыль казино (ставки минус выплаты) на момент переключения
+}
@@ -0,0 +1,186 @@
+package rtp_controller_repo
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/repository"
+	repoModel "casino_backend/internal/repository/rtp_controller_repo/model"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxAdjustments Сколько последних переключений хранить в логе (в памяти и в снимке)
+const maxAdjustments = 1000
+
+// Проверка соответствия интерфейсу
+var _ repository.RTPControllerRepository = (*controller)(nil)
+
+// ConfigNames Конфиги игры, между которыми переключается регулятор (LineConfig и CascadeConfig подходят)
+type ConfigNames interface {
+	Count() int
+	Name(idx int) string
+}
+
+// controller Регулятор RTP одной игры. Живёт в памяти, периодически сливается со снимком в БД
+type controller struct {
+	mtx      sync.RWMutex
+	game     string
+	configs  ConfigNames
+	settings config.RTPControllerSettings
+	strategy Strategy
+	state    repoModel.State
+
+	// Изменения с последней синхронизации с БД (см. MergeSnapshot)
+	pendingSpins       []repoModel.Spin
+	pendingAdjustments []repoModel.Adjustment
+}
+
+// NewRTPControllerRepository Конструктор регулятора игры game.
+// Старт со среднего конфига; целевой RTP и окно — из настроек
+func NewRTPControllerRepository(game string, configs ConfigNames, settings config.RTPControllerSettings) (repository.RTPControllerRepository, error) {
+	strategy, err := newStrategy(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &controller{
+		game:     game,
+		configs:  configs,
+		settings: settings,
+		strategy: strategy,
+		state: repoModel.State{
+			Strategy:    strategy.Name(),
+			Index:       configs.Count() / 2,
+			TargetRTP:   settings.TargetRTP,
+			WindowSize:  settings.WindowSize,
+			Window:      make([]repoModel.Spin, 0),
+			Adjustments: make([]repoModel.Adjustment, 0),
+		},
+	}, nil
+}
+
+// Count количество конфигов игры
+func (c *controller) Count() int {
+	return c.configs.Count()
+}
+
+// Name название конфига
+func (c *controller) Name(idx int) string {
+	return c.configs.Name(idx)
+}
+
+// Index индекс конфига для очередного спина
+func (c *controller) Index() int {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.state.Index
+}
+
+// State копия состояния регулятора
+func (c *controller) State() repoModel.State {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return cloneState(c.state)
+}
+
+// Record учитывает спин и, если пора, даёт стратегии переключить конфиг
+func (c *controller) Record(bet, payout int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	spin := repoModel.Spin{Bet: int64(bet), Payout: int64(payout)}
+	c.observe(&c.state, spin)
+	c.pendingSpins = append(c.pendingSpins, spin)
+
+	c.state.SinceCheck++
+	if c.state.SinceCheck < c.settings.CheckEvery {
+		return
+	}
+	c.state.SinceCheck = 0
+
+	// На паузе конфиг меняет только администратор
+	if c.state.Paused || len(c.state.Window) < c.settings.MinSpins {
+		return
+	}
+
+	step, reason := c.strategy.Decide(&c.state)
+	if step != 0 {
+		c.applyStep(step, reason)
+	}
+}
+
+// observe добавляет спин в общую статистику, окно и EWMA
+func (c *controller) observe(st *repoModel.State, spin repoModel.Spin) {
+	st.TotalSpins++
+	st.TotalBet += spin.Bet
+	st.TotalPayout += spin.Payout
+
+	st.Window = append(st.Window, spin)
+	st.WindowBet += spin.Bet
+	st.WindowPayout += spin.Payout
+	trimWindow(st)
+
+	// alpha = 2 / (N + 1): «центр масс» EWMA совпадает с окном из N спинов
+	alpha := 2 / float64(st.WindowSize+1)
+	if st.EWMABet == 0 && st.EWMAPayout == 0 {
+		st.EWMABet, st.EWMAPayout = float64(spin.Bet), float64(spin.Payout)
+	} else {
+		st.EWMABet += alpha * (float64(spin.Bet) - st.EWMABet)
+		st.EWMAPayout += alpha * (float64(spin.Payout) - st.EWMAPayout)
+	}
+
+	st.ObservedRTP = c.strategy.ObservedRTP(st)
+}
+
+// trimWindow обрезает окно до WindowSize спинов и пересчитывает суммы
+func trimWindow(st *repoModel.State) {
+	if len(st.Window) <= st.WindowSize {
+		return
+	}
+	for _, old := range st.Window[:len(st.Window)-st.WindowSize] {
+		st.WindowBet -= old.Bet
+		st.WindowPayout -= old.Payout
+	}
+	st.Window = st.Window[len(st.Window)-st.WindowSize:]
+}
+
+// applyStep сдвигает индекс в пределах конфигов игры и логирует переключение
+func (c *controller) applyStep(step int, reason string) {
+	newIndex := min(max(c.state.Index+step, 0), c.configs.Count()-1)
+	if newIndex == c.state.Index {
+		return
+	}
+
+	adjustment := repoModel.Adjustment{
+		Timestamp:   time.Now(),
+		OldIndex:    c.state.Index,
+		NewIndex:    newIndex,
+		NewConfig:   c.configs.Name(newIndex),
+		Reason:      reason,
+		ObservedRTP: c.state.ObservedRTP,
+		Profit:      c.state.TotalBet - c.state.TotalPayout,
+	}
+	log.Printf("[%s] %s: config %d -> %d (%s), observed rtp %.2f%%",
+		c.game, reason, c.state.Index, newIndex, adjustment.NewConfig, c.state.ObservedRTP)
+
+	c.state.Index = newIndex
+	c.state.Adjustments = appendAdjustments(c.state.Adjustments, adjustment)
+	c.pendingAdjustments = append(c.pendingAdjustments, adjustment)
+}
+
+// appendAdjustments Добавляет переключения в лог, оставляя последние maxAdjustments
+func appendAdjustments(dst []repoModel.Adjustment, adjustments ...repoModel.Adjustment) []repoModel.Adjustment {
+	dst = append(dst, adjustments...)
+	if len(dst) > maxAdjustments {
+		dst = slices.Clone(dst[len(dst)-maxAdjustments:])
+	}
+	return dst
+}
+
+// cloneState Копия состояния, не делящая слайсы с оригиналом
+func cloneState(st repoModel.State) repoModel.State {
+	st.Window = slices.Clone(st.Window)
+	st.Adjustments = slices.Clone(st.Adjustments)
+	return st
+}
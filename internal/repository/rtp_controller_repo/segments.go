@@ -0,0 +1,96 @@
+package rtp_controller_repo
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/config/validator"
+	"casino_backend/internal/repository"
+	"slices"
+)
+
+// Проверка соответствия интерфейсу
+var _ repository.RTPSegmentsRepository = (*segments)(nil)
+
+// segmentRule Условия сегмента и его регулятор
+type segmentRule struct {
+	cfg  config.RTPSegment
+	ctrl repository.RTPControllerRepository
+}
+
+// segments Регуляторы игры по сегментам. Каждый сегмент сходится к своему целевому RTP
+// и не зависит от спинов других сегментов
+type segments struct {
+	newPlayerRounds int
+	def             repository.RTPControllerRepository
+	rules           []segmentRule // В порядке из конфига: выигрывает первое совпадение
+	byName          map[string]repository.RTPControllerRepository
+	names           []string
+}
+
+// NewRTPSegmentsRepository Конструктор регуляторов игры game: сегмент по умолчанию и сегменты из настроек
+func NewRTPSegmentsRepository(game string, configs ConfigNames, cfg config.RTPControllerConfig) (repository.RTPSegmentsRepository, error) {
+	def, err := NewRTPControllerRepository(game, configs, cfg.Settings(game))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &segments{
+		newPlayerRounds: cfg.NewPlayerRounds(game),
+		def:             def,
+		byName:          map[string]repository.RTPControllerRepository{validator.RTPDefaultSegment: def},
+		names:           []string{validator.RTPDefaultSegment},
+	}
+	for _, seg := range cfg.Segments(game) {
+		ctrl, err := NewRTPControllerRepository(game+"/"+seg.Name, configs, seg.Settings)
+		if err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, segmentRule{cfg: seg, ctrl: ctrl})
+		s.byName[seg.Name] = ctrl
+		s.names = append(s.names, seg.Name)
+	}
+
+	return s, nil
+}
+
+// Select регулятор первого сегмента, под условия которого подходит спин, иначе — сегмент по умолчанию
+func (s *segments) Select(bet int, newPlayer bool) repository.RTPControllerRepository {
+	for _, rule := range s.rules {
+		if matches(rule.cfg, bet, newPlayer) {
+			return rule.ctrl
+		}
+	}
+	return s.def
+}
+
+func matches(seg config.RTPSegment, bet int, newPlayer bool) bool {
+	switch seg.Player {
+	case validator.RTPPlayerNew:
+		if !newPlayer {
+			return false
+		}
+	case validator.RTPPlayerReturning:
+		if newPlayer {
+			return false
+		}
+	}
+	if bet < seg.MinBet {
+		return false
+	}
+	return seg.MaxBet == 0 || bet <= seg.MaxBet
+}
+
+// Segment регулятор сегмента по имени
+func (s *segments) Segment(name string) (repository.RTPControllerRepository, bool) {
+	ctrl, ok := s.byName[name]
+	return ctrl, ok
+}
+
+// Segments имена сегментов, первым — сегмент по умолчанию
+func (s *segments) Segments() []string {
+	return slices.Clone(s.names)
+}
+
+// NewPlayerRounds сколько раундов игрок считается новичком
+func (s *segments) NewPlayerRounds() int {
+	return s.newPlayerRounds
+}
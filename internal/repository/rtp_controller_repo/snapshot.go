@@ -0,0 +1,100 @@
+package rtp_controller_repo
+
+import (
+	"casino_backend/internal/repository"
+	repoModel "casino_backend/internal/repository/rtp_controller_repo/model"
+	"encoding/json"
+	"slices"
+)
+
+// MergeSnapshot Сливает спины и переключения с прошлой синхронизации в снимок из БД.
+// Статистика, окно и EWMA складываются со спинами других реплик.
+// Индекс берётся из снимка, если эта реплика с прошлой синхронизации его не переключала
+// и конфиг не закреплён администратором; настройки — всегда из снимка, поверх них применяется override.
+// Внутреннее состояние стратегии (аварийный режим, интеграл ПИД) — всегда от этой реплики
+func (c *controller) MergeSnapshot(stored []byte, override repository.RTPOverride) ([]byte, func(), error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	spins, adjustments := len(c.pendingSpins), len(c.pendingAdjustments)
+
+	var merged repoModel.State
+	if stored == nil {
+		// Снимка ещё нет: локальное состояние уже содержит все изменения
+		merged = cloneState(c.state)
+	} else {
+		if err := json.Unmarshal(stored, &merged); err != nil {
+			return nil, nil, err
+		}
+		c.normalize(&merged)
+		for _, spin := range c.pendingSpins {
+			c.observe(&merged, spin)
+		}
+		merged.Adjustments = appendAdjustments(merged.Adjustments, c.pendingAdjustments...)
+		if adjustments > 0 && !merged.Paused {
+			merged.Index = c.state.Index
+		}
+		c.adoptStrategyState(&merged)
+	}
+	if override != nil {
+		override(&merged)
+		c.normalize(&merged)
+	}
+
+	snapshot, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commit := func() {
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+
+		// Изменения, пришедшие во время синхронизации, накатываем поверх снимка
+		st := cloneState(merged)
+		for _, spin := range c.pendingSpins[spins:] {
+			c.observe(&st, spin)
+		}
+		st.Adjustments = appendAdjustments(st.Adjustments, c.pendingAdjustments[adjustments:]...)
+		if len(c.pendingAdjustments) > adjustments && !st.Paused {
+			st.Index = c.state.Index
+		}
+		c.adoptStrategyState(&st)
+
+		c.state = st
+		c.pendingSpins = slices.Clone(c.pendingSpins[spins:])
+		c.pendingAdjustments = slices.Clone(c.pendingAdjustments[adjustments:])
+	}
+	return snapshot, commit, nil
+}
+
+// adoptStrategyState Переносит в st внутреннее состояние стратегии этой реплики
+func (c *controller) adoptStrategyState(st *repoModel.State) {
+	st.Strategy = c.strategy.Name()
+	st.SinceCheck = c.state.SinceCheck
+	st.EmergencyMode = c.state.EmergencyMode
+	st.EmergencyDirection = c.state.EmergencyDirection
+	st.Integral = c.state.Integral
+	st.PrevError = c.state.PrevError
+	st.ObservedRTP = c.strategy.ObservedRTP(st)
+}
+
+// normalize Приводит снимок к текущему конфигу: количество конфигов могло измениться с момента записи
+func (c *controller) normalize(st *repoModel.State) {
+	st.Index = min(max(st.Index, 0), c.configs.Count()-1)
+	if st.WindowSize <= 0 {
+		st.WindowSize = c.settings.WindowSize
+	}
+	if st.TargetRTP <= 0 {
+		st.TargetRTP = c.settings.TargetRTP
+	}
+
+	// Суммы окна пересчитываем по самим спинам
+	st.WindowBet, st.WindowPayout = 0, 0
+	for _, spin := range st.Window {
+		st.WindowBet += spin.Bet
+		st.WindowPayout += spin.Payout
+	}
+	trimWindow(st)
+	st.ObservedRTP = c.strategy.ObservedRTP(st)
+}
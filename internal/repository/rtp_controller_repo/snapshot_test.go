@@ -0,0 +1,89 @@
+package rtp_controller_repo
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/config/validator"
+	repoModel "casino_backend/internal/repository/rtp_controller_repo/model"
+	"encoding/json"
+	"testing"
+)
+
+func newTestController(t *testing.T) *controller {
+	t.Helper()
+
+	c, err := NewRTPControllerRepository("line", testConfigs(5), config.RTPControllerSettings{
+		Strategy:   validator.RTPStrategyWindow,
+		TargetRTP:  95,
+		WindowSize: 10,
+		MinSpins:   10,
+		CheckEvery: 1000,
+		Tolerance:  5,
+	})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	return c.(*controller)
+}
+
+// TestMergeSnapshotOverrideAppliedOnCommit изменение администратора попадает в снимок сразу,
+// а в регулятор — только после commit; без commit следующая синхронизация его не повторяет
+func TestMergeSnapshotOverrideAppliedOnCommit(t *testing.T) {
+	c := newTestController(t)
+	for i := 0; i < 10; i++ {
+		c.Record(100, 90)
+	}
+	stored, commit, err := c.MergeSnapshot(nil, nil)
+	if err != nil {
+		t.Fatalf("MergeSnapshot: %v", err)
+	}
+	commit()
+
+	pin := func(st *repoModel.State) {
+		st.Index = 4
+		st.Paused = true
+		st.WindowSize = 5
+	}
+
+	// Транзакция откатилась: commit не вызван
+	snapshot, _, err := c.MergeSnapshot(stored, pin)
+	if err != nil {
+		t.Fatalf("MergeSnapshot with override: %v", err)
+	}
+	var written repoModel.State
+	if err = json.Unmarshal(snapshot, &written); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if written.Index != 4 || !written.Paused || written.WindowSize != 5 || len(written.Window) != 5 {
+		t.Fatalf("snapshot = index %d, paused %v, window %d/%d; want override applied",
+			written.Index, written.Paused, len(written.Window), written.WindowSize)
+	}
+	if st := c.State(); st.Index != 2 || st.Paused || st.WindowSize != 10 || len(st.Window) != 10 {
+		t.Fatalf("state before commit = index %d, paused %v, window %d/%d; want unchanged",
+			st.Index, st.Paused, len(st.Window), st.WindowSize)
+	}
+
+	// Следующая синхронизация не несёт откатившееся изменение
+	snapshot, _, err = c.MergeSnapshot(stored, nil)
+	if err != nil {
+		t.Fatalf("MergeSnapshot: %v", err)
+	}
+	written = repoModel.State{}
+	if err = json.Unmarshal(snapshot, &written); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if written.Index != 2 || written.Paused || written.WindowSize != 10 {
+		t.Fatalf("next snapshot = index %d, paused %v, window %d; want stored settings",
+			written.Index, written.Paused, written.WindowSize)
+	}
+
+	// Транзакция закоммичена: регулятор получает изменение
+	_, commit, err = c.MergeSnapshot(stored, pin)
+	if err != nil {
+		t.Fatalf("MergeSnapshot with override: %v", err)
+	}
+	commit()
+	if st := c.State(); st.Index != 4 || !st.Paused || st.WindowSize != 5 || len(st.Window) != 5 {
+		t.Fatalf("state after commit = index %d, paused %v, window %d/%d; want override applied",
+			st.Index, st.Paused, len(st.Window), st.WindowSize)
+	}
+}
@@ -0,0 +1,142 @@
+package rtp_controller_repo
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/config/validator"
+	repoModel "casino_backend/internal/repository/rtp_controller_repo/model"
+	"fmt"
+	"math"
+)
+
+// Strategy Алгоритм, по которому регулятор выбирает конфиг.
+// Статистику (общую, окно и EWMA) ведёт регулятор, стратегия только читает её и принимает решение
+type Strategy interface {
+	Name() string
+	// ObservedRTP оценка текущего RTP, по которой стратегия принимает решения, %
+	ObservedRTP(st *repoModel.State) float64
+	// Decide вызывается раз в CheckEvery спинов. Возвращает шаг индекса:
+	// +1 — к конфигу с более низким RTP, -1 — к более высокому, 0 — не менять
+	Decide(st *repoModel.State) (step int, reason string)
+}
+
+// newStrategy создаёт стратегию по настройкам игры
+func newStrategy(s config.RTPControllerSettings) (Strategy, error) {
+	switch s.Strategy {
+	case validator.RTPStrategyWindow:
+		return &windowStrategy{tolerance: s.Tolerance, critical: s.Critical}, nil
+	case validator.RTPStrategyPID:
+		return &pidStrategy{tolerance: s.Tolerance, kp: s.Kp, ki: s.Ki, kd: s.Kd}, nil
+	case validator.RTPStrategyEWMA:
+		return &ewmaStrategy{tolerance: s.Tolerance}, nil
+	}
+	return nil, fmt.Errorf("unknown rtp controller strategy %q", s.Strategy)
+}
+
+// windowRTP RTP в окне, взвешенный по ставкам
+func windowRTP(st *repoModel.State) float64 {
+	if st.WindowBet <= 0 {
+		return 0
+	}
+	return float64(st.WindowPayout) / float64(st.WindowBet) * 100
+}
+
+// stepTowards шаг, который возвращает RTP к целевому: слишком высокий — к большему индексу
+func stepTowards(diff float64) int {
+	if diff > 0 {
+		return +1
+	}
+	return -1
+}
+
+// windowStrategy Порог по RTP скользящего окна с аварийным режимом
+type windowStrategy struct {
+	tolerance float64
+	critical  float64
+}
+
+func (w *windowStrategy) Name() string { return validator.RTPStrategyWindow }
+
+func (w *windowStrategy) ObservedRTP(st *repoModel.State) float64 { return windowRTP(st) }
+
+func (w *windowStrategy) Decide(st *repoModel.State) (int, string) {
+	diff := windowRTP(st) - st.TargetRTP
+	absDiff := math.Abs(diff)
+
+	// Экстренная ситуация: отклонение больше critical — шагаем на каждом решении
+	if w.critical > 0 && absDiff > w.critical {
+		st.EmergencyMode = true
+		if diff > 0 {
+			st.EmergencyDirection = "high"
+		} else {
+			st.EmergencyDirection = "low"
+		}
+		return stepTowards(diff), "Экстренная корректировка"
+	}
+	// Выходим из экстренного режима, когда RTP вернулся в допустимый коридор
+	if st.EmergencyMode {
+		if absDiff < w.tolerance {
+			st.EmergencyMode = false
+			st.EmergencyDirection = ""
+		}
+		return 0, ""
+	}
+
+	if absDiff > w.tolerance {
+		return stepTowards(diff), "Стандартная корректировка"
+	}
+	return 0, ""
+}
+
+// pidStrategy ПИД-регулятор по RTP окна.
+// Индекс дискретный, поэтому шаг делается, когда управляющее воздействие выходит за tolerance;
+// после шага интеграл сбрасывается, чтобы накопленная ошибка не толкала регулятор дальше
+type pidStrategy struct {
+	tolerance  float64
+	kp, ki, kd float64
+}
+
+// Ограничение интеграла в единицах tolerance (защита от насыщения)
+const pidIntegralLimit = 10
+
+func (p *pidStrategy) Name() string { return validator.RTPStrategyPID }
+
+func (p *pidStrategy) ObservedRTP(st *repoModel.State) float64 { return windowRTP(st) }
+
+func (p *pidStrategy) Decide(st *repoModel.State) (int, string) {
+	// Ошибка со знаком «RTP выше цели»: положительное воздействие — к более низкому RTP
+	e := windowRTP(st) - st.TargetRTP
+
+	limit := pidIntegralLimit * p.tolerance
+	st.Integral = min(max(st.Integral+e, -limit), limit)
+	derivative := e - st.PrevError
+	st.PrevError = e
+
+	u := p.kp*e + p.ki*st.Integral + p.kd*derivative
+	if math.Abs(u) <= p.tolerance {
+		return 0, ""
+	}
+	st.Integral = 0
+	return stepTowards(u), fmt.Sprintf("PID (u=%.2f)", u)
+}
+
+// ewmaStrategy Порог по экспоненциальному среднему ставок и выплат
+type ewmaStrategy struct {
+	tolerance float64
+}
+
+func (e *ewmaStrategy) Name() string { return validator.RTPStrategyEWMA }
+
+func (e *ewmaStrategy) ObservedRTP(st *repoModel.State) float64 {
+	if st.EWMABet <= 0 {
+		return 0
+	}
+	return st.EWMAPayout / st.EWMABet * 100
+}
+
+func (e *ewmaStrategy) Decide(st *repoModel.State) (int, string) {
+	diff := e.ObservedRTP(st) - st.TargetRTP
+	if math.Abs(diff) > e.tolerance {
+		return stepTowards(diff), "EWMA корректировка"
+	}
+	return 0, ""
+}
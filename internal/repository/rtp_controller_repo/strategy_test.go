@@ -0,0 +1,80 @@
+package rtp_controller_repo
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/config/validator"
+	"fmt"
+	"testing"
+)
+
+type testConfigs int
+
+func (n testConfigs) Count() int          { return int(n) }
+func (n testConfigs) Name(idx int) string { return fmt.Sprintf("config_%d", idx) }
+
+type testSpin struct{ bet, payout int }
+
+// Одни и те же исходы спинов (девять проигрышей и один выигрыш x2) при разных ставках.
+// По числу спинов RTP 20% — ниже цели; по суммам, когда выиграла крупная ставка, — 183%, выше цели
+var (
+	equalBets = []testSpin{
+		{100, 0}, {100, 0}, {100, 0}, {100, 0}, {100, 0},
+		{100, 0}, {100, 0}, {100, 0}, {100, 0}, {100, 200},
+	}
+	bigBetWins = []testSpin{
+		{10, 0}, {10, 0}, {10, 0}, {10, 0}, {10, 0},
+		{10, 0}, {10, 0}, {10, 0}, {10, 0}, {1000, 2000},
+	}
+)
+
+// TestStrategiesWeightByBet каждая стратегия решает по суммам ставок и выплат,
+// поэтому крупная выигравшая ставка разворачивает решение
+func TestStrategiesWeightByBet(t *testing.T) {
+	base := config.RTPControllerSettings{
+		TargetRTP:  95,
+		WindowSize: len(equalBets),
+		MinSpins:   len(equalBets),
+		CheckEvery: len(equalBets),
+		Tolerance:  5,
+		Kp:         0.5,
+		Ki:         0.01,
+		Kd:         0.1,
+	}
+	const start = 2 // Средний из пяти конфигов
+
+	tests := []struct {
+		strategy string
+		name     string
+		spins    []testSpin
+		want     int
+	}{
+		{strategy: validator.RTPStrategyWindow, name: "equal bets", spins: equalBets, want: start - 1},
+		{strategy: validator.RTPStrategyWindow, name: "big bet wins", spins: bigBetWins, want: start + 1},
+		{strategy: validator.RTPStrategyPID, name: "equal bets", spins: equalBets, want: start - 1},
+		{strategy: validator.RTPStrategyPID, name: "big bet wins", spins: bigBetWins, want: start + 1},
+		{strategy: validator.RTPStrategyEWMA, name: "equal bets", spins: equalBets, want: start - 1},
+		{strategy: validator.RTPStrategyEWMA, name: "big bet wins", spins: bigBetWins, want: start + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+tt.name, func(t *testing.T) {
+			settings := base
+			settings.Strategy = tt.strategy
+			c, err := NewRTPControllerRepository("line", testConfigs(5), settings)
+			if err != nil {
+				t.Fatalf("new controller: %v", err)
+			}
+			if c.Index() != start {
+				t.Fatalf("start index = %d, want %d", c.Index(), start)
+			}
+
+			for _, s := range tt.spins {
+				c.Record(s.bet, s.payout)
+			}
+
+			if got := c.Index(); got != tt.want {
+				t.Fatalf("index = %d, want %d (observed rtp %.2f%%)", got, tt.want, c.State().ObservedRTP)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package rtp_state_repo
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table        = "rtp_controller_state"
+	colGame      = "game"
+	colSegment   = "segment"
+	colState     = "state"
+	colUpdatedAt = "updated_at"
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewRTPStateRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.RTPStateRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// EnsureState - создаёт пустую строку состояния сегмента игры, если её ещё нет.
+// Нужна, чтобы GetStateForUpdate всегда было что блокировать, даже при первом запуске нескольких реплик
+func (r *repo) EnsureState(ctx context.Context, game model.Game, segment string) error {
+	// Формируем запрос
+	query := sq.Insert(table).
+		Columns(colGame, colSegment).
+		Values(game, segment).
+		Suffix("ON CONFLICT (" + colGame + ", " + colSegment + ") DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	return err
+}
+
+// GetStateForUpdate - возвращает снимок состояния регулятора сегмента игры с блокировкой строки до конца транзакции.
+// Если снимок ещё не записывался, возвращает nil.
+// Если строки нет, возвращает repository.ErrNotFound
+func (r *repo) GetStateForUpdate(ctx context.Context, game model.Game, segment string) ([]byte, error) {
+	// Формируем запрос
+	query := sq.Select(colState).
+		From(table).
+		Where(sq.Eq{colGame: game, colSegment: segment}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var state []byte
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&state)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// SaveState - записывает снимок состояния регулятора сегмента игры
+func (r *repo) SaveState(ctx context.Context, game model.Game, segment string, state []byte) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colState, string(state)).
+		Set(colUpdatedAt, sq.Expr("now()")).
+		Where(sq.Eq{colGame: game, colSegment: segment}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package totp_repo
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table        = "totp_credentials"
+	colUserID    = "user_id"
+	colSecret    = "secret"
+	colEnabled   = "enabled"
+	colLastStep  = "last_step"
+	colCreatedAt = "created_at"
+
+	recoveryTable = "totp_recovery_codes"
+	colCodeHash   = "code_hash"
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewTOTPRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.TOTPRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// GetTOTP - возвращает секрет пользователя. Если его нет — repository.ErrNotFound
+func (r *repo) GetTOTP(ctx context.Context, userID int) (*model.TOTP, error) {
+	// Формируем запрос
+	query := sq.Select(colUserID, colSecret, colEnabled, colLastStep).
+		From(table).
+		Where(sq.Eq{colUserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var totp model.TOTP
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&totp.UserID, &totp.Secret, &totp.Enabled, &totp.LastStep)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// SavePendingTOTP - сохраняет неподтверждённый секрет вместо прежнего неподтверждённого.
+// Если двухфакторная аутентификация уже включена, ничего не меняется
+func (r *repo) SavePendingTOTP(ctx context.Context, userID int, secret string) error {
+	// Формируем запрос
+	query := sq.Insert(table).
+		Columns(colUserID, colSecret).
+		Values(userID, secret).
+		Suffix("ON CONFLICT (" + colUserID + ") DO UPDATE SET " +
+			colSecret + " = EXCLUDED." + colSecret + ", " + colLastStep + " = 0, " + colCreatedAt + " = now() " +
+			"WHERE NOT " + table + "." + colEnabled).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EnableTOTP - включает двухфакторную аутентификацию; step — период кода, которым она подтверждена
+func (r *repo) EnableTOTP(ctx context.Context, userID int, step int64) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colEnabled, true).
+		Set(colLastStep, step).
+		Where(sq.Eq{colUserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// UseTOTPStep - запоминает период принятого кода, если он новее последнего принятого.
+// Условие в UPDATE не даёт двум параллельным запросам принять один и тот же код
+func (r *repo) UseTOTPStep(ctx context.Context, userID int, step int64) (bool, error) {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colLastStep, step).
+		Where(sq.Eq{colUserID: userID, colEnabled: true}).
+		Where(sq.Lt{colLastStep: step}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteTOTP - удаляет секрет и коды восстановления пользователя.
+// Должен вызываться внутри транзакции
+func (r *repo) DeleteTOTP(ctx context.Context, userID int) error {
+	for _, t := range []string{table, recoveryTable} {
+		// Формируем запрос
+		query := sq.Delete(t).
+			Where(sq.Eq{colUserID: userID}).
+			PlaceholderFormat(sq.Dollar)
+
+		sqlStr, args, err := query.ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes - заменяет коды восстановления пользователя новыми.
+// Должен вызываться внутри транзакции
+func (r *repo) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	// Формируем запрос
+	deleteQuery := sq.Delete(recoveryTable).
+		Where(sq.Eq{colUserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := deleteQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	// Формируем запрос
+	insertQuery := sq.Insert(recoveryTable).
+		Columns(colUserID, colCodeHash).
+		PlaceholderFormat(sq.Dollar)
+	for _, hash := range codeHashes {
+		insertQuery = insertQuery.Values(userID, hash)
+	}
+
+	sqlStr, args, err = insertQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UseRecoveryCode - удаляет использованный код восстановления
+func (r *repo) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	// Формируем запрос
+	query := sq.Delete(recoveryTable).
+		Where(sq.Eq{colUserID: userID, colCodeHash: codeHash}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
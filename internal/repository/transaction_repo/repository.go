@@ -0,0 +1,115 @@
+package transaction_repo
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	table            = "transactions"
+	colID            = "id"
+	colUserID        = "user_id"
+	colType          = "type"
+	colDebitAccount  = "debit_account"
+	colCreditAccount = "credit_account"
+	colAmount        = "amount"
+	colBalanceAfter  = "balance_after"
+	colRoundID       = "round_id"
+	colCreatedAt     = "created_at"
+)
+
+type repo struct {
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+}
+
+func NewTransactionRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.TransactionRepository {
+	return &repo{
+		dbc:    dbc,
+		getter: getter,
+	}
+}
+
+// CreateTransaction - добавляет запись в журнал кошелька.
+// Возвращает ID созданной записи
+func (r *repo) CreateTransaction(ctx context.Context, t *model.Transaction) (int64, error) {
+	// Пустой ID раунда храним как NULL
+	var roundID *string
+	if t.RoundID != "" {
+		roundID = &t.RoundID
+	}
+
+	// Формируем запрос
+	query := sq.Insert(table).
+		Columns(colUserID, colType, colDebitAccount, colCreditAccount, colAmount, colBalanceAfter, colRoundID).
+		Values(t.UserID, string(t.Type), t.DebitAccount, t.CreditAccount, int64(t.Amount), int64(t.BalanceAfter), roundID).
+		Suffix("RETURNING " + colID).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ListTransactions - возвращает записи журнала пользователя от новых к старым.
+// cursor - ID записи, с которой (не включая) продолжать выдачу; 0 - с самой новой
+func (r *repo) ListTransactions(ctx context.Context, userID int, cursor int64, limit int) ([]model.Transaction, error) {
+	// Формируем запрос
+	query := sq.Select(colID, colUserID, colType, colDebitAccount, colCreditAccount, colAmount, colBalanceAfter,
+		"COALESCE("+colRoundID+", '')", colCreatedAt).
+		From(table).
+		Where(sq.Eq{colUserID: userID}).
+		OrderBy(colID + " DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	if cursor > 0 {
+		query = query.Where(sq.Lt{colID: cursor})
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]model.Transaction, 0, limit)
+	for rows.Next() {
+		var (
+			t            model.Transaction
+			txType       string
+			amount       int64
+			balanceAfter int64
+		)
+		err = rows.Scan(&t.ID, &t.UserID, &txType, &t.DebitAccount, &t.CreditAccount, &amount, &balanceAfter,
+			&t.RoundID, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		t.Type = model.TransactionType(txType)
+		t.Amount = int(amount)
+		t.BalanceAfter = int(balanceAfter)
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
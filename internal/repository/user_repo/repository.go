@@ -8,6 +8,8 @@ import (
 	"errors"
 
 	sq "github.com/Masterminds/squirrel"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,15 +20,18 @@ const (
 	colLogin        = "login"
 	colPasswordHash = "password_hash"
 	colBalance      = "balance"
+	colRole         = "role"
 )
 
 type repo struct {
-	dbc *pgxpool.Pool
+	dbc    *pgxpool.Pool
+	getter *trmpgx.CtxGetter
 }
 
-func NewUserRepository(dbc *pgxpool.Pool) repository.UserRepository {
+func NewUserRepository(dbc *pgxpool.Pool, getter *trmpgx.CtxGetter) repository.UserRepository {
 	return &repo{
-		dbc: dbc,
+		dbc:    dbc,
+		getter: getter,
 	}
 }
 
@@ -35,8 +40,8 @@ func NewUserRepository(dbc *pgxpool.Pool) repository.UserRepository {
 func (r *repo) CreateUser(ctx context.Context, user *model.User) (int, error) {
 	// Формируем запрос
 	query := sq.Insert(table).
-		Columns(colName, colLogin, colPasswordHash, colBalance).
-		Values(user.Name, user.Login, user.Password, int64(user.Balance)).
+		Columns(colName, colLogin, colPasswordHash, colBalance, colRole).
+		Values(user.Name, user.Login, user.Password, int64(user.Balance), string(user.Role)).
 		Suffix("RETURNING " + colID).
 		PlaceholderFormat(sq.Dollar)
 
@@ -46,7 +51,7 @@ func (r *repo) CreateUser(ctx context.Context, user *model.User) (int, error) {
 	}
 
 	var id int
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&id)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -54,10 +59,11 @@ func (r *repo) CreateUser(ctx context.Context, user *model.User) (int, error) {
 	return id, nil
 }
 
-// GetUserByLogin - возвращает модель пользователя (ID, Name, Login, Password, Balance) по его логину
+// GetUserByLogin - возвращает модель пользователя (ID, Name, Login, Password, Balance, Role) по его логину.
+// Если пользователя нет — repository.ErrNotFound
 func (r *repo) GetUserByLogin(ctx context.Context, login string) (*model.User, error) {
 	// Формируем запрос
-	query := sq.Select(colID, colName, colLogin, colPasswordHash, colBalance).
+	query := sq.Select(colID, colName, colLogin, colPasswordHash, colBalance, colRole).
 		From(table).
 		Where(sq.Eq{colLogin: login}).
 		PlaceholderFormat(sq.Dollar)
@@ -69,18 +75,130 @@ func (r *repo) GetUserByLogin(ctx context.Context, login string) (*model.User, e
 
 	var user model.User
 	var balance int64
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&user.ID, &user.Name, &user.Login, &user.Password, &balance)
+	var role string
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&user.ID, &user.Name, &user.Login, &user.Password, &balance, &role)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, err
+			return nil, repository.ErrNotFound
 		}
 		return nil, err
 	}
 
 	user.Balance = uint(balance)
+	user.Role = model.Role(role)
 	return &user, nil
 }
 
+// GetUserByID - возвращает модель пользователя (ID, Name, Login, Balance, Role) по его ID, без хэша пароля.
+// Если пользователя нет — repository.ErrNotFound
+func (r *repo) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	// Формируем запрос
+	query := sq.Select(colID, colName, colLogin, colBalance, colRole).
+		From(table).
+		Where(sq.Eq{colID: id}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	var balance int64
+	var role string
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&user.ID, &user.Name, &user.Login, &balance, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	user.Balance = uint(balance)
+	user.Role = model.Role(role)
+	return &user, nil
+}
+
+// GetUserWithPasswordByID - возвращает модель пользователя (ID, Name, Login, Password, Balance, Role) по его ID.
+// Если пользователя нет — repository.ErrNotFound
+func (r *repo) GetUserWithPasswordByID(ctx context.Context, id int) (*model.User, error) {
+	// Формируем запрос
+	query := sq.Select(colID, colName, colLogin, colPasswordHash, colBalance, colRole).
+		From(table).
+		Where(sq.Eq{colID: id}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	var balance int64
+	var role string
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&user.ID, &user.Name, &user.Login, &user.Password, &balance, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	user.Balance = uint(balance)
+	user.Role = model.Role(role)
+	return &user, nil
+}
+
+// SetRole - меняет роль пользователя.
+// Если пользователя нет — repository.ErrNotFound
+func (r *repo) SetRole(ctx context.Context, id int, role model.Role) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colRole, string(role)).
+		Where(sq.Eq{colID: id}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetPassword - заменяет хэш пароля пользователя.
+// Если пользователя нет — repository.ErrNotFound
+func (r *repo) SetPassword(ctx context.Context, id int, passwordHash string) error {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colPasswordHash, passwordHash).
+		Where(sq.Eq{colID: id}).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
 // GetBalance - получение баланса пользователя по его ID
 // Возвращает баланс пользователя
 func (r *repo) GetBalance(ctx context.Context, id int) (int, error) {
@@ -96,7 +214,7 @@ func (r *repo) GetBalance(ctx context.Context, id int) (int, error) {
 	}
 
 	var balance int64
-	err = r.dbc.QueryRow(ctx, sqlStr, args...).Scan(&balance)
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&balance)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, nil
@@ -121,10 +239,88 @@ func (r *repo) UpdateBalance(ctx context.Context, id int, amount int) error {
 		return err
 	}
 
-	_, err = r.dbc.Exec(ctx, sqlStr, args...)
+	_, err = r.getter.DefaultTrOrDB(ctx, r.dbc).Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// GetBalanceForUpdate - получение баланса пользователя с блокировкой строки (SELECT ... FOR UPDATE).
+// Должен вызываться внутри транзакции: блокировка держится до её завершения,
+// поэтому параллельные операции одного пользователя выполняются последовательно
+func (r *repo) GetBalanceForUpdate(ctx context.Context, id int) (int, error) {
+	// Формируем запрос
+	query := sq.Select(colBalance).
+		From(table).
+		Where(sq.Eq{colID: id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var balance int64
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(balance), nil
+}
+
+// Debit - атомарно списывает сумму с баланса пользователя.
+// Списание происходит только если баланса хватает, иначе возвращается repository.ErrNotEnoughBalance.
+// Возвращает баланс после списания
+func (r *repo) Debit(ctx context.Context, id int, amount int) (int, error) {
+	// Формируем запрос: проверка неотрицательного баланса выполняется в самом UPDATE
+	query := sq.Update(table).
+		Set(colBalance, sq.Expr(colBalance+" - ?", int64(amount))).
+		Where(sq.Eq{colID: id}).
+		Where(sq.GtOrEq{colBalance: int64(amount)}).
+		Suffix("RETURNING " + colBalance).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var balance int64
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repository.ErrNotEnoughBalance
+		}
+		return 0, err
+	}
+
+	return int(balance), nil
+}
+
+// Credit - атомарно начисляет сумму на баланс пользователя.
+// Возвращает баланс после начисления
+func (r *repo) Credit(ctx context.Context, id int, amount int) (int, error) {
+	// Формируем запрос
+	query := sq.Update(table).
+		Set(colBalance, sq.Expr(colBalance+" + ?", int64(amount))).
+		Where(sq.Eq{colID: id}).
+		Suffix("RETURNING " + colBalance).
+		PlaceholderFormat(sq.Dollar)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var balance int64
+	err = r.getter.DefaultTrOrDB(ctx, r.dbc).QueryRow(ctx, sqlStr, args...).Scan(&balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(balance), nil
+}
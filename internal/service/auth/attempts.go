@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"casino_backend/internal/service"
+	"context"
+	"time"
+)
+
+// preAuthTokenTTL Сколько действует токен второго шага входа
+const preAuthTokenTTL = 5 * time.Minute
+
+// loginFailed учитывает неверный логин или пароль. Возвращает ошибку для ответа клиенту
+func (s *serv) loginFailed(ctx context.Context, login, ip string) error {
+	if err := s.loginProtection.RecordFailure(ctx, login, ip); err != nil {
+		return err
+	}
+	return service.ErrInvalidCredentials
+}
@@ -2,23 +2,96 @@ package auth
 
 import (
 	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
 	"casino_backend/pkg/pass"
 	"casino_backend/pkg/token"
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 )
 
-func (s *serv) Login(ctx context.Context, user *model.User) (*model.AuthData, error) {
+func (s *serv) Login(ctx context.Context, user *model.User, client model.ClientInfo) (*model.AuthData, error) {
+	// Пока вход заблокирован, пароль не проверяется вовсе
+	if err := s.loginProtection.Check(ctx, user.Login, client.IP); err != nil {
+		return nil, err
+	}
+
 	// Получение пользователя из бд по логину
 	userRepo, err := s.userRepo.GetUserByLogin(ctx, user.Login)
 	if err != nil {
-		return nil, err
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		// Неизвестный логин обрабатывается как неверный пароль, в том числе по времени ответа
+		pass.VerifyPassword(s.dummyPasswordHash, user.Password)
+		return nil, s.loginFailed(ctx, user.Login, client.IP)
 	}
 
 	// Верификация пароля
 	if !pass.VerifyPassword(userRepo.Password, user.Password) {
-		return nil, errors.New("invalid  password")
+		return nil, s.loginFailed(ctx, user.Login, client.IP)
+	}
+	s.rehashIfOutdated(ctx, userRepo, user.Password)
+
+	// С включённой двухфакторной аутентификацией вместо сессии выдаётся токен второго шага.
+	// Счётчик неудач не сбрасывается до проверки кода, иначе знающий пароль мог бы перебирать коды без ограничений
+	twoFactor, err := s.twoFactorServ.Enabled(ctx, userRepo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor {
+		preAuthToken, err := token.GeneratePreAuthToken(userRepo.ID, s.jwtConfig.AccessTokenSecretKey(), preAuthTokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &model.AuthData{PreAuthToken: preAuthToken}, nil
+	}
+
+	return s.completeLogin(ctx, userRepo, client, false)
+}
+
+// LoginTwoFactor второй шаг входа: проверяет токен первого шага и код из приложения или код восстановления.
+// Неверные коды учитываются вместе с неверными паролями и так же приводят к блокировке
+func (s *serv) LoginTwoFactor(ctx context.Context, preAuthToken, code string, client model.ClientInfo) (*model.AuthData, error) {
+	claims, err := token.VerifyPreAuthToken(preAuthToken, s.jwtConfig.AccessTokenSecretKey())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidPreAuthToken, err)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, service.ErrInvalidPreAuthToken
+		}
+		return nil, err
+	}
+
+	if err = s.loginProtection.Check(ctx, user.Login, client.IP); err != nil {
+		return nil, err
+	}
+
+	err = s.twoFactorServ.Verify(ctx, user.ID, code)
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			return nil, err
+		}
+		if err = s.loginProtection.RecordFailure(ctx, user.Login, client.IP); err != nil {
+			return nil, err
+		}
+		return nil, service.ErrInvalidTwoFactorCode
+	}
+
+	return s.completeLogin(ctx, user, client, true)
+}
+
+// completeLogin сбрасывает счётчик неудач по логину и создаёт сессию
+func (s *serv) completeLogin(ctx context.Context, user *model.User, client model.ClientInfo, twoFactor bool) (*model.AuthData, error) {
+	err := s.loginProtection.Reset(ctx, user.Login)
+	if err != nil {
+		return nil, err
 	}
 
 	// Генерация sessionID
@@ -34,9 +107,13 @@ func (s *serv) Login(ctx context.Context, user *model.User) (*model.AuthData, er
 	err = s.authRepo.CreateSession(ctx,
 		&model.Session{
 			ID:           sessionID,
-			UserID:       userRepo.ID,
+			UserID:       user.ID,
 			RefreshToken: token.HashRefreshToken(refreshToken),
 			ExpiresAt:    time.Now().Add(s.jwtConfig.RefreshTokenDuration()), // Время жизни refresh токена из конфигурации
+			Device:       client.Device,
+			IP:           client.IP,
+			UserAgent:    client.UserAgent,
+			TwoFactor:    twoFactor,
 		})
 	if err != nil {
 		return nil, err
@@ -44,8 +121,10 @@ func (s *serv) Login(ctx context.Context, user *model.User) (*model.AuthData, er
 
 	// Создать access токен
 	accessToken, err := token.GenerateAccessToken(
-		userRepo.ID,
+		user.ID,
 		sessionID,
+		user.Role,
+		twoFactor,
 		s.jwtConfig.AccessTokenSecretKey(),
 		s.jwtConfig.AccessTokenDuration())
 	if err != nil {
@@ -58,3 +137,24 @@ func (s *serv) Login(ctx context.Context, user *model.User) (*model.AuthData, er
 		SessionID:    sessionID,
 	}, nil
 }
+
+// rehashIfOutdated пересчитывает хэш пароля, если он получен устаревшим алгоритмом или стоимостью.
+// Пароль в открытом виде есть только при входе, поэтому обновить хэш можно только здесь.
+// Ошибка не мешает входу: хэш обновится при следующем
+func (s *serv) rehashIfOutdated(ctx context.Context, user *model.User, password string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("rehash password of user %d: %v", user.ID, err)
+		return
+	}
+	if err = s.userRepo.SetPassword(ctx, user.ID, passwordHash); err != nil {
+		log.Printf("rehash password of user %d: %v", user.ID, err)
+		return
+	}
+
+	user.Password = passwordHash
+}
@@ -2,38 +2,81 @@ package auth
 
 import (
 	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
 	"casino_backend/pkg/token"
 	"context"
 	"errors"
+	"log"
 )
 
-func (s *serv) Refresh(ctx context.Context, data *model.AuthData) (newAccessToken string, err error) {
-	// Получение хэша refresh токена из хранилища по sessionID
-	refreshTokenHash, err := s.authRepo.GetRefreshTokenBySessionID(ctx, data.SessionID)
+// Refresh выдаёт новую пару access и refresh токенов; предъявленный refresh токен становится использованным.
+// Если предъявлен уже использованный токен той же сессии, сессия удаляется целиком:
+// токеном воспользовался кто-то ещё (либо злоумышленник, либо владелец после кражи)
+func (s *serv) Refresh(ctx context.Context, data *model.AuthData, client model.ClientInfo) (*model.AuthData, error) {
+	refreshHash := token.HashRefreshToken(data.RefreshToken)
+
+	// Генерация нового refresh токена
+	newRefreshToken, err := token.GenerateRefreshToken()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Верификация переданного refresh токена с хэшем из хранилища
-	if !token.VerifyRefreshToken(data.RefreshToken, refreshTokenHash) {
-		return "", errors.New("invalid refresh token")
-	}
+	var (
+		accessToken string
+		reused      bool
+	)
+	err = s.txManager.Do(ctx, func(ctx context.Context) error {
+		// Замена refresh токена, только если предъявлен текущий
+		rotated, err := s.authRepo.RotateRefreshToken(ctx, data.SessionID, refreshHash, token.HashRefreshToken(newRefreshToken), client.IP)
+		if err != nil {
+			return err
+		}
+		if !rotated {
+			familyID, err := s.authRepo.GetSessionIDByUsedRefreshToken(ctx, refreshHash)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return errors.New("invalid refresh token")
+				}
+				return err
+			}
 
-	// Получение пользователя по sessionID
-	user, err := s.authRepo.GetUserBySessionID(ctx, data.SessionID)
-	if err != nil {
-		return "", err
-	}
+			// Удаление сессии фиксируется транзакцией, ошибку возвращаем уже после неё
+			reused = true
+			log.Printf("refresh token reuse detected, revoking session %s", familyID)
+			return s.authRepo.DeleteSession(ctx, familyID)
+		}
+
+		// Получение пользователя по sessionID
+		user, err := s.authRepo.GetUserBySessionID(ctx, data.SessionID)
+		if err != nil {
+			return err
+		}
+		session, err := s.authRepo.GetSession(ctx, data.SessionID)
+		if err != nil {
+			return err
+		}
 
-	// Генерация нового access токена
-	newAccessToken, err = token.GenerateAccessToken(
-		user.ID,
-		data.SessionID,
-		s.jwtConfig.AccessTokenSecretKey(),
-		s.jwtConfig.AccessTokenDuration())
+		// Генерация нового access токена
+		accessToken, err = token.GenerateAccessToken(
+			user.ID,
+			data.SessionID,
+			user.Role,
+			session.TwoFactor,
+			s.jwtConfig.AccessTokenSecretKey(),
+			s.jwtConfig.AccessTokenDuration())
+		return err
+	})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if reused {
+		return nil, service.ErrRefreshTokenReused
 	}
 
-	return newAccessToken, nil
+	return &model.AuthData{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		SessionID:    data.SessionID,
+	}, nil
 }
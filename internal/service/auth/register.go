@@ -2,19 +2,29 @@ package auth
 
 import (
 	"casino_backend/internal/model"
-	"casino_backend/pkg/pass"
+	"casino_backend/internal/service"
 	"casino_backend/pkg/token"
 	"context"
+	"fmt"
 	"time"
 )
 
-func (s *serv) Register(ctx context.Context, user *model.User) (*model.AuthData, error) {
+func (s *serv) Register(ctx context.Context, user *model.User, client model.ClientInfo) (*model.AuthData, error) {
+	if err := validateUser(user); err != nil {
+		return nil, err
+	}
+	if err := s.passwordPolicy.Validate(user.Login, user.Password); err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidPassword, err)
+	}
+
 	// Хэширование пароля пользователя
-	passwordHash, err := pass.HashPassword(user.Password)
+	passwordHash, err := s.hasher.Hash(user.Password)
 	if err != nil {
 		return nil, err
 	}
 	user.Password = passwordHash
+	// Регистрируются только игроки, остальные роли назначает администратор
+	user.Role = model.RolePlayer
 
 	// Переменные для хранения результатов
 	var (
@@ -45,6 +55,9 @@ func (s *serv) Register(ctx context.Context, user *model.User) (*model.AuthData,
 				UserID:       user.ID,
 				RefreshToken: token.HashRefreshToken(refreshToken),
 				ExpiresAt:    time.Now().Add(s.jwtConfig.RefreshTokenDuration()), // Время жизни refresh токена из конфигурации
+				Device:       client.Device,
+				IP:           client.IP,
+				UserAgent:    client.UserAgent,
 			})
 		if err != nil {
 			return err
@@ -54,6 +67,8 @@ func (s *serv) Register(ctx context.Context, user *model.User) (*model.AuthData,
 		accessToken, err = token.GenerateAccessToken(
 			user.ID,
 			sessionID,
+			user.Role,
+			false,
 			s.jwtConfig.AccessTokenSecretKey(),
 			s.jwtConfig.AccessTokenDuration())
 		if err != nil {
@@ -4,6 +4,8 @@ import (
 	"casino_backend/internal/config"
 	"casino_backend/internal/repository"
 	"casino_backend/internal/service"
+	"casino_backend/pkg/pass"
+	"fmt"
 
 	"github.com/avito-tech/go-transaction-manager/trm/v2"
 	"github.com/google/uuid"
@@ -13,10 +15,18 @@ import (
 var _ service.AuthService = (*serv)(nil)
 
 type serv struct {
-	txManager trm.Manager
-	jwtConfig config.JWTConfig
-	userRepo  repository.UserRepository
-	authRepo  repository.AuthRepository
+	txManager       trm.Manager
+	jwtConfig       config.JWTConfig
+	userRepo        repository.UserRepository
+	authRepo        repository.AuthRepository
+	loginProtection service.LoginProtectionService
+	twoFactorServ   service.TwoFactorService
+	hasher          *pass.Hasher
+	passwordPolicy  *pass.Policy
+
+	// dummyPasswordHash хэш, с которым сверяется пароль при неизвестном логине,
+	// чтобы такой ответ не был заметно быстрее ответа на неверный пароль
+	dummyPasswordHash string
 }
 
 func NewService(
@@ -24,13 +34,29 @@ func NewService(
 	jwtConfig config.JWTConfig,
 	userRepo repository.UserRepository,
 	authRepo repository.AuthRepository,
-) *serv {
-	return &serv{
-		txManager: txManager,
-		jwtConfig: jwtConfig,
-		userRepo:  userRepo,
-		authRepo:  authRepo,
+	loginProtection service.LoginProtectionService,
+	twoFactorServ service.TwoFactorService,
+	hasher *pass.Hasher,
+	passwordPolicy *pass.Policy,
+) (*serv, error) {
+	// Без хэша вход по неизвестному логину отвечал бы быстрее и выдавал, какие логины существуют
+	dummyPasswordHash, err := hasher.Hash("dummy password")
+	if err != nil {
+		return nil, fmt.Errorf("hash dummy password: %w", err)
 	}
+
+	return &serv{
+		txManager:       txManager,
+		jwtConfig:       jwtConfig,
+		userRepo:        userRepo,
+		authRepo:        authRepo,
+		loginProtection: loginProtection,
+		twoFactorServ:   twoFactorServ,
+		hasher:          hasher,
+		passwordPolicy:  passwordPolicy,
+
+		dummyPasswordHash: dummyPasswordHash,
+	}, nil
 }
 
 func generateSessionID() string {
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"casino_backend/internal/model"
+	"context"
+)
+
+// Sessions возвращает активные сессии пользователя
+func (s *serv) Sessions(ctx context.Context, userID int) ([]model.Session, error) {
+	return s.authRepo.ListUserSessions(ctx, userID)
+}
+
+// RevokeSession завершает одну сессию пользователя.
+// Чужую сессию завершить нельзя — repository.ErrNotFound
+func (s *serv) RevokeSession(ctx context.Context, userID int, sessionID string) error {
+	return s.authRepo.DeleteUserSession(ctx, userID, sessionID)
+}
+
+// RevokeAllSessions завершает все сессии пользователя, включая текущую
+func (s *serv) RevokeAllSessions(ctx context.Context, userID int) error {
+	return s.authRepo.DeleteUserSessions(ctx, userID)
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения на логин и имя при регистрации. Длина логина ограничена столбцом users.login
+const (
+	minLoginLength = 3
+	maxLoginLength = 50
+	maxNameLength  = 64
+)
+
+// validateUser проверяет логин и имя нового пользователя. Пробелы по краям имени отбрасываются
+func validateUser(user *model.User) error {
+	if len(user.Login) < minLoginLength || len(user.Login) > maxLoginLength {
+		return fmt.Errorf("%w: length must be between %d and %d", service.ErrInvalidLogin, minLoginLength, maxLoginLength)
+	}
+	// Только ASCII: логины вида "аdmin" с кириллической «а» неотличимы от настоящих
+	for _, c := range user.Login {
+		if !isLoginChar(c) {
+			return fmt.Errorf("%w: only latin letters, digits, '.', '_' and '-' are allowed", service.ErrInvalidLogin)
+		}
+	}
+
+	user.Name = strings.TrimSpace(user.Name)
+	if !utf8.ValidString(user.Name) {
+		return fmt.Errorf("%w: not valid UTF-8", service.ErrInvalidName)
+	}
+	if n := utf8.RuneCountInString(user.Name); n == 0 || n > maxNameLength {
+		return fmt.Errorf("%w: length must be between 1 and %d", service.ErrInvalidName, maxNameLength)
+	}
+	for _, c := range user.Name {
+		if unicode.IsControl(c) {
+			return fmt.Errorf("%w: control characters are not allowed", service.ErrInvalidName)
+		}
+	}
+
+	return nil
+}
+
+func isLoginChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-'
+}
@@ -2,6 +2,8 @@ package cascade
 
 import (
 	"casino_backend/internal/middleware"
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
 	"context"
 	"errors"
 )
@@ -9,6 +11,9 @@ import (
 // BuyBonus Купить бонуску
 func (s *serv) BuyBonus(ctx context.Context, amount int) error {
 	cost := amount
+	if cost <= 0 {
+		return errors.New("amount must be positive")
+	}
 
 	userID, ok := middleware.UserIDFromContext(ctx)
 	if !ok {
@@ -17,15 +22,11 @@ func (s *serv) BuyBonus(ctx context.Context, amount int) error {
 
 	// Начало транзакции
 	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
-		balance, err := s.userRepo.GetBalance(txCtx, userID)
-		if err != nil {
-			return errors.New("failed to get user balance")
-		}
-		if balance < cost {
-			return errors.New("not enough balance for bonus buy")
-		}
-		err = s.userRepo.UpdateBalance(txCtx, userID, balance-cost)
+		_, err := s.walletServ.Debit(txCtx, userID, cost, model.TransactionBonusBuy, "")
 		if err != nil {
+			if errors.Is(err, repository.ErrNotEnoughBalance) {
+				return errors.New("not enough balance for bonus buy")
+			}
 			return errors.New("failed to update balance after bonus buy")
 		}
 
@@ -0,0 +1,53 @@
+package cascade
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/model"
+	"casino_backend/pkg/rng"
+)
+
+// Engine Математика каскадного слота без БД и кошелька — тот же resolveSpin, что и в игре.
+// Хранит множители ячеек в памяти, как cascade_game_state хранит их для игрока.
+// Используется офлайн-инструментами (симулятор)
+type Engine struct {
+	s    *serv
+	cfg  config.CascadeConfig
+	mult [rows][cols]int
+	hits [rows][cols]int
+}
+
+// NewEngine Создать движок каскадного слота по конфигу
+func NewEngine(cfg config.CascadeConfig) *Engine {
+	e := &Engine{s: &serv{cfg: cfg}, cfg: cfg}
+	e.resetMultipliers()
+	return e
+}
+
+// Count количество конфигов
+func (e *Engine) Count() int {
+	return e.cfg.Count()
+}
+
+// Name название конфига
+func (e *Engine) Name(idx int) string {
+	return e.cfg.Name(idx)
+}
+
+// Spin один спин на конфиге idx.
+// Платный спин сбрасывает множители, фриспин продолжает с множителями прошлого спина
+func (e *Engine) Spin(rnd rng.RNG, bet, idx int, freeSpin bool) *model.CascadeSpinResult {
+	if !freeSpin {
+		e.resetMultipliers()
+	}
+	return e.s.resolveSpin(rnd, &e.mult, &e.hits, bet, e.cfg, idx)
+}
+
+// resetMultipliers множители x1, счётчики попаданий 0
+func (e *Engine) resetMultipliers() {
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			e.mult[r][c] = 1
+			e.hits[r][c] = 0
+		}
+	}
+}
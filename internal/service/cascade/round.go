@@ -0,0 +1,19 @@
+package cascade
+
+import (
+	"casino_backend/internal/model"
+	"context"
+	"encoding/json"
+)
+
+// saveRound сохраняет раунд в историю вместе с результатом спина
+func (s *serv) saveRound(ctx context.Context, round *model.GameRound, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	round.Game = model.GameCascade
+	round.Result = data
+	return s.roundRepo.CreateRound(ctx, round)
+}
@@ -9,11 +9,14 @@ import (
 )
 
 type serv struct {
-	cfg              config.CascadeConfig
-	cascadeRepo      repository.CascadeRepository
-	userRepo         repository.UserRepository
-	cascadeStatsRepo repository.CascadeStatsRepository
-	txManager        trm.Manager
+	cfg         config.CascadeConfig
+	cascadeRepo repository.CascadeRepository
+	userRepo    repository.UserRepository
+	walletServ  service.WalletService
+	roundRepo   repository.RoundRepository
+	rtpSegments repository.RTPSegmentsRepository
+	txManager   trm.Manager
+	rngProvider service.RNGProvider
 }
 
 // NewCascadeService Создать новый cascade
@@ -21,14 +24,20 @@ func NewCascadeService(
 	cfg config.CascadeConfig,
 	repo repository.CascadeRepository,
 	userRepo repository.UserRepository,
-	cascadeStatsRepo repository.CascadeStatsRepository,
+	walletServ service.WalletService,
+	roundRepo repository.RoundRepository,
+	rtpSegments repository.RTPSegmentsRepository,
 	txManager trm.Manager,
+	rngProvider service.RNGProvider,
 ) service.CascadeService {
 	return &serv{
-		cfg:              cfg,
-		cascadeRepo:      repo,
-		userRepo:         userRepo,
-		cascadeStatsRepo: cascadeStatsRepo,
-		txManager:        txManager,
+		cfg:         cfg,
+		cascadeRepo: repo,
+		userRepo:    userRepo,
+		walletServ:  walletServ,
+		roundRepo:   roundRepo,
+		rtpSegments: rtpSegments,
+		txManager:   txManager,
+		rngProvider: rngProvider,
 	}
 }
@@ -3,12 +3,15 @@ package cascade
 import (
 	"casino_backend/internal/config"
 	"casino_backend/internal/middleware"
+	"casino_backend/pkg/rng"
 	"context"
 	"errors"
 	"log"
-	"math/rand"
+	"sort"
 
 	"casino_backend/internal/model"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -51,21 +54,31 @@ func (s *serv) Spin(ctx context.Context, req model.CascadeSpin) (*model.CascadeS
 		return nil, errors.New("user id not found in context")
 	}
 
-	// Получаем текущий индекс конфига из статистики (вне транзакции)
-	configIndex, err := s.cascadeStatsRepo.GetConfigIndex()
+	// Получаем текущий индекс конфига из статистики сегмента игрока (вне транзакции)
+	rtpController, err := s.rtpController(ctx, userID, req.Bet)
 	if err != nil {
 		return nil, err
 	}
+	configIndex := rtpController.Index()
 
 	var spinRes *model.CascadeSpinResult
 	var finalFreeSpins int
+	// ID раунда, на который ссылаются операции кошелька
+	roundID := uuid.New().String()
 
 	// Начало транзакции
 	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
-		// Проверка баланса и фриспинов внутри транзакции
+		// Блокируем строку пользователя до конца транзакции,
+		// чтобы параллельные спины одного игрока выполнялись последовательно
+		userBalance, err := s.userRepo.GetBalanceForUpdate(txCtx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Проверка фриспинов внутри транзакции
 		freeSpins, err := s.cascadeRepo.GetFreeSpinCount(txCtx, userID)
 		if err != nil {
-			err = s.cascadeRepo.CreateCascadeGameState(ctx, userID)
+			err = s.cascadeRepo.CreateCascadeGameState(txCtx, userID)
 			if err != nil {
 				log.Println(err)
 				return errors.New("failed to get count free spins in Cascade Repo")
@@ -74,42 +87,38 @@ func (s *serv) Spin(ctx context.Context, req model.CascadeSpin) (*model.CascadeS
 		}
 
 		isFreeSpin := freeSpins > 0
-		var userBalance int
 
 		if !isFreeSpin {
-			userBalance, err = s.userRepo.GetBalance(txCtx, userID)
+			userBalance, err = s.walletServ.Debit(txCtx, userID, req.Bet, model.TransactionBet, roundID)
 			if err != nil {
 				return err
 			}
-			if userBalance < req.Bet {
-				return errors.New("not enough balance")
-			}
-			userBalance -= req.Bet
-			if err := s.userRepo.UpdateBalance(txCtx, userID, userBalance); err != nil {
-				return err
-			}
 		} else {
 			freeSpins--
 			if err := s.cascadeRepo.UpdateFreeSpinCount(txCtx, userID, freeSpins); err != nil {
 				return err
 			}
-			// Получаем баланс для последующего начисления
-			userBalance, err = s.userRepo.GetBalance(txCtx, userID)
-			if err != nil {
-				return err
-			}
+		}
+
+		// Генератор для этого спина (в provably-fair режиме — по сидам игрока и следующему nonce)
+		rnd, proof, err := s.rngProvider.SpinRNG(txCtx, userID)
+		if err != nil {
+			return err
 		}
 
 		// Выполняем спин (с txCtx)
-		spinRes, err = s.spinOnce(txCtx, userID, req.Bet, !isFreeSpin, s.cfg, configIndex)
+		spinRes, err = s.spinOnce(txCtx, rnd, userID, req.Bet, !isFreeSpin, s.cfg, configIndex)
 		if err != nil {
 			return err
 		}
+		spinRes.Fairness = proof
 
 		// Начисление выигрыша
-		userBalance += spinRes.TotalPayout
-		if err := s.userRepo.UpdateBalance(txCtx, userID, userBalance); err != nil {
-			return err
+		if spinRes.TotalPayout > 0 {
+			userBalance, err = s.walletServ.Credit(txCtx, userID, spinRes.TotalPayout, model.TransactionWin, roundID)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Начисление фриспинов — уже сделано внутри spinOnce → просто читаем результат
@@ -127,20 +136,33 @@ func (s *serv) Spin(ctx context.Context, req model.CascadeSpin) (*model.CascadeS
 		// Сохраняем balance для возврата
 		spinRes.Balance = userBalance
 		spinRes.InFreeSpin = isFreeSpin
-
-		return nil
+		spinRes.RoundID = roundID
+
+		// Сохраняем раунд в историю
+		return s.saveRound(txCtx, &model.GameRound{
+			ID:          roundID,
+			UserID:      userID,
+			Bet:         req.Bet,
+			Payout:      spinRes.TotalPayout,
+			InFreeSpin:  isFreeSpin,
+			ConfigIndex: configIndex,
+			ConfigName:  s.cfg.Name(configIndex),
+		}, spinRes)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Обновляем статистику (вне транзакции)
-	err = s.cascadeStatsRepo.UpdateStats(spinRes.TotalPayout, req.Bet)
-	if err != nil {
-		return nil, errors.New("failed to update stats")
+	// Обновляем статистику регулятора RTP (вне транзакции).
+	// Фриспин ставку не списывает: его выигрыш идёт в счёт ставки спина, который дал фриспины
+	debited := req.Bet
+	if spinRes.InFreeSpin {
+		debited = 0
 	}
+	rtpController.Record(debited, spinRes.TotalPayout)
 
 	return &model.CascadeSpinResult{
+		RoundID:          spinRes.RoundID,
 		InitialBoard:     spinRes.InitialBoard,
 		Board:            spinRes.Board,
 		Cascades:         spinRes.Cascades,
@@ -150,13 +172,12 @@ func (s *serv) Spin(ctx context.Context, req model.CascadeSpin) (*model.CascadeS
 		AwardedFreeSpins: spinRes.AwardedFreeSpins,
 		FreeSpinsLeft:    finalFreeSpins,
 		InFreeSpin:       spinRes.InFreeSpin,
+		Fairness:         spinRes.Fairness,
 	}, nil
 }
 
-// spinOnce полный спин с каскадами
-func (s *serv) spinOnce(ctx context.Context, userID int, bet int, resetMultipliers bool, cfg config.CascadeConfig, configIndex int) (*model.CascadeSpinResult, error) {
-	// Инициализация доски
-	var board [rows][cols]int
+// spinOnce полный спин с каскадами: загружает множители игрока, разыгрывает спин и сохраняет состояние
+func (s *serv) spinOnce(ctx context.Context, rnd rng.RNG, userID int, bet int, resetMultipliers bool, cfg config.CascadeConfig, configIndex int) (*model.CascadeSpinResult, error) {
 	// hits - сколько раз ячейка участвовала в удалении кластера
 	// mult - множитель клетки (x1, x2, x4, x8, x16...)
 	// Загружаем состояние множителей из репозитория
@@ -176,14 +197,39 @@ func (s *serv) spinOnce(ctx context.Context, userID int, bet int, resetMultiplie
 		if err != nil {
 			return nil, err
 		}
-		// Заполняем доску заново
-		s.fillBoard(&board, cfg.BonusProbPerColumn(configIndex), cfg.SymbolWeights(configIndex))
-	} else {
-		// Фриспин — оставляем старые множители, но генерим новую доску
-		s.fillBoard(&board, cfg.BonusProbPerColumn(configIndex), cfg.SymbolWeights(configIndex))
-		// ← Важно: множители остаются от прошлого спина!
+	}
+	// Фриспин — оставляем старые множители, но генерим новую доску
+	// ← Важно: множители остаются от прошлого спина!
+
+	res := s.resolveSpin(rnd, &mult, &hits, bet, cfg, configIndex)
+
+	// Сохраняем обновлённое состояние множителей
+	if err := s.cascadeRepo.SetMultiplierState(ctx, userID, mult, hits); err != nil {
+		return nil, err
 	}
 
+	// Начисляем фриспины за бонусные символы
+	if res.AwardedFreeSpins > 0 {
+		currentFS, err := s.cascadeRepo.GetFreeSpinCount(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		err = s.cascadeRepo.UpdateFreeSpinCount(ctx, userID, currentFS+res.AwardedFreeSpins)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// resolveSpin чистая логика спина без БД: заполняет доску, разрешает каскады и обновляет множители на месте.
+// Используется и в игре, и в симуляторе
+func (s *serv) resolveSpin(rnd rng.RNG, mult, hits *[rows][cols]int, bet int, cfg config.CascadeConfig, configIndex int) *model.CascadeSpinResult {
+	// Инициализация доски
+	var board [rows][cols]int
+	s.fillBoard(rnd, &board, cfg.BonusProbPerColumn(configIndex), cfg.SymbolWeights(configIndex))
+
 	// Сохраняем начальную доску для возврата
 	initialBoard := board
 
@@ -202,9 +248,9 @@ func (s *serv) spinOnce(ctx context.Context, userID int, bet int, resetMultiplie
 
 		// Обрабатываем все кластеры на доске (подсчет выигрыша, удаление, обновление множителей)
 		for _, cl := range clusters {
-			win := s.calculateWin(cl, mult, bet, cfg.PayoutTable(configIndex))
+			win := s.calculateWin(cl, *mult, bet, cfg.PayoutTable(configIndex))
 			totalWin += win
-			avgMult := s.averageMultiplier(cl, mult)
+			avgMult := s.averageMultiplier(cl, *mult)
 
 			positions := make([]model.Position, len(cl.cells))
 			for i, cell := range cl.cells {
@@ -219,13 +265,13 @@ func (s *serv) spinOnce(ctx context.Context, userID int, bet int, resetMultiplie
 				Multiplier: avgMult,
 			})
 
-			s.removeCluster(cl, &board, &hits, &mult)
+			s.removeCluster(cl, &board, hits, mult)
 		}
 
 		// Сдвигаем символы вниз и заполняем пустоты
 		s.collapse(&board)
 		intermediateBoard := board // Копия после collapse (upper empty)
-		s.refill(&board, cfg.BonusProbPerColumn(configIndex), cfg.SymbolWeights(configIndex))
+		s.refill(rnd, &board, cfg.BonusProbPerColumn(configIndex), cfg.SymbolWeights(configIndex))
 
 		// Добавляем новые символы которые упадут на доску
 		step.NewSymbols = []struct {
@@ -245,25 +291,11 @@ func (s *serv) spinOnce(ctx context.Context, userID int, bet int, resetMultiplie
 		cascades = append(cascades, step)
 	}
 
-	// Сохраняем обновлённое состояние множителей
-	if err := s.cascadeRepo.SetMultiplierState(ctx, userID, mult, hits); err != nil {
-		return nil, err
-	}
-
 	scatterCount := s.countScatters(board)
 	awarded := 0
 	if scatterCount >= 3 {
 		if v, ok := cfg.BonusAwards(configIndex)[scatterCount]; ok {
 			awarded = v
-
-			currentFS, err := s.cascadeRepo.GetFreeSpinCount(ctx, userID)
-			if err != nil {
-				return nil, err
-			}
-			err = s.cascadeRepo.UpdateFreeSpinCount(ctx, userID, currentFS+awarded)
-			if err != nil {
-				return nil, err
-			}
 		}
 	}
 	totalPayout := s.applyMaxPayout(totalWin, bet)
@@ -275,19 +307,19 @@ func (s *serv) spinOnce(ctx context.Context, userID int, bet int, resetMultiplie
 		TotalPayout:      totalPayout,
 		ScatterCount:     scatterCount,
 		AwardedFreeSpins: awarded,
-	}, nil
+	}
 }
 
 //---------- ВСПОМОГАТЕЛЬНЫЕ МЕТОДЫ ----------
 
 // fillBoard заполняет доску начальными символами
-func (s *serv) fillBoard(board *[rows][cols]int, bonusProbPerColumn float64, weights map[int]int) {
+func (s *serv) fillBoard(rnd rng.RNG, board *[rows][cols]int, bonusProbPerColumn float64, weights map[int]int) {
 	for r := 0; r < rows; r++ {
 		for c := 0; c < cols; c++ {
-			if rand.Float64() < bonusProbPerColumn {
+			if rnd.Float64() < bonusProbPerColumn {
 				board[r][c] = symbolBonus
 			} else {
-				board[r][c] = s.randomRegularSymbol(weights)
+				board[r][c] = s.randomRegularSymbol(rnd, weights)
 			}
 		}
 	}
@@ -313,14 +345,14 @@ func (s *serv) collapse(board *[rows][cols]int) {
 }
 
 // refill заполняет empty (upper) новыми символами
-func (s *serv) refill(board *[rows][cols]int, bonusProbPerColumn float64, weights map[int]int) {
+func (s *serv) refill(rnd rng.RNG, board *[rows][cols]int, bonusProbPerColumn float64, weights map[int]int) {
 	for c := 0; c < cols; c++ {
 		for r := 0; r < rows; r++ {
 			if board[r][c] == emptyCell {
-				if rand.Float64() < bonusProbPerColumn {
+				if rnd.Float64() < bonusProbPerColumn {
 					board[r][c] = symbolBonus
 				} else {
-					board[r][c] = s.randomRegularSymbol(weights)
+					board[r][c] = s.randomRegularSymbol(rnd, weights)
 				}
 			}
 		}
@@ -328,17 +360,23 @@ func (s *serv) refill(board *[rows][cols]int, bonusProbPerColumn float64, weight
 }
 
 // randomRegularSymbol выбирает случайный обычный символ с учётом весов
-func (s *serv) randomRegularSymbol(weights map[int]int) int {
+// Символы перебираются в отсортированном порядке, чтобы при одинаковом seed результат был одинаковым
+func (s *serv) randomRegularSymbol(rnd rng.RNG, weights map[int]int) int {
 
 	total := 0
-	for _, w := range weights {
+	symbols := make([]int, 0, len(weights))
+	for sym, w := range weights {
 		total += w
+		symbols = append(symbols, sym)
 	}
 	if total == 0 {
 		return 0
 	}
-	n := rand.Intn(total)
-	for sym, w := range weights {
+	sort.Ints(symbols)
+
+	n := rnd.Intn(total)
+	for _, sym := range symbols {
+		w := weights[sym]
 		if n < w {
 			return sym
 		}
@@ -0,0 +1,21 @@
+package cascade
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/rng"
+)
+
+// VerifyBoard воспроизводит начальную доску provably-fair спина по раскрытому серверному сиду,
+// клиентскому сиду, nonce и конфигу, на котором был сыгран раунд.
+// Символы, падающие в каскадах, берутся из того же потока сразу после начальной доски
+func (s *serv) VerifyBoard(req model.FairVerify) ([7][7]int, error) {
+	if req.ConfigIndex < 0 || req.ConfigIndex >= s.cfg.Count() || req.BonusBuy {
+		return [7][7]int{}, service.ErrInvalidVerifyRequest
+	}
+
+	var board [rows][cols]int
+	rnd := rng.NewHMAC(req.ServerSeed, req.ClientSeed, req.Nonce)
+	s.fillBoard(rnd, &board, s.cfg.BonusProbPerColumn(req.ConfigIndex), s.cfg.SymbolWeights(req.ConfigIndex))
+	return board, nil
+}
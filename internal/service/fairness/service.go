@@ -0,0 +1,197 @@
+package fairness
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/rng"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+)
+
+const (
+	// Размер серверного сида в байтах (в hex — вдвое длиннее)
+	serverSeedSize = 32
+	// Размер клиентского сида по умолчанию в байтах
+	defaultClientSeedSize = 8
+	// Максимальная длина клиентского сида, задаваемого игроком
+	maxClientSeedLen = 64
+)
+
+// Проверка соответствия интерфейсам
+var (
+	_ service.FairnessService = (*serv)(nil)
+	_ service.RNGProvider     = (*serv)(nil)
+)
+
+type serv struct {
+	txManager trm.Manager
+	seedRepo  repository.FairSeedRepository
+}
+
+// NewService Создать сервис provably-fair сидов.
+// Он же выдаёт HMAC-генератор для спинов, если включён provably-fair режим
+func NewService(txManager trm.Manager, seedRepo repository.FairSeedRepository) *serv {
+	return &serv{
+		txManager: txManager,
+		seedRepo:  seedRepo,
+	}
+}
+
+// Seed возвращает активную пару сидов игрока (серверный сид скрыт, виден только хэш)
+func (s *serv) Seed(ctx context.Context, userID int) (*model.FairSeed, error) {
+	seed, err := s.ensureSeed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return hidden(seed), nil
+}
+
+// SetClientSeed меняет клиентский сид. Nonce не сбрасывается,
+// поэтому повторно выставленный старый сид не повторит уже сыгранные спины
+func (s *serv) SetClientSeed(ctx context.Context, userID int, clientSeed string) (*model.FairSeed, error) {
+	clientSeed = strings.TrimSpace(clientSeed)
+	if clientSeed == "" || len(clientSeed) > maxClientSeedLen {
+		return nil, service.ErrInvalidClientSeed
+	}
+
+	var seed *model.FairSeed
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		if _, err := s.ensureSeed(txCtx, userID); err != nil {
+			return err
+		}
+
+		var err error
+		seed, err = s.seedRepo.GetSeedForUpdate(txCtx, userID)
+		if err != nil {
+			return err
+		}
+
+		seed.ClientSeed = clientSeed
+		return s.seedRepo.UpdateSeed(txCtx, seed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hidden(seed), nil
+}
+
+// Rotate раскрывает текущий серверный сид и заменяет его новым.
+// Клиентский сид сохраняется, nonce начинается заново
+func (s *serv) Rotate(ctx context.Context, userID int) (*model.FairRotation, error) {
+	var rotation model.FairRotation
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		if _, err := s.ensureSeed(txCtx, userID); err != nil {
+			return err
+		}
+
+		old, err := s.seedRepo.GetSeedForUpdate(txCtx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Старая пара уходит в архив вместе с серверным сидом
+		if err := s.seedRepo.ArchiveSeed(txCtx, old); err != nil {
+			return err
+		}
+
+		next, err := newSeed(userID, old.ClientSeed)
+		if err != nil {
+			return err
+		}
+		if err := s.seedRepo.UpdateSeed(txCtx, next); err != nil {
+			return err
+		}
+
+		rotation.Revealed = *old
+		rotation.Next = *hidden(next)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rotation, nil
+}
+
+// SpinRNG увеличивает nonce и возвращает HMAC-генератор для спина.
+// Вызывается внутри транзакции спина: если спин откатится, откатится и nonce
+func (s *serv) SpinRNG(ctx context.Context, userID int) (rng.RNG, *model.FairnessProof, error) {
+	nonce, err := s.seedRepo.IncrementNonce(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Первый спин игрока — сиды ещё не созданы
+		if _, err = s.ensureSeed(ctx, userID); err != nil {
+			return nil, nil, err
+		}
+		nonce, err = s.seedRepo.IncrementNonce(ctx, userID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seed, err := s.seedRepo.GetSeed(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof := &model.FairnessProof{
+		ServerSeedHash: seed.ServerSeedHash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          nonce,
+	}
+	return rng.NewHMAC(seed.ServerSeed, seed.ClientSeed, nonce), proof, nil
+}
+
+// ensureSeed возвращает пару сидов игрока, создавая её при первом обращении
+func (s *serv) ensureSeed(ctx context.Context, userID int) (*model.FairSeed, error) {
+	seed, err := s.seedRepo.GetSeed(ctx, userID)
+	if err == nil {
+		return seed, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	clientSeed, err := rng.GenerateSeed(defaultClientSeedSize)
+	if err != nil {
+		return nil, err
+	}
+	seed, err = newSeed(userID, clientSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Если пару параллельно создал другой запрос, вставка ничего не сделает — перечитываем
+	if err := s.seedRepo.CreateSeed(ctx, seed); err != nil {
+		return nil, err
+	}
+	return s.seedRepo.GetSeed(ctx, userID)
+}
+
+// newSeed генерирует новый серверный сид для игрока
+func newSeed(userID int, clientSeed string) (*model.FairSeed, error) {
+	serverSeed, err := rng.GenerateSeed(serverSeedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FairSeed{
+		UserID:         userID,
+		ServerSeed:     serverSeed,
+		ServerSeedHash: rng.HashSeed(serverSeed),
+		ClientSeed:     clientSeed,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// hidden возвращает копию пары сидов без серверного сида
+func hidden(seed *model.FairSeed) *model.FairSeed {
+	c := *seed
+	c.ServerSeed = ""
+	return &c
+}
@@ -0,0 +1,25 @@
+package fairness
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/rng"
+	"context"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.RNGProvider = (*staticProvider)(nil)
+
+// staticProvider отдаёт один и тот же генератор для всех спинов (обычный режим)
+type staticProvider struct {
+	rnd rng.RNG
+}
+
+// NewStaticProvider Создать провайдер, который всегда возвращает переданный генератор
+func NewStaticProvider(rnd rng.RNG) *staticProvider {
+	return &staticProvider{rnd: rnd}
+}
+
+func (p *staticProvider) SpinRNG(_ context.Context, _ int) (rng.RNG, *model.FairnessProof, error) {
+	return p.rnd, nil, nil
+}
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+)
+
+const (
+	// Размер страницы истории по умолчанию
+	defaultRoundsLimit = 20
+	// Максимальный размер страницы истории
+	maxRoundsLimit = 100
+)
+
+// Проверка соответствия интерфейсу
+var _ service.HistoryService = (*serv)(nil)
+
+type serv struct {
+	roundRepo repository.RoundRepository
+}
+
+func NewService(roundRepo repository.RoundRepository) *serv {
+	return &serv{
+		roundRepo: roundRepo,
+	}
+}
+
+// Rounds возвращает страницу истории раундов пользователя от новых к старым
+func (s *serv) Rounds(ctx context.Context, userID int, cursor int64, limit int) (*model.GameRoundPage, error) {
+	if limit <= 0 {
+		limit = defaultRoundsLimit
+	}
+	if limit > maxRoundsLimit {
+		limit = maxRoundsLimit
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	items, err := s.roundRepo.ListRounds(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.GameRoundPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = page.Items[limit-1].Seq
+	}
+
+	return page, nil
+}
+
+// Round возвращает раунд пользователя с полным результатом спина
+func (s *serv) Round(ctx context.Context, userID int, roundID string) (*model.GameRound, error) {
+	return s.roundRepo.GetRound(ctx, userID, roundID)
+}
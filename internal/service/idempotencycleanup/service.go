@@ -0,0 +1,55 @@
+package idempotencycleanup
+
+import (
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.IdempotencyCleanupService = (*serv)(nil)
+
+type serv struct {
+	repo     repository.IdempotencyRepository
+	ttl      time.Duration
+	interval time.Duration
+}
+
+// NewService Создать сервис очистки ключей идемпотентности.
+// ttl — сколько хранить ключ, interval — период фонового удаления
+func NewService(repo repository.IdempotencyRepository, ttl, interval time.Duration) *serv {
+	return &serv{
+		repo:     repo,
+		ttl:      ttl,
+		interval: interval,
+	}
+}
+
+// Cleanup удаляет ключи старше ttl
+func (s *serv) Cleanup(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpiredKeys(ctx, s.ttl)
+}
+
+// Run фоновая очистка. Старые ключи никому не мешают, поэтому при остановке сервиса финальная очистка не нужна
+func (s *serv) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Cleanup(ctx)
+			if err != nil {
+				log.Printf("idempotency keys cleanup failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d expired idempotency keys", deleted)
+			}
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"casino_backend/internal/middleware"
 	"casino_backend/internal/model"
 	servModel "casino_backend/internal/service/line/model"
+	"casino_backend/pkg/rng"
 	"context"
 	"errors"
-	"math/rand"
+
+	"github.com/google/uuid"
 )
 
 const bonusMult = 100
@@ -20,46 +22,62 @@ func (s *serv) BuyBonus(ctx context.Context, bonusReq model.BonusSpin) (*model.B
 		return nil, errors.New("user id not found")
 	}
 
-	// Ограничение покупки бонуски если есть фриспины
-	countFreeSpins, err := s.repo.GetFreeSpinCount(ctx, userID)
+	// Получаем пресет весов символов исходя из статистики сегмента игрока
+	rtpController, err := s.rtpController(ctx, userID, bonusReq.Bet)
 	if err != nil {
-		return nil, errors.New("error getting free spins")
-	}
-	if countFreeSpins > 0 {
-		return nil, errors.New("free spins are not empty")
+		return nil, err
 	}
-
-	// Получаем пресет весов символов исходя из статистики
-	preset := servModel.RtpPresets[s.lineStatsRepo.CasinoState().PresetIndex]
+	presetIndex := rtpController.Index()
+	preset := s.presets[presetIndex]
 
 	// Инициализируем структуру для хранения результатов спина
 	var res *model.BonusSpinResult
+	// ID раунда, на который ссылаются операции кошелька
+	roundID := uuid.New().String()
 
 	// Начало транзакции, где выполняется процесс бонусного спина.
 	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
-		// Получаем баланс пользователя
-		balance, err := s.userRepo.GetBalance(txCtx, userID)
+		// Блокируем строку пользователя до конца транзакции
+		_, err := s.userRepo.GetBalanceForUpdate(txCtx, userID)
 		if err != nil {
 			return err
 		}
 
-		// Считаем цену бонуски
+		// Ограничение покупки бонуски если есть фриспины
+		countFreeSpins, err := s.repo.GetFreeSpinCount(txCtx, userID)
+		if err != nil {
+			return errors.New("error getting free spins")
+		}
+		if countFreeSpins > 0 {
+			return errors.New("free spins are not empty")
+		}
+
+		// Считаем цену бонуски и списываем её с баланса.
+		// Если денег не хватает — вернётся repository.ErrNotEnoughBalance
 		bonusPrice := bonusReq.Bet * bonusMult
-		// Проверяем хватает ли денег на покупку бонуски
-		if balance < bonusPrice {
-			return errors.New("not enough balance")
+		balance, err := s.walletServ.Debit(txCtx, userID, bonusPrice, model.TransactionBonusBuy, roundID)
+		if err != nil {
+			return err
 		}
 
-		// Вычитаем из баланса цену бонуски
-		balance -= bonusPrice
+		// Генератор для этого спина (в provably-fair режиме — по сидам игрока и следующему nonce)
+		rnd, proof, err := s.rngProvider.SpinRNG(txCtx, userID)
+		if err != nil {
+			return err
+		}
 
-		spinRes, err := s.SpinOnce(bonusReq.Bet, preset, s.GenerateBonusBoard)
+		spinRes, err := s.SpinOnce(rnd, bonusReq.Bet, preset, s.GenerateBonusBoard)
 		if err != nil {
 			return err
 		}
 
 		// начисляем выигрыш trigger spin
-		balance += spinRes.TotalPayout
+		if spinRes.TotalPayout > 0 {
+			balance, err = s.walletServ.Credit(txCtx, userID, spinRes.TotalPayout, model.TransactionWin, roundID)
+			if err != nil {
+				return err
+			}
+		}
 
 		// сохраняем фриспины
 		err = s.repo.UpdateFreeSpinCount(txCtx, userID, spinRes.AwardedFreeSpins)
@@ -67,43 +85,54 @@ func (s *serv) BuyBonus(ctx context.Context, bonusReq model.BonusSpin) (*model.B
 			return err
 		}
 
-		err = s.userRepo.UpdateBalance(txCtx, userID, balance)
-		if err != nil {
-			return err
-		}
-
 		res = &model.BonusSpinResult{
+			RoundID:          roundID,
 			Board:            spinRes.Board,
 			LineWins:         spinRes.LineWins,
 			ScatterCount:     spinRes.ScatterCount,
+			ScatterPayout:    spinRes.ScatterPayout,
 			AwardedFreeSpins: spinRes.AwardedFreeSpins,
 			TotalPayout:      spinRes.TotalPayout,
 			Balance:          balance,
 			FreeSpinCount:    spinRes.AwardedFreeSpins,
+			Fairness:         proof,
 		}
 
-		return nil
+		// Сохраняем раунд в историю
+		return s.saveRound(txCtx, &model.GameRound{
+			ID:          roundID,
+			UserID:      userID,
+			Bet:         bonusPrice,
+			Payout:      spinRes.TotalPayout,
+			BonusBuy:    true,
+			ConfigIndex: presetIndex,
+			ConfigName:  preset.Name,
+		}, res)
 	})
 
 	return res, err
 }
 
-func (s *serv) GenerateBonusBoard(preset servModel.RTPPreset) [5][3]string {
+func (s *serv) GenerateBonusBoard(rnd rng.RNG, preset servModel.RTPPreset) [5][3]string {
 	var board [5][3]string
 
 	// выбираем 3 случайных барабана
 	bonusReels := make(map[int]int, 3)
-	for _, r := range rand.Perm(reels)[:3] {
-		bonusReels[r] = rand.Intn(rows)
+	for _, r := range rnd.Perm(reels)[:3] {
+		bonusReels[r] = rnd.Intn(rows)
 	}
 
 	for r := 0; r < reels; r++ {
-		// Выбор пресета для барабана
-		reelProbs := preset.Probabilities[r]
 		// Пытаемся получить строку на которой будет бонуска
 		rowB, hasBonus := bonusReels[r]
 		f_bonus := hasBonus
 
+		// Барабаны 2-4 без бонуски с шансом WildChance целиком становятся W
+		if !hasBonus && isWildReel(r) && rnd.Float64() < preset.WildChance {
+			board[r][0], board[r][1], board[r][2] = "W", "W", "W"
+			continue
+		}
+
 		for i := 0; i < rows; i++ {
 			// Если это гарантированная позиция
 			if hasBonus && i == rowB {
@@ -111,16 +140,16 @@ func (s *serv) GenerateBonusBoard(preset servModel.RTPPreset) [5][3]string {
 				continue
 			}
 
-			symbol, _ := getSymbolFromProbs(reelProbs)
+			symbol, _ := getSymbolFromWeights(rnd, preset.SymbolWeights)
 			// W только если нет бонуса на барабане
-			if (r == 1 || r == 2 || r == 3) && symbol == "W" && !f_bonus {
+			if isWildReel(r) && symbol == "W" && !f_bonus {
 				board[r][0], board[r][1], board[r][2] = "W", "W", "W"
 				break
 			}
 
 			// запрещаем вторую бонуску на барабане
 			for f_bonus && symbol == "B" {
-				symbol, _ = getSymbolFromProbs(reelProbs)
+				symbol, _ = getSymbolFromWeights(rnd, preset.SymbolWeights)
 			}
 			board[r][i] = symbol
 		}
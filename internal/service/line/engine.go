@@ -0,0 +1,38 @@
+package line
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/model"
+	"casino_backend/pkg/rng"
+)
+
+// Engine Математика line-слота без БД и кошелька — те же SpinOnce и генерация доски, что и в игре.
+// Используется офлайн-инструментами (симулятор)
+type Engine struct {
+	s *serv
+}
+
+// NewEngine Создать движок line-слота по конфигу
+func NewEngine(cfg config.LineConfig) *Engine {
+	return &Engine{s: &serv{presets: newPresets(cfg)}}
+}
+
+// Count количество пресетов
+func (e *Engine) Count() int {
+	return len(e.s.presets)
+}
+
+// Name название пресета
+func (e *Engine) Name(idx int) string {
+	return e.s.presets[idx].Name
+}
+
+// Spin один спин на пресете idx. Фриспин в line-слоте генерируется так же, как платный спин
+func (e *Engine) Spin(rnd rng.RNG, bet, idx int) (*model.SpinResult, error) {
+	return e.s.SpinOnce(rnd, bet, e.s.presets[idx], e.s.GenerateBoard)
+}
+
+// BonusSpin спин купленной бонуски на пресете idx
+func (e *Engine) BonusSpin(rnd rng.RNG, bet, idx int) (*model.SpinResult, error) {
+	return e.s.SpinOnce(rnd, bet, e.s.presets[idx], e.s.GenerateBonusBoard)
+}
@@ -24,181 +24,15 @@ var (
 		{2, 2, 1, 0, 0},
 		{1, 0, 2, 0, 1},
 	}
-
-	FreeSpinsScatter = map[int]int{3: 10, 4: 15, 5: 20}
-
-	PayoutTable = PTable{
-		"S1": {3: 25, 4: 150, 5: 450},
-		"S2": {3: 25, 4: 150, 5: 450},
-		"S3": {3: 25, 4: 150, 5: 450},
-		"S4": {3: 25, 4: 150, 5: 450},
-		"S5": {3: 75, 4: 250, 5: 1000},
-		"S6": {3: 125, 4: 500, 5: 2500},
-		"S7": {3: 125, 4: 500, 5: 2500},
-		"S8": {2: 50, 3: 250, 4: 1250, 5: 12500},
-	}
-
-	// Пресеты RTP (от 70% до 120%)
-	RtpPresets = []RTPPreset{
-		//{
-		//	Name:      "70% RTP",
-		//	TargetRTP: 70.0,
-		//	Probabilities: [5]map[string]int{
-		//		// Барабан 1:
-		//		{"S8": 18, "S7": 17, "S6": 17, "S5": 16, "S4": 8, "S3": 8, "S2": 8, "S1": 7, "X": 0, "B": 1},
-		//		// Барабан 2:
-		//		{"S8": 18, "S7": 17, "S6": 16, "S5": 16, "S4": 5, "S3": 5, "S2": 5, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 3: Wild + дорогие
-		//		{"S8": 18, "S7": 18, "S6": 18, "S5": 12, "S4": 3, "S3": 3, "S2": 3, "S1": 3, "X": 20, "B": 2},
-		//		// Барабан 4:
-		//		{"S8": 18, "S7": 17, "S6": 16, "S5": 16, "S4": 5, "S3": 5, "S2": 5, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 5:
-		//		{"S8": 18, "S7": 17, "S6": 17, "S5": 16, "S4": 8, "S3": 8, "S2": 8, "S1": 7, "X": 0, "B": 1},
-		//	},
-		//},
-		//{
-		//	Name:      "75% RTP",
-		//	TargetRTP: 75.0,
-		//	Probabilities: [5]map[string]int{
-		//		// Барабан 1:
-		//		{"S8": 18, "S7": 19, "S6": 19, "S5": 17, "S4": 7, "S3": 7, "S2": 6, "S1": 6, "X": 0, "B": 1},
-		//		// Барабан 2:
-		//		{"S8": 18, "S7": 17, "S6": 17, "S5": 15, "S4": 5, "S3": 5, "S2": 5, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 3:
-		//		{"S8": 19, "S7": 18, "S6": 18, "S5": 11, "S4": 3, "S3": 3, "S2": 3, "S1": 3, "X": 20, "B": 2},
-		//		// Барабан 4:
-		//		{"S8": 18, "S7": 17, "S6": 17, "S5": 15, "S4": 5, "S3": 5, "S2": 5, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 5:
-		//		{"S8": 18, "S7": 19, "S6": 19, "S5": 17, "S4": 7, "S3": 7, "S2": 6, "S1": 6, "X": 0, "B": 1},
-		//	},
-		//},
-		//{
-		//	Name:      "80% RTP",
-		//	TargetRTP: 80.0,
-		//	Probabilities: [5]map[string]int{
-		//		// Барабан 1:
-		//		{"S8": 19, "S7": 19, "S6": 19, "S5": 19, "S4": 6, "S3": 6, "S2": 6, "S1": 5, "X": 0, "B": 1},
-		//		// Барабан 2:
-		//		{"S8": 18, "S7": 18, "S6": 18, "S5": 16, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 3:
-		//		{"S8": 19, "S7": 18, "S6": 18, "S5": 11, "S4": 3, "S3": 3, "S2": 3, "S1": 3, "X": 20, "B": 2},
-		//		// Барабан 4:
-		//		{"S8": 18, "S7": 18, "S6": 18, "S5": 16, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 5:
-		//		{"S8": 19, "S7": 19, "S6": 19, "S5": 19, "S4": 6, "S3": 6, "S2": 6, "S1": 5, "X": 0, "B": 1},
-		//	},
-		//},
-		{
-			Name:      "85% RTP", // РАБОТАЕТ
-			TargetRTP: 85.0,
-			Probabilities: [5]map[string]int{
-				{"S8": 1, "S7": 3, "S6": 4, "S5": 7, "S4": 21, "S3": 21, "S2": 21, "S1": 21, "W": 0, "B": 1},
-				{"S8": 2, "S7": 4, "S6": 3, "S5": 8, "S4": 19, "S3": 21, "S2": 20, "S1": 20, "W": 2, "B": 1},
-				{"S8": 2, "S7": 3, "S6": 3, "S5": 6, "S4": 20, "S3": 21, "S2": 21, "S1": 21, "W": 2, "B": 1},
-				{"S8": 2, "S7": 3, "S6": 4, "S5": 8, "S4": 19, "S3": 21, "S2": 20, "S1": 20, "W": 2, "B": 1},
-				{"S8": 1, "S7": 4, "S6": 3, "S5": 7, "S4": 21, "S3": 21, "S2": 21, "S1": 21, "W": 0, "B": 1},
-			},
-		},
-		{
-			Name:      "90% RTP", // РАБОТАЕТ
-			TargetRTP: 90.0,
-			Probabilities: [5]map[string]int{
-				{"S8": 1, "S7": 3, "S6": 4, "S5": 8, "S4": 21, "S3": 21, "S2": 21, "S1": 20, "W": 0, "B": 1},
-				{"S8": 2, "S7": 4, "S6": 4, "S5": 8, "S4": 19, "S3": 20, "S2": 20, "S1": 20, "W": 2, "B": 1},
-				{"S8": 2, "S7": 3, "S6": 3, "S5": 8, "S4": 20, "S3": 20, "S2": 20, "S1": 20, "W": 3, "B": 1},
-				{"S8": 2, "S7": 4, "S6": 4, "S5": 8, "S4": 19, "S3": 20, "S2": 20, "S1": 20, "W": 2, "B": 1},
-				{"S8": 1, "S7": 4, "S6": 3, "S5": 8, "S4": 21, "S3": 21, "S2": 21, "S1": 20, "W": 0, "B": 1},
-			},
-		},
-		{
-			Name:      "95% RTP", // РАБОТАЕТ
-			TargetRTP: 95.0,
-			Probabilities: [5]map[string]int{
-				{"S8": 2, "S7": 3, "S6": 3, "S5": 13, "S4": 20, "S3": 19, "S2": 20, "S1": 19, "W": 0, "B": 1},
-				{"S8": 3, "S7": 5, "S6": 5, "S5": 7, "S4": 19, "S3": 19, "S2": 19, "S1": 19, "W": 3, "B": 1},
-				{"S8": 4, "S7": 6, "S6": 6, "S5": 7, "S4": 18, "S3": 18, "S2": 18, "S1": 18, "W": 3, "B": 2},
-				{"S8": 3, "S7": 5, "S6": 5, "S5": 7, "S4": 19, "S3": 19, "S2": 19, "S1": 19, "W": 3, "B": 1},
-				{"S8": 2, "S7": 3, "S6": 3, "S5": 13, "S4": 20, "S3": 19, "S2": 20, "S1": 19, "W": 0, "B": 1},
-			},
-		},
-		{
-			Name:      "100% RTP", // РАБОТАЕТ
-			TargetRTP: 100.0,
-			Probabilities: [5]map[string]int{
-				{"S8": 5, "S7": 6, "S6": 6, "S5": 8, "S4": 18, "S3": 18, "S2": 19, "S1": 19, "W": 0, "B": 1},
-				{"S8": 4, "S7": 5, "S6": 5, "S5": 6, "S4": 19, "S3": 19, "S2": 19, "S1": 19, "W": 3, "B": 1},
-				{"S8": 5, "S7": 6, "S6": 6, "S5": 6, "S4": 18, "S3": 18, "S2": 18, "S1": 18, "W": 3, "B": 2},
-				{"S8": 4, "S7": 5, "S6": 5, "S5": 6, "S4": 19, "S3": 19, "S2": 19, "S1": 19, "W": 3, "B": 1},
-				{"S8": 5, "S7": 6, "S6": 6, "S5": 8, "S4": 18, "S3": 18, "S2": 19, "S1": 19, "W": 0, "B": 1},
-			},
-		},
-		{
-			Name:      "105% RTP", // РАБОТАЕТ
-			TargetRTP: 105.0,
-			Probabilities: [5]map[string]int{
-				{"S8": 1, "S7": 3, "S6": 4, "S5": 9, "S4": 20, "S3": 21, "S2": 21, "S1": 20, "W": 0, "B": 1},
-				{"S8": 2, "S7": 4, "S6": 4, "S5": 7, "S4": 19, "S3": 20, "S2": 20, "S1": 20, "W": 3, "B": 1},
-				{"S8": 2, "S7": 3, "S6": 3, "S5": 8, "S4": 20, "S3": 20, "S2": 20, "S1": 20, "W": 3, "B": 1},
-				{"S8": 2, "S7": 4, "S6": 4, "S5": 7, "S4": 19, "S3": 20, "S2": 20, "S1": 20, "W": 3, "B": 1},
-				{"S8": 1, "S7": 3, "S6": 4, "S5": 9, "S4": 20, "S3": 21, "S2": 21, "S1": 20, "W": 0, "B": 1},
-			},
-		},
-		//{
-		//	Name:      "110% RTP",
-		//	TargetRTP: 110.0,
-		//	Probabilities: [5]map[string]int{
-		//		// Барабан 1:
-		//		{"S8": 24, "S7": 21, "S6": 20, "S5": 14, "S4": 5, "S3": 5, "S2": 5, "S1": 5, "X": 0, "B": 1},
-		//		// Барабан 2:
-		//		{"S8": 20, "S7": 18, "S6": 18, "S5": 14, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 3:
-		//		{"S8": 21, "S7": 17, "S6": 17, "S5": 11, "S4": 3, "S3": 3, "S2": 3, "S1": 3, "X": 20, "B": 2},
-		//		// Барабан 4:
-		//		{"S8": 20, "S7": 18, "S6": 18, "S5": 14, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 5:
-		//		{"S8": 24, "S7": 21, "S6": 20, "S5": 14, "S4": 5, "S3": 5, "S2": 5, "S1": 5, "X": 0, "B": 1},
-		//	},
-		//},
-		//{
-		//	Name:      "115% RTP",
-		//	TargetRTP: 115.0,
-		//	Probabilities: [5]map[string]int{
-		//		// Барабан 1:
-		//		{"S8": 25, "S7": 20, "S6": 20, "S5": 14, "S4": 5, "S3": 5, "S2": 5, "S1": 5, "X": 0, "B": 1},
-		//		// Барабан 2:
-		//		{"S8": 20, "S7": 18, "S6": 18, "S5": 14, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 3:
-		//		{"S8": 21, "S7": 17, "S6": 17, "S5": 11, "S4": 3, "S3": 3, "S2": 3, "S1": 3, "X": 20, "B": 2},
-		//		// Барабан 4:
-		//		{"S8": 20, "S7": 18, "S6": 18, "S5": 14, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 5:
-		//		{"S8": 25, "S7": 20, "S6": 20, "S5": 14, "S4": 5, "S3": 5, "S2": 5, "S1": 5, "X": 0, "B": 1},
-		//	},
-		//},
-		//{
-		//	Name:      "120% RTP",
-		//	TargetRTP: 120.0,
-		//	Probabilities: [5]map[string]int{
-		//		// Барабан 1:
-		//		{"S8": 25, "S7": 21, "S6": 21, "S5": 13, "S4": 5, "S3": 5, "S2": 5, "S1": 4, "X": 0, "B": 1},
-		//		// Барабан 2:
-		//		{"S8": 21, "S7": 19, "S6": 18, "S5": 12, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 3:
-		//		{"S8": 21, "S7": 17, "S6": 17, "S5": 11, "S4": 3, "S3": 3, "S2": 3, "S1": 3, "X": 20, "B": 2},
-		//		// Барабан 4:
-		//		{"S8": 21, "S7": 19, "S6": 18, "S5": 12, "S4": 4, "S3": 4, "S2": 4, "S1": 4, "X": 12, "B": 2},
-		//		// Барабан 5:
-		//		{"S8": 25, "S7": 21, "S6": 21, "S5": 13, "S4": 5, "S3": 5, "S2": 5, "S1": 4, "X": 0, "B": 1},
-		//	},
-		//},
-	}
 )
 
 type PTable map[string]map[int]int
 
-// RTPPreset Пресет вероятностей для RTP
+// RTPPreset Пресет RTP, собранный из конфига config-line.yaml
 type RTPPreset struct {
-	Name          string
-	TargetRTP     float64
-	Probabilities [5]map[string]int
+	Name             string
+	SymbolWeights    map[string]int // Веса символов из line_symbol_weights (в YAML один набор на пресет)
+	WildChance       float64        // Шанс, что барабан 2, 3 или 4 целиком станет W
+	FreeSpinsScatter map[int]int    // Фриспины за количество B на поле
+	PayoutTable      PTable         // Выплаты (в процентах от ставки) за количество символов на линии
 }
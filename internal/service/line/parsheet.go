@@ -0,0 +1,339 @@
+package line
+
+import (
+	servModel "casino_backend/internal/service/line/model"
+	"math"
+	"slices"
+	"sort"
+)
+
+// ParSheet Точный (комбинаторный) расчёт математики пресета line-слота.
+// Вероятности считаются перебором всех исходов генерации барабанов (как в GenerateBoard), а не симуляцией.
+// Все выплаты и RTP — в долях ставки за спин
+type ParSheet struct {
+	Index  int
+	Preset string
+	Lines  int
+
+	// Reels вероятность символа в каждой позиции: [барабан][ряд][символ]
+	Reels [reels][rows]map[string]float64
+
+	LinePays []ParLinePay   // Выплаты по линиям: символ x количество
+	Symbols  []ParSymbolRTP // Вклад символов в RTP по линиям
+	Scatters []ParScatter   // Распределение количества B на поле
+
+	LineRTP    float64 // RTP базовой игры по линиям
+	ScatterRTP float64 // RTP базовой игры по скаттерам
+	BaseRTP    float64 // LineRTP + ScatterRTP
+
+	FreeSpinTrigger      float64 // Вероятность запуска фриспинов за спин
+	FreeSpinsPerSpin     float64 // Ожидаемое число фриспинов, начисленных за один спин (включая ретриггеры во фриспинах)
+	FreeSpinsPerTrigger  float64 // Ожидаемое число сыгранных фриспинов за запуск с учётом ретриггеров
+	FreeSpinValue        float64 // Ожидаемый выигрыш за запуск фриспинов (в ставках)
+	FreeSpinRTP          float64 // Вклад фриспинов в RTP
+	TotalRTP             float64 // Итоговый RTP
+	FreeSpinsUnbounded   bool    // Ретриггеры в среднем дают >= 1 фриспина за фриспин — серия не заканчивается
+	MaxWinCapNeverBinds  bool    // Максимальная выплата пресета ниже лимита maxPayoutMultiplier
+	MaxTheoreticalPayout float64 // Верхняя граница выплаты за спин (в ставках)
+}
+
+// ParLinePay Строка таблицы выплат по линиям
+type ParLinePay struct {
+	Symbol      string
+	Count       int
+	Pay         int     // Выплата в процентах от ставки
+	HitsPerSpin float64 // Ожидаемое количество таких выигрышных линий за спин (по всем линиям)
+	RTP         float64 // Вклад в RTP
+}
+
+// ParSymbolRTP Вклад символа в RTP по линиям
+type ParSymbolRTP struct {
+	Symbol string
+	RTP    float64
+}
+
+// ParScatter Строка распределения количества скаттеров
+type ParScatter struct {
+	Count       int
+	Probability float64
+	Pay         int // Выплата в процентах от ставки
+	FreeSpins   int // Начисляемые фриспины
+	RTP         float64
+}
+
+// reelOutcome Один исход генерации барабана и его вероятность
+type reelOutcome struct {
+	cells [rows]string
+	p     float64
+}
+
+type symbolProb struct {
+	symbol string
+	p      float64
+}
+
+// ParSheet рассчитывает PAR sheet пресета idx
+func (e *Engine) ParSheet(idx int) *ParSheet {
+	preset := e.s.presets[idx]
+	sheet := &ParSheet{
+		Index:  idx,
+		Preset: preset.Name,
+		Lines:  len(servModel.PlayLines),
+	}
+
+	// 1. Все исходы каждого барабана -> вероятности символов по позициям и распределение B на барабане
+	var marginals [reels][rows][]symbolProb
+	var bonusDist [reels][]float64
+	for r := 0; r < reels; r++ {
+		outcomes := reelOutcomes(r, preset)
+		bonusDist[r] = make([]float64, rows+1)
+		for row := 0; row < rows; row++ {
+			sheet.Reels[r][row] = make(map[string]float64)
+		}
+		for _, o := range outcomes {
+			b := 0
+			for row, sym := range o.cells {
+				sheet.Reels[r][row][sym] += o.p
+				if sym == "B" {
+					b++
+				}
+			}
+			bonusDist[r][b] += o.p
+		}
+		for row := 0; row < rows; row++ {
+			marginals[r][row] = sortedProbs(sheet.Reels[r][row])
+		}
+	}
+
+	// 2. Линии: барабаны независимы, поэтому для одной линии перебираем произведение вероятностей позиций
+	type payKey struct {
+		symbol string
+		count  int
+	}
+	hits := make(map[payKey]float64)
+	pays := make(map[payKey]int)
+	symbols := make([]string, reels)
+	for _, line := range servModel.PlayLines {
+		evaluate := func(symbols []string, p float64) {
+			if base, count, val, ok := evaluateLine(symbols, preset.PayoutTable); ok {
+				k := payKey{base, count}
+				hits[k] += p
+				pays[k] = val
+			}
+		}
+		var walk func(r int, p float64)
+		walk = func(r int, p float64) {
+			if r == reels {
+				evaluate(symbols, p)
+				return
+			}
+			for _, sp := range marginals[r][line[r]] {
+				symbols[r] = sp.symbol
+				// Серия прервалась: остальные барабаны на выигрыш линии уже не влияют
+				if runBroken(symbols[:r+1]) {
+					evaluate(symbols[:r+1], p*sp.p)
+					continue
+				}
+				walk(r+1, p*sp.p)
+			}
+		}
+		walk(0, 1)
+	}
+
+	symbolRTP := make(map[string]float64)
+	for k, h := range hits {
+		rtp := h * float64(pays[k]) / 100
+		sheet.LinePays = append(sheet.LinePays, ParLinePay{
+			Symbol:      k.symbol,
+			Count:       k.count,
+			Pay:         pays[k],
+			HitsPerSpin: h,
+			RTP:         rtp,
+		})
+		symbolRTP[k.symbol] += rtp
+		sheet.LineRTP += rtp
+	}
+	sort.Slice(sheet.LinePays, func(i, j int) bool {
+		a, b := sheet.LinePays[i], sheet.LinePays[j]
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Count < b.Count
+	})
+	for _, sym := range sortedKeys(symbolRTP) {
+		sheet.Symbols = append(sheet.Symbols, ParSymbolRTP{Symbol: sym, RTP: symbolRTP[sym]})
+	}
+
+	// 3. Скаттеры: свёртка распределений количества B по барабанам
+	dist := []float64{1}
+	for r := 0; r < reels; r++ {
+		next := make([]float64, len(dist)+rows)
+		for a, pa := range dist {
+			for b, pb := range bonusDist[r] {
+				next[a+b] += pa * pb
+			}
+		}
+		dist = next
+	}
+
+	var awardedPerSpin float64
+	for k, p := range dist {
+		if p == 0 {
+			continue
+		}
+		row := ParScatter{Count: k, Probability: p}
+		if val, ok := preset.PayoutTable["B"][k]; ok {
+			row.Pay = val
+			row.RTP = p * float64(val) / 100
+			sheet.ScatterRTP += row.RTP
+		}
+		// Как в CountBonusSpin: фриспины только за 3 и более B
+		if k >= 3 {
+			if fs, ok := preset.FreeSpinsScatter[k]; ok && fs > 0 {
+				row.FreeSpins = fs
+				sheet.FreeSpinTrigger += p
+				awardedPerSpin += p * float64(fs)
+			}
+		}
+		sheet.Scatters = append(sheet.Scatters, row)
+	}
+	sheet.BaseRTP = sheet.LineRTP + sheet.ScatterRTP
+
+	// 4. Фриспины играются той же математикой, что и платный спин, и могут ретриггериться.
+	// Каждый спин в среднем начисляет awardedPerSpin фриспинов, поэтому серия из N фриспинов
+	// в среднем длится N / (1 - awardedPerSpin) спинов
+	sheet.FreeSpinsPerSpin = awardedPerSpin
+	if awardedPerSpin >= 1 {
+		sheet.FreeSpinsUnbounded = true
+		sheet.FreeSpinsPerTrigger = math.Inf(1)
+		sheet.FreeSpinValue = math.Inf(1)
+		sheet.FreeSpinRTP = math.Inf(1)
+		sheet.TotalRTP = math.Inf(1)
+	} else {
+		if sheet.FreeSpinTrigger > 0 {
+			sheet.FreeSpinsPerTrigger = awardedPerSpin / sheet.FreeSpinTrigger / (1 - awardedPerSpin)
+		}
+		sheet.FreeSpinValue = sheet.FreeSpinsPerTrigger * sheet.BaseRTP
+		sheet.FreeSpinRTP = awardedPerSpin / (1 - awardedPerSpin) * sheet.BaseRTP
+		sheet.TotalRTP = sheet.BaseRTP + sheet.FreeSpinRTP
+	}
+
+	// 5. Верхняя граница выплаты за спин: максимальная выплата на каждой линии + максимальная по скаттерам
+	maxLine, maxScatter := 0, 0
+	for sym, p := range preset.PayoutTable {
+		for _, val := range p {
+			if sym == "B" {
+				maxScatter = max(maxScatter, val)
+			} else {
+				maxLine = max(maxLine, val)
+			}
+		}
+	}
+	sheet.MaxTheoreticalPayout = float64(maxLine*sheet.Lines+maxScatter) / 100
+	sheet.MaxWinCapNeverBinds = sheet.MaxTheoreticalPayout <= maxPayoutMultiplier
+
+	return sheet
+}
+
+// runBroken прервана ли серия base + W с первого барабана на последнем символе prefix.
+// Пока базовый символ не определён (только W и B), выигрыш ещё зависит от следующих барабанов
+func runBroken(prefix []string) bool {
+	base := ""
+	for _, sym := range prefix {
+		if sym != "W" && sym != "B" {
+			base = sym
+			break
+		}
+	}
+	last := prefix[len(prefix)-1]
+	return base != "" && last != base && last != "W"
+}
+
+// reelOutcomes перебирает все исходы генерации барабана r ровно по правилам GenerateBoard
+func reelOutcomes(r int, preset servModel.RTPPreset) []reelOutcome {
+	probs := sortedProbs(weightsToProbs(preset.SymbolWeights))
+	wild := [rows]string{"W", "W", "W"}
+	merged := make(map[[rows]string]float64)
+
+	// Барабаны 2-4 с шансом WildChance целиком становятся W
+	start := 1.0
+	if isWildReel(r) {
+		merged[wild] += preset.WildChance
+		start = 1 - preset.WildChance
+	}
+
+	var walk func(i int, fBonus bool, cells [rows]string, p float64)
+	place := func(i int, fBonus bool, cells [rows]string, sym string, p float64) {
+		cells[i] = sym
+		walk(i+1, fBonus || sym == "B", cells, p)
+	}
+	walk = func(i int, fBonus bool, cells [rows]string, p float64) {
+		if p == 0 {
+			return
+		}
+		if i == rows {
+			merged[cells] += p
+			return
+		}
+		for _, sp := range probs {
+			q := p * sp.p
+			// Выпавший W на барабанах 2-4 раскрывается на весь барабан
+			if isWildReel(r) && sp.symbol == "W" {
+				merged[wild] += q
+				continue
+			}
+			// Второй B на барабане перевыбирается один раз (перевыбранный W не раскрывается)
+			if fBonus && sp.symbol == "B" {
+				for _, sp2 := range probs {
+					place(i, fBonus, cells, sp2.symbol, q*sp2.p)
+				}
+				continue
+			}
+			place(i, fBonus, cells, sp.symbol, q)
+		}
+	}
+	walk(0, false, [rows]string{}, start)
+
+	outcomes := make([]reelOutcome, 0, len(merged))
+	for cells, p := range merged {
+		if p > 0 {
+			outcomes = append(outcomes, reelOutcome{cells: cells, p: p})
+		}
+	}
+	return outcomes
+}
+
+// weightsToProbs переводит веса символов в вероятности
+func weightsToProbs(weights map[string]int) map[string]float64 {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	probs := make(map[string]float64, len(weights))
+	for sym, w := range weights {
+		if w > 0 {
+			probs[sym] = float64(w) / float64(total)
+		}
+	}
+	return probs
+}
+
+// sortedProbs вероятности в порядке символов (для воспроизводимого вывода)
+func sortedProbs(probs map[string]float64) []symbolProb {
+	res := make([]symbolProb, 0, len(probs))
+	for _, sym := range sortedKeys(probs) {
+		if probs[sym] > 0 {
+			res = append(res, symbolProb{symbol: sym, p: probs[sym]})
+		}
+	}
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package line
+
+import (
+	servModel "casino_backend/internal/service/line/model"
+	"casino_backend/pkg/rng"
+	"math"
+	"testing"
+)
+
+func engineOf(presets ...servModel.RTPPreset) *Engine {
+	return &Engine{s: &serv{presets: presets}}
+}
+
+// TestParSheetSingleSymbol на поле из одних S1 каждая линия платит за пять S1, скаттеров нет
+func TestParSheetSingleSymbol(t *testing.T) {
+	sheet := engineOf(servModel.RTPPreset{
+		Name:             "only_s1",
+		SymbolWeights:    map[string]int{"S1": 1, "S2": 0, "B": 0, "W": 0},
+		PayoutTable:      testPayout,
+		FreeSpinsScatter: testFreeSpins,
+	}).ParSheet(0)
+
+	want := float64(len(servModel.PlayLines)) * 450 / 100
+	if math.Abs(sheet.LineRTP-want) > 1e-12 {
+		t.Errorf("LineRTP = %v, want %v", sheet.LineRTP, want)
+	}
+	if sheet.ScatterRTP != 0 || sheet.FreeSpinTrigger != 0 || sheet.FreeSpinRTP != 0 {
+		t.Errorf("scatter RTP %v, trigger %v, free spin RTP %v, want zeros", sheet.ScatterRTP, sheet.FreeSpinTrigger, sheet.FreeSpinRTP)
+	}
+	if sheet.TotalRTP != sheet.LineRTP {
+		t.Errorf("TotalRTP = %v, want LineRTP %v", sheet.TotalRTP, sheet.LineRTP)
+	}
+	if len(sheet.LinePays) != 1 || sheet.LinePays[0].Symbol != "S1" || sheet.LinePays[0].Count != 5 {
+		t.Errorf("LinePays = %+v, want only S1 x5", sheet.LinePays)
+	}
+}
+
+// TestParSheetMatchesSimulation точный расчёт сходится с Монте-Карло на той же генерации поля
+func TestParSheetMatchesSimulation(t *testing.T) {
+	const (
+		bet    = 100
+		rounds = 100_000
+	)
+	if testing.Short() {
+		t.Skip("Monte Carlo run is slow")
+	}
+	preset := servModel.RTPPreset{
+		Name:             "sim",
+		SymbolWeights:    map[string]int{"S1": 100, "S2": 100, "S3": 100, "S4": 100, "S5": 40, "S6": 25, "S7": 15, "S8": 8, "B": 30, "W": 10},
+		WildChance:       0.08,
+		PayoutTable:      testPayout,
+		FreeSpinsScatter: testFreeSpins,
+	}
+	e := engineOf(preset)
+	sheet := e.ParSheet(0)
+
+	// Вероятности символов в каждой позиции складываются в 1
+	for r := range sheet.Reels {
+		for row := range sheet.Reels[r] {
+			sum := 0.0
+			for _, p := range sheet.Reels[r][row] {
+				sum += p
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Fatalf("reel %d row %d probabilities sum to %v", r, row, sum)
+			}
+		}
+	}
+
+	rnd := rng.NewSeeded(7)
+	var sum, sumSq, triggers float64
+	for i := 0; i < rounds; i++ {
+		res, err := e.s.SpinOnce(rnd, bet, preset, e.s.GenerateBoard)
+		if err != nil {
+			t.Fatalf("SpinOnce: %v", err)
+		}
+		x := float64(res.TotalPayout) / bet
+		sum += x
+		sumSq += x * x
+		if res.AwardedFreeSpins > 0 {
+			triggers++
+		}
+	}
+
+	n := float64(rounds)
+	mean := sum / n
+	stdErr := math.Sqrt((sumSq/n - mean*mean) / n)
+	// Пять стандартных ошибок: тест не должен падать от случайности, но ловит ошибки в расчёте
+	if math.Abs(mean-sheet.BaseRTP) > 5*stdErr {
+		t.Errorf("simulated base RTP %.4f, PAR sheet %.4f (std err %.4f)", mean, sheet.BaseRTP, stdErr)
+	}
+
+	p := sheet.FreeSpinTrigger
+	if got, tol := triggers/n, 5*math.Sqrt(p*(1-p)/n); math.Abs(got-p) > tol {
+		t.Errorf("simulated trigger rate %.5f, PAR sheet %.5f (tolerance %.5f)", got, p, tol)
+	}
+}
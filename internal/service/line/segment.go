@@ -0,0 +1,21 @@
+package line
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"context"
+)
+
+// rtpController выбирает регулятор RTP сегмента, в который попадает спин игрока со ставкой bet.
+// Сыгранные раунды считаются, только если сегменты игры различают новичков
+func (s *serv) rtpController(ctx context.Context, userID, bet int) (repository.RTPControllerRepository, error) {
+	newPlayer := false
+	if limit := s.rtpSegments.NewPlayerRounds(); limit > 0 {
+		rounds, err := s.roundRepo.CountRounds(ctx, userID, model.GameLine, limit)
+		if err != nil {
+			return nil, err
+		}
+		newPlayer = rounds < limit
+	}
+	return s.rtpSegments.Select(bet, newPlayer), nil
+}
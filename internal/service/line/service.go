@@ -1,30 +1,59 @@
 package line
 
 import (
+	"casino_backend/internal/config"
 	"casino_backend/internal/repository"
 	"casino_backend/internal/service"
+	servModel "casino_backend/internal/service/line/model"
 
 	"github.com/avito-tech/go-transaction-manager/trm/v2"
 )
 
 type serv struct {
-	repo          repository.LineRepository
-	userRepo      repository.UserRepository
-	lineStatsRepo repository.LineStatsRepository
-	txManager     trm.Manager
+	presets     []servModel.RTPPreset
+	repo        repository.LineRepository
+	userRepo    repository.UserRepository
+	walletServ  service.WalletService
+	roundRepo   repository.RoundRepository
+	rtpSegments repository.RTPSegmentsRepository
+	txManager   trm.Manager
+	rngProvider service.RNGProvider
 }
 
 // NewLineService Создать новый слот 5x3
 func NewLineService(
+	cfg config.LineConfig,
 	repo repository.LineRepository,
 	userRepo repository.UserRepository,
-	lineStatsRepo repository.LineStatsRepository,
+	walletServ service.WalletService,
+	roundRepo repository.RoundRepository,
+	rtpSegments repository.RTPSegmentsRepository,
 	txManager trm.Manager,
+	rngProvider service.RNGProvider,
 ) service.LineService {
 	return &serv{
-		repo:          repo,
-		userRepo:      userRepo,
-		lineStatsRepo: lineStatsRepo,
-		txManager:     txManager,
+		presets:     newPresets(cfg),
+		repo:        repo,
+		userRepo:    userRepo,
+		walletServ:  walletServ,
+		roundRepo:   roundRepo,
+		rtpSegments: rtpSegments,
+		txManager:   txManager,
+		rngProvider: rngProvider,
 	}
 }
+
+// newPresets собирает пресеты RTP из конфига (индекс 0 — пресет с самым высоким RTP)
+func newPresets(cfg config.LineConfig) []servModel.RTPPreset {
+	presets := make([]servModel.RTPPreset, cfg.Count())
+	for i := range presets {
+		presets[i] = servModel.RTPPreset{
+			Name:             cfg.Name(i),
+			SymbolWeights:    cfg.SymbolWeights(i),
+			WildChance:       cfg.WildChance(i),
+			FreeSpinsScatter: cfg.FreeSpinsByScatter(i),
+			PayoutTable:      cfg.PayoutTable(i),
+		}
+	}
+	return presets
+}
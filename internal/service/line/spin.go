@@ -3,12 +3,16 @@ package line
 import (
 	"casino_backend/internal/middleware"
 	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
 	servModel "casino_backend/internal/service/line/model"
+	"casino_backend/pkg/rng"
 	"context"
 	"errors"
 	"fmt"
 	"log"
-	"math/rand"
+	"sort"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -34,19 +38,33 @@ func (s *serv) Spin(ctx context.Context, spinReq model.LineSpin) (*model.SpinRes
 		return nil, errors.New("user id not found in context")
 	}
 
-	// Получаем пресет весов символов исходя из статистики
-	presetCfg := servModel.RtpPresets[s.lineStatsRepo.CasinoState().PresetIndex]
+	// Получаем пресет весов символов исходя из статистики сегмента игрока
+	rtpController, err := s.rtpController(ctx, userID, spinReq.Bet)
+	if err != nil {
+		return nil, err
+	}
+	presetIndex := rtpController.Index()
+	presetCfg := s.presets[presetIndex]
 
 	// Инициализируем структуру для хранения результатов спина
 	var res *model.SpinResult
+	// ID раунда, на который ссылаются операции кошелька
+	roundID := uuid.New().String()
 
 	// Начало транзакции где выполняется процесс спина.
-	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
+		// Блокируем строку пользователя до конца транзакции,
+		// чтобы параллельные спины одного игрока выполнялись последовательно
+		userBalance, err := s.userRepo.GetBalanceForUpdate(txCtx, userID)
+		if err != nil {
+			return errors.New("failed to get user balance")
+		}
+
 		// Получаем текущее количество фриспинов внутри транзакции
 		countFreeSpins, err := s.repo.GetFreeSpinCount(txCtx, userID)
 		if err != nil {
 			// Елси этих данных нет, то значит создаем их по умолчанию
-			err = s.repo.CreateLineGameState(ctx, userID)
+			err = s.repo.CreateLineGameState(txCtx, userID)
 			if err != nil {
 				log.Println(err)
 				return errors.New("failed to get count free spins in Line Repo")
@@ -54,26 +72,14 @@ func (s *serv) Spin(ctx context.Context, spinReq model.LineSpin) (*model.SpinRes
 			countFreeSpins = 0
 		}
 
-		// Локальная переменная для баланса
-		var userBalance int
-
-		//TODO Бойлерплейт ниже. Два раза получаем баланс когда можно получить один раз до условия
-
 		// Платный спин
-		// Если счетчик фриспинов нулевой, то списываем деньги с баланса
+		// Если счетчик фриспинов нулевой, то списываем ставку с баланса
 		if countFreeSpins == 0 {
-			// Получаем баланс пользователя
-			userBalance, err = s.userRepo.GetBalance(txCtx, userID)
+			userBalance, err = s.walletServ.Debit(txCtx, userID, spinReq.Bet, model.TransactionBet, roundID)
 			if err != nil {
-				return errors.New("failed to get user balance")
-			}
-			if userBalance < spinReq.Bet {
-				return errors.New("not enough balance")
-			}
-
-			// Списание ставки, обновление баланса пользователя
-			userBalance -= spinReq.Bet
-			if err := s.userRepo.UpdateBalance(txCtx, userID, userBalance); err != nil {
+				if errors.Is(err, repository.ErrNotEnoughBalance) {
+					return err
+				}
 				return errors.New("failed to update user balance")
 			}
 		} else { // Иначе режим фриспинов.
@@ -81,19 +87,21 @@ func (s *serv) Spin(ctx context.Context, spinReq model.LineSpin) (*model.SpinRes
 			if err := s.repo.UpdateFreeSpinCount(txCtx, userID, countFreeSpins-1); err != nil {
 				return errors.New("failed to update count free spins")
 			}
-			// Получаем баланс для последующего начисления
-			userBalance, err = s.userRepo.GetBalance(txCtx, userID)
-			if err != nil {
-				return errors.New("failed to get user balance")
-			}
+		}
+
+		// Генератор для этого спина (в provably-fair режиме — по сидам игрока и следующему nonce)
+		rnd, proof, err := s.rngProvider.SpinRNG(txCtx, userID)
+		if err != nil {
+			return err
 		}
 
 		// КЛЮЧЕВОЙ ВЫЗОВ
 		// Делаем спин (передаём countFreeSpins как параметр)
-		res, err = s.SpinOnce(spinReq.Bet, presetCfg, s.GenerateBoard)
+		res, err = s.SpinOnce(rnd, spinReq.Bet, presetCfg, s.GenerateBoard)
 		if err != nil {
 			return err
 		}
+		res.Fairness = proof
 
 		// Устанавливаем флаг InFreeSpin, если это был фриспин
 		if countFreeSpins > 0 {
@@ -101,9 +109,11 @@ func (s *serv) Spin(ctx context.Context, spinReq model.LineSpin) (*model.SpinRes
 		}
 
 		// Начисление выигрыша
-		userBalance += res.TotalPayout
-		if err := s.userRepo.UpdateBalance(txCtx, userID, userBalance); err != nil {
-			return errors.New("failed to update user balance")
+		if res.TotalPayout > 0 {
+			userBalance, err = s.walletServ.Credit(txCtx, userID, res.TotalPayout, model.TransactionWin, roundID)
+			if err != nil {
+				return errors.New("failed to update user balance")
+			}
 		}
 
 		// Если есть выигранные фриспины, добавляем их
@@ -130,45 +140,61 @@ func (s *serv) Spin(ctx context.Context, spinReq model.LineSpin) (*model.SpinRes
 		// Устанавливаем финальные значения в res
 		res.Balance = userBalance
 		res.FreeSpinCount = freeCount // Финальное значение (перезапишет, если было awarded)
-
-		return nil
+		res.RoundID = roundID
+
+		// Сохраняем раунд в историю
+		return s.saveRound(txCtx, &model.GameRound{
+			ID:          roundID,
+			UserID:      userID,
+			Bet:         spinReq.Bet,
+			Payout:      res.TotalPayout,
+			InFreeSpin:  res.InFreeSpin,
+			ConfigIndex: presetIndex,
+			ConfigName:  presetCfg.Name,
+		}, res)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Обновляем статистику
-	s.lineStatsRepo.UpdateState(float64(spinReq.Bet), float64(res.TotalPayout))
-
-	// АВТОМАТИЧЕСКАЯ РЕГУЛИРОВКА
-	s.lineStatsRepo.SmartAutoAdjust()
+	// Обновляем статистику регулятора RTP (он же при необходимости переключает пресет).
+	// Фриспин ставку не списывает: его выигрыш идёт в счёт ставки спина, который дал фриспины
+	debited := spinReq.Bet
+	if res.InFreeSpin {
+		debited = 0
+	}
+	rtpController.Record(debited, res.TotalPayout)
 
 	return res, nil
 }
 
 // SpinOnce выполняет один спин (возвращает единый SpinResult)
-func (s *serv) SpinOnce(bet int, preset servModel.RTPPreset, generateBoard func(preset servModel.RTPPreset) [5][3]string) (*model.SpinResult, error) {
+func (s *serv) SpinOnce(rnd rng.RNG, bet int, preset servModel.RTPPreset, generateBoard func(rnd rng.RNG, preset servModel.RTPPreset) [5][3]string) (*model.SpinResult, error) {
 	// Генерация игрового поля
-	board := generateBoard(preset)
+	board := generateBoard(rnd, preset)
 
 	// Подсчет символов бонуса "B" на игровом поле
 	bonusCount := s.bonusSymbolCount(board)
 
 	// Выигрыши по линиям
-	lineWins := s.EvaluateLines(board, bet)
+	lineWins := s.EvaluateLines(board, bet, preset.PayoutTable)
 	lineTotalPayout := s.TotalPayoutLines(lineWins)
 
-	// Общая выплата за спин
-	total := s.ApplyMaxPayout(lineTotalPayout, bet, maxPayoutMultiplier)
+	// Выплата за скаттеры (B в любом месте поля)
+	scatterPayout := s.ScatterPayout(bonusCount, bet, preset.PayoutTable)
+
+	// Общая выплата за спин (линии + скаттеры, с ограничением максимального выигрыша)
+	total := s.ApplyMaxPayout(lineTotalPayout+scatterPayout, bet, maxPayoutMultiplier)
 
 	// Считает сколько дается фриспинов за символы бонуски (если 3 и более) — по таблице FreeSpinsScatter
 	// Фриспины за бонус-символы
-	countFreeSpins := s.CountBonusSpin(bonusCount)
+	countFreeSpins := s.CountBonusSpin(bonusCount, preset.FreeSpinsScatter)
 
 	return &model.SpinResult{
 		Board:            board,
 		LineWins:         lineWins,
 		ScatterCount:     bonusCount,
+		ScatterPayout:    scatterPayout,
 		AwardedFreeSpins: countFreeSpins,
 		TotalPayout:      total,
 		Balance:          0,
@@ -176,22 +202,25 @@ func (s *serv) SpinOnce(bet int, preset servModel.RTPPreset, generateBoard func(
 }
 
 // GenerateBoard генерирует игровое поле матрицы 5x3
-func (s *serv) GenerateBoard(preset servModel.RTPPreset) [5][3]string {
+func (s *serv) GenerateBoard(rnd rng.RNG, preset servModel.RTPPreset) [5][3]string {
 	var board [5][3]string
 	for r := 0; r < reels; r++ {
-		// Выбор пресета для барабана
-		reelProbs := preset.Probabilities[r]
+		// Барабаны 2-4 с шансом WildChance целиком становятся W
+		if isWildReel(r) && rnd.Float64() < preset.WildChance {
+			board[r][0], board[r][1], board[r][2] = "W", "W", "W"
+			continue
+		}
 		//
 		f_bonus := false
 
 		for i := 0; i < rows; i++ {
-			symbol, _ := getSymbolFromProbs(reelProbs)
-			if (r == 1 || r == 2 || r == 3) && (symbol == "W") {
+			symbol, _ := getSymbolFromWeights(rnd, preset.SymbolWeights)
+			if isWildReel(r) && (symbol == "W") {
 				board[r][0], board[r][1], board[r][2] = "W", "W", "W"
 				break
 			}
 			if f_bonus && symbol == "B" {
-				symbol, _ = getSymbolFromProbs(reelProbs)
+				symbol, _ = getSymbolFromWeights(rnd, preset.SymbolWeights)
 			}
 			if symbol == "B" {
 				f_bonus = true
@@ -203,26 +232,33 @@ func (s *serv) GenerateBoard(preset servModel.RTPPreset) [5][3]string {
 	return board
 }
 
-// Выбор символа на основе вероятностей
-// Функции симуляции
-func getSymbolFromProbs(probs map[string]int) (string, error) {
+// isWildReel барабаны 2, 3 и 4 (индексы 1-3), на которых W раскрывается на весь барабан
+func isWildReel(r int) bool {
+	return r == 1 || r == 2 || r == 3
+}
+
+// Выбор символа на основе весов (сумма весов произвольная)
+// Символы перебираются в отсортированном порядке, чтобы при одинаковом seed результат был одинаковым
+func getSymbolFromWeights(r rng.RNG, weights map[string]int) (string, error) {
 	total := 0
-	for _, prob := range probs {
-		total += prob
+	symbols := make([]string, 0, len(weights))
+	for sym, w := range weights {
+		total += w
+		symbols = append(symbols, sym)
 	}
 
-	if total != 100 {
-		return "", fmt.Errorf("сумма вероятностей должна быть 100, got %d", total)
+	if total <= 0 {
+		return "", fmt.Errorf("сумма весов должна быть положительной, got %d", total)
 	}
+	sort.Strings(symbols)
 
-	num := rand.Intn(100) + 1
-	cumulative := 0
-
-	for sym, prob := range probs {
-		cumulative += prob
-		if num <= cumulative {
+	num := r.Intn(total)
+	for _, sym := range symbols {
+		w := weights[sym]
+		if num < w {
 			return sym, nil
 		}
+		num -= w
 	}
 
 	return "", errors.New("не удалось выбрать символ")
@@ -242,7 +278,7 @@ func (s *serv) bonusSymbolCount(board [5][3]string) int {
 }
 
 // EvaluateLines выполняет оценку выигрышных линий
-func (s *serv) EvaluateLines(board [5][3]string, bet int) []model.LineWin {
+func (s *serv) EvaluateLines(board [5][3]string, bet int, payoutTable servModel.PTable) []model.LineWin {
 	// Массив для хранения выигрышных линий
 	var wins []model.LineWin
 
@@ -253,52 +289,68 @@ func (s *serv) EvaluateLines(board [5][3]string, bet int) []model.LineWin {
 			symbols[r] = board[r][line[r]]
 		}
 
-		// Находим базовый символ (не W и не B) !!!
-		var base string
-		for _, sym := range symbols {
-			if sym != "W" && sym != "B" {
-				base = sym
-				break
-			}
+		// Если линия выигрышная, то добавляем её
+		if base, count, val, ok := evaluateLine(symbols, payoutTable); ok {
+			wins = append(wins, model.LineWin{
+				Line:   i + 1,
+				Symbol: base,
+				Count:  count,
+				Payout: val * bet / 100,
+			})
 		}
-		if base == "" {
-			continue
+	}
+	return wins
+}
+
+// evaluateLine оценивает символы одной линии (по барабанам слева направо).
+// Возвращает символ, длину комбинации и выплату из таблицы (в процентах от ставки)
+func evaluateLine(symbols []string, payoutTable servModel.PTable) (base string, count, val int, ok bool) {
+	// Находим базовый символ (не W и не B) !!!
+	for _, sym := range symbols {
+		if sym != "W" && sym != "B" {
+			base = sym
+			break
 		}
+	}
+	if base == "" {
+		return "", 0, 0, false
+	}
 
-		// Считаем последовательность base + W с первого барабана
-		count := 0
-		for _, sym := range symbols {
-			if sym == base || sym == "W" {
-				count++
-			} else {
-				break
-			}
+	// Считаем последовательность base + W с первого барабана
+	for _, sym := range symbols {
+		if sym == base || sym == "W" {
+			count++
+		} else {
+			break
 		}
+	}
 
-		// Определяем минимальное количество символов для выплаты
-		minCount := 3
-		for c := range servModel.PayoutTable[base] {
-			if c < minCount {
-				minCount = c // обновится до 2 для S8
-			}
+	// Определяем минимальное количество символов для выплаты
+	minCount := 3
+	for c := range payoutTable[base] {
+		if c < minCount {
+			minCount = c // обновится до 2 для S8
 		}
+	}
 
-		// Если количество совпадений больше или равно минимальному, то проверяем выплату
-		if count >= minCount {
-			if payTable, ok := servModel.PayoutTable[base]; ok {
-				if val, ok := payTable[count]; ok {
-					win := model.LineWin{
-						Line:   i + 1,
-						Symbol: base,
-						Count:  count,
-						Payout: val * bet / 100,
-					}
-					wins = append(wins, win)
-				}
+	// Если количество совпадений больше или равно минимальному, то проверяем выплату
+	if count >= minCount {
+		if payTable, ok := payoutTable[base]; ok {
+			if val, ok := payTable[count]; ok {
+				return base, count, val, true
 			}
 		}
 	}
-	return wins
+	return "", 0, 0, false
+}
+
+// ScatterPayout выплата за символы B в любом месте поля по строке "B" таблицы выплат.
+// Как и на линиях, значение в таблице — процент от ставки
+func (s *serv) ScatterPayout(bonusCount, bet int, payoutTable servModel.PTable) int {
+	if val, ok := payoutTable["B"][bonusCount]; ok {
+		return val * bet / 100
+	}
+	return 0
 }
 
 // TotalPayoutLines подсчет выплаты за линии
@@ -320,10 +372,10 @@ func (s *serv) ApplyMaxPayout(amount, bet, maxMult int) int {
 }
 
 // CountBonusSpin считает сколько дается фриспинов за символы бонуски
-func (s *serv) CountBonusSpin(bonusCount int) int {
+func (s *serv) CountBonusSpin(bonusCount int, freeSpinsScatter map[int]int) int {
 	awardedFreeSpins := 0
 	if bonusCount >= 3 {
-		if v, ok := servModel.FreeSpinsScatter[bonusCount]; ok {
+		if v, ok := freeSpinsScatter[bonusCount]; ok {
 			awardedFreeSpins = v
 		}
 	}
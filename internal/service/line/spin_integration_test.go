@@ -0,0 +1,245 @@
+package line
+
+import (
+	"casino_backend/internal/middleware"
+	"casino_backend/internal/model"
+	"casino_backend/internal/pgtest"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/repository/line_repo"
+	"casino_backend/internal/repository/round_repo"
+	"casino_backend/internal/repository/transaction_repo"
+	"casino_backend/internal/repository/user_repo"
+	"casino_backend/internal/service"
+	"casino_backend/internal/service/fairness"
+	servModel "casino_backend/internal/service/line/model"
+	"casino_backend/internal/service/wallet"
+	"casino_backend/pkg/rng"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var errInjected = errors.New("injected failure")
+
+// winningPreset пресет, в котором каждое поле — выигрыш на всех линиях
+var winningPreset = servModel.RTPPreset{
+	Name:             "always_win",
+	SymbolWeights:    map[string]int{"S1": 1},
+	FreeSpinsScatter: map[int]int{},
+	PayoutTable:      servModel.PTable{"S1": {3: 100, 4: 200, 5: 500}},
+}
+
+// failingWallet кошелёк, начисление выигрыша в котором всегда падает
+type failingWallet struct {
+	service.WalletService
+}
+
+func (w failingWallet) Credit(context.Context, int, int, model.TransactionType, string) (int, error) {
+	return 0, errInjected
+}
+
+// failingRounds история раундов, запись в которую всегда падает
+type failingRounds struct {
+	repository.RoundRepository
+}
+
+func (r failingRounds) CreateRound(context.Context, *model.GameRound) error {
+	return errInjected
+}
+
+// fixedSegments один регулятор с неизменным пресетом
+type fixedSegments struct {
+	repository.RTPSegmentsRepository
+	controller *fixedController
+}
+
+func (fixedSegments) NewPlayerRounds() int { return 0 }
+
+func (s fixedSegments) Select(int, bool) repository.RTPControllerRepository { return s.controller }
+
+// fixedController регулятор с неизменным пресетом, запоминающий учтённые ставки
+type fixedController struct {
+	repository.RTPControllerRepository
+	bets []int
+}
+
+func (*fixedController) Index() int          { return 0 }
+func (c *fixedController) Record(bet, _ int) { c.bets = append(c.bets, bet) }
+
+type spinFixture struct {
+	pool       *pgxpool.Pool
+	lineRepo   repository.LineRepository
+	controller *fixedController
+	serv       *serv
+}
+
+func newSpinFixture(t *testing.T) *spinFixture {
+	pool := pgtest.New(t)
+	txManager := manager.Must(trmpgx.NewDefaultFactory(pool))
+
+	userRepo := user_repo.NewUserRepository(pool, trmpgx.DefaultCtxGetter)
+	lineRepo := line_repo.NewLineRepository(pool, trmpgx.DefaultCtxGetter)
+
+	controller := &fixedController{}
+	return &spinFixture{
+		pool:       pool,
+		lineRepo:   lineRepo,
+		controller: controller,
+		serv: &serv{
+			presets:  []servModel.RTPPreset{winningPreset},
+			repo:     lineRepo,
+			userRepo: userRepo,
+			walletServ: wallet.NewService(
+				txManager,
+				userRepo,
+				transaction_repo.NewTransactionRepository(pool, trmpgx.DefaultCtxGetter),
+			),
+			roundRepo:   round_repo.NewRoundRepository(pool, trmpgx.DefaultCtxGetter),
+			rtpSegments: fixedSegments{controller: controller},
+			txManager:   txManager,
+			rngProvider: fairness.NewStaticProvider(rng.NewSeeded(1)),
+		},
+	}
+}
+
+// snapshot баланс, число операций в журнале и фриспины игрока
+type snapshot struct {
+	balance      int
+	transactions int
+	freeSpins    int
+}
+
+func (f *spinFixture) snapshot(t *testing.T, userID int) snapshot {
+	t.Helper()
+	ctx := context.Background()
+
+	var s snapshot
+	err := f.pool.QueryRow(ctx, "SELECT balance FROM users WHERE id = $1", userID).Scan(&s.balance)
+	if err != nil {
+		t.Fatalf("get balance: %v", err)
+	}
+	err = f.pool.QueryRow(ctx, "SELECT count(*) FROM transactions WHERE user_id = $1", userID).Scan(&s.transactions)
+	if err != nil {
+		t.Fatalf("count transactions: %v", err)
+	}
+	if s.freeSpins, err = f.lineRepo.GetFreeSpinCount(ctx, userID); err != nil {
+		t.Fatalf("get free spins: %v", err)
+	}
+	return s
+}
+
+func userContext(userID int) context.Context {
+	return context.WithValue(context.Background(), middleware.CtxUserIDKey, userID)
+}
+
+func TestSpinRollsBackOnFailure(t *testing.T) {
+	const bet = 10
+
+	tests := []struct {
+		name      string
+		freeSpins int
+		breakServ func(s *serv)
+	}{
+		{
+			name:      "payout fails on paid spin",
+			breakServ: func(s *serv) { s.walletServ = failingWallet{s.walletServ} },
+		},
+		{
+			name:      "payout fails on free spin",
+			freeSpins: 2,
+			breakServ: func(s *serv) { s.walletServ = failingWallet{s.walletServ} },
+		},
+		{
+			name:      "saving round fails",
+			breakServ: func(s *serv) { s.roundRepo = failingRounds{s.roundRepo} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSpinFixture(t)
+			userID := pgtest.CreateUser(t, f.pool, 1000)
+			ctx := context.Background()
+
+			if err := f.lineRepo.CreateLineGameState(ctx, userID); err != nil {
+				t.Fatalf("create game state: %v", err)
+			}
+			if err := f.lineRepo.UpdateFreeSpinCount(ctx, userID, tt.freeSpins); err != nil {
+				t.Fatalf("set free spins: %v", err)
+			}
+
+			before := f.snapshot(t, userID)
+			tt.breakServ(f.serv)
+
+			if _, err := f.serv.Spin(userContext(userID), model.LineSpin{Bet: bet}); err == nil {
+				t.Fatal("Spin succeeded, want error")
+			}
+
+			if after := f.snapshot(t, userID); after != before {
+				t.Fatalf("state changed after failed spin: before %+v, after %+v", before, after)
+			}
+		})
+	}
+}
+
+func TestSpinCommitsDebitAndPayout(t *testing.T) {
+	const bet = 10
+
+	f := newSpinFixture(t)
+	userID := pgtest.CreateUser(t, f.pool, 1000)
+	if err := f.lineRepo.CreateLineGameState(context.Background(), userID); err != nil {
+		t.Fatalf("create game state: %v", err)
+	}
+	before := f.snapshot(t, userID)
+
+	res, err := f.serv.Spin(userContext(userID), model.LineSpin{Bet: bet})
+	if err != nil {
+		t.Fatalf("Spin: %v", err)
+	}
+	if res.TotalPayout == 0 {
+		t.Fatal("winning preset paid nothing")
+	}
+
+	after := f.snapshot(t, userID)
+	if want := before.balance - bet + res.TotalPayout; after.balance != want || res.Balance != want {
+		t.Fatalf("balance = %d (response %d), want %d", after.balance, res.Balance, want)
+	}
+	if after.transactions != before.transactions+2 {
+		t.Fatalf("transactions = %d, want %d (bet and win)", after.transactions, before.transactions+2)
+	}
+}
+
+// TestSpinRecordsDebitedBet регулятор RTP учитывает только списанную ставку: фриспин идёт со ставкой 0
+func TestSpinRecordsDebitedBet(t *testing.T) {
+	const bet = 10
+
+	f := newSpinFixture(t)
+	userID := pgtest.CreateUser(t, f.pool, 1000)
+	ctx := context.Background()
+	if err := f.lineRepo.CreateLineGameState(ctx, userID); err != nil {
+		t.Fatalf("create game state: %v", err)
+	}
+
+	if _, err := f.serv.Spin(userContext(userID), model.LineSpin{Bet: bet}); err != nil {
+		t.Fatalf("paid Spin: %v", err)
+	}
+	if err := f.lineRepo.UpdateFreeSpinCount(ctx, userID, 1); err != nil {
+		t.Fatalf("set free spins: %v", err)
+	}
+	res, err := f.serv.Spin(userContext(userID), model.LineSpin{Bet: bet})
+	if err != nil {
+		t.Fatalf("free Spin: %v", err)
+	}
+	if !res.InFreeSpin {
+		t.Fatal("second spin is not a free spin")
+	}
+
+	if want := []int{bet, 0}; !slices.Equal(f.controller.bets, want) {
+		t.Fatalf("recorded bets = %v, want %v", f.controller.bets, want)
+	}
+}
@@ -0,0 +1,256 @@
+package line
+
+import (
+	"casino_backend/internal/model"
+	servModel "casino_backend/internal/service/line/model"
+	"casino_backend/pkg/rng"
+	"reflect"
+	"testing"
+)
+
+// testPayout таблица выплат из config-line.yaml (проценты от ставки)
+var testPayout = servModel.PTable{
+	"S1": {3: 25, 4: 150, 5: 450},
+	"S2": {3: 25, 4: 150, 5: 450},
+	"S3": {3: 25, 4: 150, 5: 450},
+	"S4": {3: 25, 4: 150, 5: 450},
+	"S5": {3: 75, 4: 250, 5: 1000},
+	"S6": {3: 125, 4: 500, 5: 2500},
+	"S7": {3: 125, 4: 500, 5: 2500},
+	"S8": {2: 50, 3: 250, 4: 1250, 5: 12500},
+	"B":  {3: 100, 4: 500, 5: 2500},
+}
+
+var testFreeSpins = map[int]int{3: 10, 4: 15, 5: 20}
+
+// Поля задаются по барабанам: board[барабан][ряд].
+// На noWinBoard соседние барабаны не имеют общих символов, поэтому ни одна линия не выигрывает
+var noWinBoard = [5][3]string{
+	{"S1", "S2", "S3"},
+	{"S4", "S5", "S6"},
+	{"S1", "S2", "S3"},
+	{"S4", "S5", "S6"},
+	{"S1", "S2", "S3"},
+}
+
+// withScatters копия поля с B в позициях pos ({барабан, ряд})
+func withScatters(board [5][3]string, pos ...[2]int) [5][3]string {
+	for _, p := range pos {
+		board[p[0]][p[1]] = "B"
+	}
+	return board
+}
+
+// middleLineBoard выигрывает только средняя линия (S1 x5), плюс три B вне её
+var middleLineBoard = [5][3]string{
+	{"S2", "S1", "S3"},
+	{"S4", "S1", "S5"},
+	{"B", "S1", "S6"},
+	{"S5", "S1", "B"},
+	{"B", "S1", "S2"},
+}
+
+func fixedBoard(board [5][3]string) func(rng.RNG, servModel.RTPPreset) [5][3]string {
+	return func(rng.RNG, servModel.RTPPreset) [5][3]string { return board }
+}
+
+func TestEvaluateLines(t *testing.T) {
+	s := &serv{}
+
+	tests := []struct {
+		name  string
+		board [5][3]string
+		bet   int
+		want  []model.LineWin
+	}{
+		{
+			name:  "no matching neighbours",
+			board: noWinBoard,
+			bet:   100,
+		},
+		{
+			name:  "scatters do not form lines",
+			board: withScatters(noWinBoard, [2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{3, 0}, [2]int{4, 0}),
+			bet:   100,
+		},
+		{
+			name:  "middle line of five",
+			board: middleLineBoard,
+			bet:   100,
+			want:  []model.LineWin{{Line: 1, Symbol: "S1", Count: 5, Payout: 450}},
+		},
+		{
+			name:  "payout scales with bet",
+			board: middleLineBoard,
+			bet:   20,
+			want:  []model.LineWin{{Line: 1, Symbol: "S1", Count: 5, Payout: 90}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.EvaluateLines(tt.board, tt.bet, testPayout)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("EvaluateLines() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpinOnceScatterPays(t *testing.T) {
+	const bet = 100
+
+	tests := []struct {
+		name          string
+		board         [5][3]string
+		wantScatters  int
+		wantScatter   int
+		wantFreeSpins int
+		wantLines     int
+	}{
+		{
+			name:  "two scatters pay nothing",
+			board: withScatters(noWinBoard, [2]int{0, 0}, [2]int{4, 2}),
+			// Два B — ниже порога, выплаты и фриспинов нет
+			wantScatters: 2,
+		},
+		{
+			name:          "three scatters anywhere",
+			board:         withScatters(noWinBoard, [2]int{0, 0}, [2]int{2, 1}, [2]int{4, 2}),
+			wantScatters:  3,
+			wantScatter:   100 * bet / 100,
+			wantFreeSpins: 10,
+		},
+		{
+			name:          "three scatters on one reel",
+			board:         withScatters(noWinBoard, [2]int{3, 0}, [2]int{3, 1}, [2]int{3, 2}),
+			wantScatters:  3,
+			wantScatter:   100 * bet / 100,
+			wantFreeSpins: 10,
+		},
+		{
+			name:          "four scatters",
+			board:         withScatters(noWinBoard, [2]int{0, 2}, [2]int{1, 1}, [2]int{3, 0}, [2]int{4, 1}),
+			wantScatters:  4,
+			wantScatter:   500 * bet / 100,
+			wantFreeSpins: 15,
+		},
+		{
+			name:          "five scatters",
+			board:         withScatters(noWinBoard, [2]int{0, 1}, [2]int{1, 0}, [2]int{2, 2}, [2]int{3, 1}, [2]int{4, 0}),
+			wantScatters:  5,
+			wantScatter:   2500 * bet / 100,
+			wantFreeSpins: 20,
+		},
+		{
+			name:          "scatter adds to line wins",
+			board:         middleLineBoard,
+			wantScatters:  3,
+			wantScatter:   100 * bet / 100,
+			wantFreeSpins: 10,
+			wantLines:     450,
+		},
+	}
+
+	s := &serv{}
+	preset := servModel.RTPPreset{PayoutTable: testPayout, FreeSpinsScatter: testFreeSpins}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.SpinOnce(nil, bet, preset, fixedBoard(tt.board))
+			if err != nil {
+				t.Fatalf("SpinOnce: %v", err)
+			}
+
+			if res.ScatterCount != tt.wantScatters {
+				t.Errorf("ScatterCount = %d, want %d", res.ScatterCount, tt.wantScatters)
+			}
+			if res.ScatterPayout != tt.wantScatter {
+				t.Errorf("ScatterPayout = %d, want %d", res.ScatterPayout, tt.wantScatter)
+			}
+			if res.AwardedFreeSpins != tt.wantFreeSpins {
+				t.Errorf("AwardedFreeSpins = %d, want %d", res.AwardedFreeSpins, tt.wantFreeSpins)
+			}
+			if lines := s.TotalPayoutLines(res.LineWins); lines != tt.wantLines {
+				t.Errorf("line payout = %d, want %d", lines, tt.wantLines)
+			}
+			if want := tt.wantLines + tt.wantScatter; res.TotalPayout != want {
+				t.Errorf("TotalPayout = %d, want lines + scatter = %d", res.TotalPayout, want)
+			}
+		})
+	}
+}
+
+func TestSpinOnceMaxPayoutCapsLinesAndScatter(t *testing.T) {
+	const (
+		bet = 100
+		// Каждая часть по отдельности ниже лимита, вместе — выше
+		linePct    = 6000 * 100
+		scatterPct = 6000 * 100
+	)
+
+	preset := servModel.RTPPreset{
+		PayoutTable: servModel.PTable{
+			"S1": {3: 25, 4: 150, 5: linePct},
+			"B":  {3: scatterPct},
+		},
+		FreeSpinsScatter: testFreeSpins,
+	}
+
+	s := &serv{}
+	res, err := s.SpinOnce(nil, bet, preset, fixedBoard(middleLineBoard))
+	if err != nil {
+		t.Fatalf("SpinOnce: %v", err)
+	}
+
+	lines := s.TotalPayoutLines(res.LineWins)
+	maxPay := maxPayoutMultiplier * bet
+	if lines >= maxPay || res.ScatterPayout >= maxPay {
+		t.Fatalf("fixture broken: lines %d and scatter %d must each stay below the cap %d", lines, res.ScatterPayout, maxPay)
+	}
+	if res.TotalPayout != maxPay {
+		t.Fatalf("TotalPayout = %d, want cap %d (lines %d + scatter %d)", res.TotalPayout, maxPay, lines, res.ScatterPayout)
+	}
+}
+
+func TestApplyMaxPayout(t *testing.T) {
+	s := &serv{}
+
+	tests := []struct {
+		amount, bet, maxMult, want int
+	}{
+		{amount: 0, bet: 10, maxMult: 100, want: 0},
+		{amount: 999, bet: 10, maxMult: 100, want: 999},
+		{amount: 1000, bet: 10, maxMult: 100, want: 1000},
+		{amount: 1001, bet: 10, maxMult: 100, want: 1000},
+	}
+
+	for _, tt := range tests {
+		if got := s.ApplyMaxPayout(tt.amount, tt.bet, tt.maxMult); got != tt.want {
+			t.Errorf("ApplyMaxPayout(%d, %d, %d) = %d, want %d", tt.amount, tt.bet, tt.maxMult, got, tt.want)
+		}
+	}
+}
+
+// TestGenerateBoardGolden одинаковый seed даёт одни и те же поля: на этом держатся симуляции,
+// воспроизведение спорных раундов и provably-fair проверка. Меняется порядок обращений к RNG — меняется и эталон
+func TestGenerateBoardGolden(t *testing.T) {
+	preset := servModel.RTPPreset{
+		SymbolWeights: map[string]int{"S1": 100, "S2": 100, "S3": 100, "S4": 100, "S5": 40, "S6": 25, "S7": 15, "S8": 8, "B": 12, "W": 10},
+		WildChance:    0.1,
+	}
+	want := [][5][3]string{
+		{{"S3", "S8", "S4"}, {"S5", "S7", "S2"}, {"S2", "S5", "S4"}, {"W", "W", "W"}, {"S4", "S3", "S6"}},
+		{{"S3", "S2", "S4"}, {"W", "W", "W"}, {"S2", "S1", "S2"}, {"S6", "S1", "S4"}, {"S7", "S3", "S3"}},
+		{{"S2", "S3", "S1"}, {"W", "W", "W"}, {"S4", "S4", "B"}, {"S3", "S3", "S3"}, {"S1", "S3", "S6"}},
+		{{"S3", "S3", "S3"}, {"S4", "S1", "S3"}, {"W", "W", "W"}, {"S4", "S2", "S4"}, {"S4", "S4", "S3"}},
+	}
+
+	s := &serv{}
+	rnd := rng.NewSeeded(42)
+	for i, w := range want {
+		if got := s.GenerateBoard(rnd, preset); got != w {
+			t.Fatalf("board %d = %v, want %v", i, got, w)
+		}
+	}
+}
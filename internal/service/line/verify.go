@@ -0,0 +1,23 @@
+package line
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/rng"
+)
+
+// VerifyBoard воспроизводит доску provably-fair спина по раскрытому серверному сиду,
+// клиентскому сиду, nonce и пресету, на котором был сыгран раунд
+func (s *serv) VerifyBoard(req model.FairVerify) ([5][3]string, error) {
+	if req.ConfigIndex < 0 || req.ConfigIndex >= len(s.presets) {
+		return [5][3]string{}, service.ErrInvalidVerifyRequest
+	}
+	preset := s.presets[req.ConfigIndex]
+	rnd := rng.NewHMAC(req.ServerSeed, req.ClientSeed, req.Nonce)
+
+	// Купленная бонуска генерирует доску с гарантированными скаттерами
+	if req.BonusBuy {
+		return s.GenerateBonusBoard(rnd, preset), nil
+	}
+	return s.GenerateBoard(rnd, preset), nil
+}
@@ -0,0 +1,81 @@
+package line
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/service"
+	servModel "casino_backend/internal/service/line/model"
+	"casino_backend/pkg/rng"
+	"errors"
+	"testing"
+)
+
+// TestVerifyBoardRoundTrip доска, сыгранная на HMAC-потоке, воспроизводится по раскрытым сидам
+func TestVerifyBoardRoundTrip(t *testing.T) {
+	const (
+		serverSeed = "server-seed"
+		clientSeed = "client-seed"
+	)
+	presets := []servModel.RTPPreset{
+		{
+			SymbolWeights:    map[string]int{"S1": 100, "S2": 100, "S3": 100, "S4": 100, "S5": 40, "S6": 25, "S7": 15, "S8": 8, "B": 12, "W": 0},
+			WildChance:       0.1,
+			PayoutTable:      testPayout,
+			FreeSpinsScatter: testFreeSpins,
+		},
+		{
+			SymbolWeights:    map[string]int{"S1": 10, "S2": 10, "S3": 10, "S4": 10, "S5": 10, "S6": 10, "S7": 10, "S8": 10, "B": 10, "W": 10},
+			WildChance:       0.3,
+			PayoutTable:      testPayout,
+			FreeSpinsScatter: testFreeSpins,
+		},
+	}
+	s := &serv{presets: presets}
+
+	for idx, preset := range presets {
+		for nonce := 1; nonce <= 20; nonce++ {
+			for _, bonusBuy := range []bool{false, true} {
+				rnd := rng.NewHMAC(serverSeed, clientSeed, nonce)
+				var played [5][3]string
+				if bonusBuy {
+					played = s.GenerateBonusBoard(rnd, preset)
+				} else {
+					played = s.GenerateBoard(rnd, preset)
+				}
+
+				verified, err := s.VerifyBoard(model.FairVerify{
+					Game:        model.GameLine,
+					ServerSeed:  serverSeed,
+					ClientSeed:  clientSeed,
+					Nonce:       nonce,
+					ConfigIndex: idx,
+					BonusBuy:    bonusBuy,
+				})
+				if err != nil {
+					t.Fatalf("VerifyBoard(preset %d, nonce %d, bonus %v): %v", idx, nonce, bonusBuy, err)
+				}
+				if verified != played {
+					t.Fatalf("preset %d, nonce %d, bonus %v: verified %v, played %v", idx, nonce, bonusBuy, verified, played)
+				}
+			}
+		}
+	}
+
+	// Неверный сид не воспроизводит доску
+	played := s.GenerateBoard(rng.NewHMAC(serverSeed, clientSeed, 1), presets[0])
+	verified, err := s.VerifyBoard(model.FairVerify{ServerSeed: "other", ClientSeed: clientSeed, Nonce: 1})
+	if err != nil {
+		t.Fatalf("VerifyBoard: %v", err)
+	}
+	if verified == played {
+		t.Fatal("board reproduced with a wrong server seed")
+	}
+}
+
+func TestVerifyBoardRejectsUnknownPreset(t *testing.T) {
+	s := &serv{presets: []servModel.RTPPreset{{}}}
+	for _, idx := range []int{-1, 1} {
+		if _, err := s.VerifyBoard(model.FairVerify{ConfigIndex: idx}); !errors.Is(err, service.ErrInvalidVerifyRequest) {
+			t.Errorf("VerifyBoard(config %d) error = %v, want ErrInvalidVerifyRequest", idx, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package loginattemptcleanup
+
+import (
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.LoginAttemptCleanupService = (*serv)(nil)
+
+type serv struct {
+	attemptRepo repository.LoginAttemptRepository
+	window      time.Duration
+	interval    time.Duration
+}
+
+// NewService Создать сервис очистки счётчиков неудачных попыток входа.
+// window — пауза, после которой счётчик неудач начинается заново, interval — период фонового удаления
+func NewService(attemptRepo repository.LoginAttemptRepository, window, interval time.Duration) *serv {
+	return &serv{
+		attemptRepo: attemptRepo,
+		window:      window,
+		interval:    interval,
+	}
+}
+
+// Cleanup удаляет счётчики без неудач дольше window, если блокировка по ним уже истекла
+func (s *serv) Cleanup(ctx context.Context) (int64, error) {
+	return s.attemptRepo.DeleteStale(ctx, s.window)
+}
+
+// Run фоновая очистка. Устаревшие счётчики не влияют на вход, поэтому при остановке сервиса финальная очистка не нужна
+func (s *serv) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Cleanup(ctx)
+			if err != nil {
+				log.Printf("login attempts cleanup failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d stale login attempt counters", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package loginprotection
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"time"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.LoginProtectionService = (*serv)(nil)
+
+type serv struct {
+	cfg         config.LoginProtectionConfig
+	attemptRepo repository.LoginAttemptRepository
+}
+
+func NewService(cfg config.LoginProtectionConfig, attemptRepo repository.LoginAttemptRepository) *serv {
+	return &serv{
+		cfg:         cfg,
+		attemptRepo: attemptRepo,
+	}
+}
+
+// Check возвращает *service.LoginLockedError, если заблокирован логин или IP. Пустой ip не проверяется
+func (s *serv) Check(ctx context.Context, login, ip string) error {
+	retryAfter, err := s.attemptRepo.LockRemaining(ctx, keys(login, ip)...)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return &service.LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure учитывает неудачную проверку пароля или кода по логину и по IP и при превышении
+// бесплатных попыток блокирует вход. Пустой ip не учитывается
+func (s *serv) RecordFailure(ctx context.Context, login, ip string) error {
+	if err := s.recordFailure(ctx, model.LoginAttemptKey(login), s.cfg.LoginFreeAttempts()); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return s.recordFailure(ctx, model.IPAttemptKey(ip), s.cfg.IPFreeAttempts())
+}
+
+// Reset сбрасывает счётчик неудач и блокировку по логину.
+// Счётчик по IP не сбрасывается: иначе перебирающий мог бы обнулять его входом в свой аккаунт
+func (s *serv) Reset(ctx context.Context, login string) error {
+	return s.attemptRepo.Reset(ctx, model.LoginAttemptKey(login))
+}
+
+func (s *serv) recordFailure(ctx context.Context, key string, freeAttempts int) error {
+	failures, err := s.attemptRepo.RecordFailure(ctx, key, s.cfg.FailureWindow())
+	if err != nil {
+		return err
+	}
+	if failures <= freeAttempts {
+		return nil
+	}
+	return s.attemptRepo.Lock(ctx, key, lockoutDuration(
+		failures-freeAttempts,
+		s.cfg.BackoffBase(),
+		s.cfg.MaxLockout(),
+	))
+}
+
+// lockoutDuration блокировка после excess неудач сверх бесплатных: base, 2*base, 4*base, ... но не больше max
+func lockoutDuration(excess int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < excess && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
+
+func keys(login, ip string) []string {
+	if ip == "" {
+		return []string{model.LoginAttemptKey(login)}
+	}
+	return []string{model.LoginAttemptKey(login), model.IPAttemptKey(ip)}
+}
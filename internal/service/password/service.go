@@ -0,0 +1,209 @@
+package password
+
+import (
+	"casino_backend/internal/config"
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/pass"
+	"casino_backend/pkg/token"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+)
+
+// Сколько длится фоновая обработка запроса на сброс пароля
+const resetRequestTimeout = 30 * time.Second
+
+// Проверка соответствия интерфейсу
+var _ service.PasswordService = (*serv)(nil)
+
+type serv struct {
+	txManager trm.Manager
+	cfg       config.PasswordConfig
+	userRepo  repository.UserRepository
+	authRepo  repository.AuthRepository
+	resetRepo repository.PasswordResetRepository
+	notifier  service.Notifier
+	hasher    *pass.Hasher
+	policy    *pass.Policy
+
+	loginProtection service.LoginProtectionService
+}
+
+func NewService(
+	txManager trm.Manager,
+	cfg config.PasswordConfig,
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	resetRepo repository.PasswordResetRepository,
+	loginProtection service.LoginProtectionService,
+	notifier service.Notifier,
+	hasher *pass.Hasher,
+	policy *pass.Policy,
+) *serv {
+	return &serv{
+		txManager: txManager,
+		cfg:       cfg,
+		userRepo:  userRepo,
+		authRepo:  authRepo,
+		resetRepo: resetRepo,
+		notifier:  notifier,
+		hasher:    hasher,
+		policy:    policy,
+
+		loginProtection: loginProtection,
+	}
+}
+
+// ChangePassword проверяет текущий пароль, задаёт новый и завершает остальные сессии:
+// если пароль меняют из-за утечки, чужие сессии не должны пережить смену.
+// Неверный текущий пароль учитывается как неудачный вход: с украденной сессией пароль не подобрать
+func (s *serv) ChangePassword(ctx context.Context, userID int, sessionID, oldPassword, newPassword string) error {
+	user, err := s.userRepo.GetUserWithPasswordByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.loginProtection.Check(ctx, user.Login, ""); err != nil {
+		return err
+	}
+	if !pass.VerifyPassword(user.Password, oldPassword) {
+		if err := s.loginProtection.RecordFailure(ctx, user.Login, ""); err != nil {
+			return err
+		}
+		return service.ErrWrongPassword
+	}
+	if err = s.validatePassword(user.Login, newPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.SetPassword(ctx, userID, passwordHash); err != nil {
+			return err
+		}
+		if err := s.authRepo.DeleteOtherUserSessions(ctx, userID, sessionID); err != nil {
+			return err
+		}
+		return s.loginProtection.Reset(ctx, user.Login)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("user %d changed password", userID)
+	return nil
+}
+
+// RequestReset запускает в фоне создание токена сброса и его отправку пользователю.
+// Известный и неизвестный логин неотличимы ни по ответу, ни по времени: поиск пользователя,
+// запись токена и уведомление идут после ответа, а их ошибки только логируются
+func (s *serv) RequestReset(ctx context.Context, login string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resetRequestTimeout)
+		defer cancel()
+
+		if err := s.sendResetToken(ctx, login); err != nil {
+			log.Printf("password reset request: %v", err)
+		}
+	}()
+}
+
+// sendResetToken создаёт токен сброса и отправляет его пользователю. Для неизвестного логина ничего не делает
+func (s *serv) sendResetToken(ctx context.Context, login string) error {
+	user, err := s.userRepo.GetUserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	resetToken, err := token.GenerateResetToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := s.cfg.ResetTokenTTL()
+	err = s.txManager.Do(ctx, func(ctx context.Context) error {
+		return s.resetRepo.CreateResetToken(ctx, user.ID, token.HashResetToken(resetToken), ttl)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.notifier.Notify(ctx, model.Notification{
+		UserID:  user.ID,
+		Login:   user.Login,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Токен для сброса пароля: %s\nДействует %s. Если вы не запрашивали сброс, просто проигнорируйте это сообщение.",
+			resetToken, ttl.Round(time.Minute)),
+	})
+	if err != nil {
+		return fmt.Errorf("notify user %d: %w", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword задаёт новый пароль по токену сброса. Токен одноразовый, но если новый пароль
+// не подошёл, токен остаётся действительным. Все сессии пользователя завершаются,
+// блокировка входа после неудачных попыток снимается
+func (s *serv) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	var userID int
+	err := s.txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		userID, err = s.resetRepo.ConsumeResetToken(ctx, token.HashResetToken(resetToken))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return service.ErrInvalidResetToken
+			}
+			return err
+		}
+
+		user, err := s.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Логин нужен для проверки пароля, поэтому проверка внутри транзакции:
+		// при ошибке откат вернёт токен
+		if err = s.validatePassword(user.Login, newPassword); err != nil {
+			return err
+		}
+		passwordHash, err := s.hasher.Hash(newPassword)
+		if err != nil {
+			return err
+		}
+
+		if err = s.userRepo.SetPassword(ctx, userID, passwordHash); err != nil {
+			return err
+		}
+		if err = s.authRepo.DeleteUserSessions(ctx, userID); err != nil {
+			return err
+		}
+		return s.loginProtection.Reset(ctx, user.Login)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("user %d reset password", userID)
+	return nil
+}
+
+// validatePassword проверяет новый пароль по политике паролей
+func (s *serv) validatePassword(login, password string) error {
+	if err := s.policy.Validate(login, password); err != nil {
+		return fmt.Errorf("%w: %v", service.ErrInvalidPassword, err)
+	}
+	return nil
+}
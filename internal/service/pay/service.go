@@ -1,43 +1,83 @@
 package pay
 
 import (
+	"casino_backend/internal/model"
 	"casino_backend/internal/repository"
 	"casino_backend/internal/service"
 	"context"
+)
 
-	"github.com/avito-tech/go-transaction-manager/trm/v2"
+const (
+	// Размер страницы журнала по умолчанию
+	defaultTransactionsLimit = 20
+	// Максимальный размер страницы журнала
+	maxTransactionsLimit = 100
 )
 
 // Проверка соответствия интерфейсу
 var _ service.PaymentService = (*serv)(nil)
 
 type serv struct {
-	txManager trm.Manager
-	userRepo  repository.UserRepository
+	walletServ      service.WalletService
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
 }
 
 func NewService(
-	txManager trm.Manager,
+	walletServ service.WalletService,
 	userRepo repository.UserRepository,
+	transactionRepo repository.TransactionRepository,
 ) *serv {
 	return &serv{
-		txManager: txManager,
-		userRepo:  userRepo,
+		walletServ:      walletServ,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
 	}
 }
 
 func (s *serv) Deposit(ctx context.Context, userID, amount int) error {
-	return s.txManager.Do(ctx, func(txCtx context.Context) error {
-		balance, err := s.userRepo.GetBalance(txCtx, userID)
-		if err != nil {
-			return err
-		}
-
-		newBalance := balance + amount
-		return s.userRepo.UpdateBalance(txCtx, userID, newBalance)
-	})
+	if amount <= 0 {
+		return service.ErrInvalidAmount
+	}
+
+	_, err := s.walletServ.Credit(ctx, userID, amount, model.TransactionDeposit, "")
+	return err
+}
+
+// Withdraw выводит средства с баланса. Если денег не хватает — repository.ErrNotEnoughBalance
+func (s *serv) Withdraw(ctx context.Context, userID, amount int) error {
+	if amount <= 0 {
+		return service.ErrInvalidAmount
+	}
+
+	_, err := s.walletServ.Debit(ctx, userID, amount, model.TransactionWithdraw, "")
+	return err
 }
 
 func (s *serv) GetBalance(ctx context.Context, userID int) (int, error) {
 	return s.userRepo.GetBalance(ctx, userID)
 }
+
+// Transactions возвращает страницу журнала кошелька от новых операций к старым
+func (s *serv) Transactions(ctx context.Context, userID int, cursor int64, limit int) (*model.TransactionPage, error) {
+	if limit <= 0 {
+		limit = defaultTransactionsLimit
+	}
+	if limit > maxTransactionsLimit {
+		limit = maxTransactionsLimit
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	items, err := s.transactionRepo.ListTransactions(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.TransactionPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = page.Items[limit-1].ID
+	}
+
+	return page, nil
+}
@@ -0,0 +1,56 @@
+package ratelimitcleanup
+
+import (
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.RateLimitCleanupService = (*serv)(nil)
+
+type serv struct {
+	repo     repository.RateLimitRepository
+	idle     time.Duration
+	interval time.Duration
+}
+
+// NewService Создать сервис очистки корзин лимитов запросов.
+// idle — через сколько без запросов корзина пополняется до конца, interval — период фонового удаления
+func NewService(repo repository.RateLimitRepository, idle, interval time.Duration) *serv {
+	return &serv{
+		repo:     repo,
+		idle:     idle,
+		interval: interval,
+	}
+}
+
+// Cleanup удаляет корзины без запросов дольше idle. Новая корзина создаётся полной,
+// поэтому удаление пополнившейся корзины не меняет лимит
+func (s *serv) Cleanup(ctx context.Context) (int64, error) {
+	return s.repo.DeleteIdleBuckets(ctx, s.idle)
+}
+
+// Run фоновая очистка. Полные корзины никому не мешают, поэтому при остановке сервиса финальная очистка не нужна
+func (s *serv) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Cleanup(ctx)
+			if err != nil {
+				log.Printf("rate limit buckets cleanup failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d idle rate limit buckets", deleted)
+			}
+		}
+	}
+}
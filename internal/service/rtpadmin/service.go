@@ -0,0 +1,269 @@
+package rtpadmin
+
+import (
+	"casino_backend/internal/config/validator"
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	rtpModel "casino_backend/internal/repository/rtp_controller_repo/model"
+	"casino_backend/internal/service"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+)
+
+const (
+	// Размер страницы журнала по умолчанию
+	defaultAuditLimit = 50
+	// Максимальный размер страницы журнала
+	maxAuditLimit = 200
+)
+
+// Проверка соответствия интерфейсу
+var _ service.RTPAdminService = (*serv)(nil)
+
+type serv struct {
+	txManager   trm.Manager
+	controllers map[model.Game]repository.RTPSegmentsRepository
+	auditRepo   repository.RTPAuditRepository
+	stateServ   service.RTPStateService
+}
+
+func NewService(
+	txManager trm.Manager,
+	controllers map[model.Game]repository.RTPSegmentsRepository,
+	auditRepo repository.RTPAuditRepository,
+	stateServ service.RTPStateService,
+) *serv {
+	return &serv{
+		txManager:   txManager,
+		controllers: controllers,
+		auditRepo:   auditRepo,
+		stateServ:   stateServ,
+	}
+}
+
+// controller регулятор сегмента игры (пустой segment — сегмент по умолчанию)
+func (s *serv) controller(game model.Game, segment string) (repository.RTPControllerRepository, string, error) {
+	segments, ok := s.controllers[game]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: unknown game %q", service.ErrInvalidRTPOverride, game)
+	}
+	if segment == "" {
+		segment = validator.RTPDefaultSegment
+	}
+	c, ok := segments.Segment(segment)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: unknown segment %q of game %q", service.ErrInvalidRTPOverride, segment, game)
+	}
+	return c, segment, nil
+}
+
+// State возвращает состояние регулятора сегмента игры (пустой segment — сегмент по умолчанию).
+// Перед чтением регуляторы игры синхронизируются с БД, чтобы учесть спины других реплик
+func (s *serv) State(ctx context.Context, game model.Game, segment string) (*model.RTPControllerState, error) {
+	c, segment, err := s.controller(game, segment)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := s.stateServ.SyncGame(ctx, game)
+	// Вне транзакции каждый сегмент уже закоммичен
+	commit()
+	if err != nil {
+		return nil, err
+	}
+
+	st := c.State()
+	adjustments := make([]model.RTPAdjustment, len(st.Adjustments))
+	for i, a := range st.Adjustments {
+		adjustments[i] = model.RTPAdjustment{
+			Timestamp:   a.Timestamp,
+			OldIndex:    a.OldIndex,
+			NewIndex:    a.NewIndex,
+			NewConfig:   a.NewConfig,
+			Reason:      a.Reason,
+			ObservedRTP: a.ObservedRTP,
+			Profit:      a.Profit,
+		}
+	}
+
+	var totalRTP float64
+	if st.TotalBet > 0 {
+		totalRTP = float64(st.TotalPayout) / float64(st.TotalBet) * 100
+	}
+
+	return &model.RTPControllerState{
+		Game:               game,
+		Segment:            segment,
+		Segments:           s.controllers[game].Segments(),
+		Strategy:           st.Strategy,
+		ConfigIndex:        st.Index,
+		ConfigName:         c.Name(st.Index),
+		ConfigCount:        c.Count(),
+		TargetRTP:          st.TargetRTP,
+		ObservedRTP:        st.ObservedRTP,
+		TotalSpins:         st.TotalSpins,
+		TotalBet:           st.TotalBet,
+		TotalPayout:        st.TotalPayout,
+		TotalRTP:           totalRTP,
+		WindowSize:         st.WindowSize,
+		WindowSpins:        len(st.Window),
+		EmergencyMode:      st.EmergencyMode,
+		EmergencyDirection: st.EmergencyDirection,
+		Paused:             st.Paused,
+		Adjustments:        adjustments,
+	}, nil
+}
+
+// change Подготовленное изменение регулятора: значения для журнала и само изменение состояния
+type change struct {
+	oldValue any
+	newValue any
+	apply    repository.RTPOverride
+}
+
+// Override пишет изменение администратора в журнал и в снимок состояния в БД одной транзакцией,
+// чтобы остальные реплики подхватили его при следующей синхронизации.
+// Регулятор этой реплики получает изменение только после коммита: при ошибке ничего не меняется
+func (s *serv) Override(ctx context.Context, o model.RTPOverride) error {
+	ctrl, segment, err := s.controller(o.Game, o.Segment)
+	if err != nil {
+		return err
+	}
+	c, err := newChange(ctrl, o)
+	if err != nil {
+		return err
+	}
+
+	oldValue, err := json.Marshal(c.oldValue)
+	if err != nil {
+		return err
+	}
+	newValue, err := json.Marshal(c.newValue)
+	if err != nil {
+		return err
+	}
+
+	var commitSync func()
+	err = s.txManager.Do(ctx, func(ctx context.Context) error {
+		err := s.auditRepo.CreateEntry(ctx, &model.RTPAuditEntry{
+			Game:     o.Game,
+			Segment:  segment,
+			Action:   o.Action,
+			OldValue: oldValue,
+			NewValue: newValue,
+			Actor:    o.Actor,
+		})
+		if err != nil {
+			return err
+		}
+		commitSync, err = s.stateServ.OverrideSegment(ctx, o.Game, segment, c.apply)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	commitSync()
+	return nil
+}
+
+// Значения настроек в журнале
+type (
+	indexValue struct {
+		Index  int    `json:"index"`
+		Name   string `json:"name"`
+		Paused bool   `json:"paused"`
+	}
+	targetValue struct {
+		TargetRTP float64 `json:"target_rtp"`
+	}
+	windowValue struct {
+		WindowSize int `json:"window_size"`
+	}
+	pausedValue struct {
+		Paused bool `json:"paused"`
+	}
+)
+
+func newChange(ctrl repository.RTPControllerRepository, o model.RTPOverride) (*change, error) {
+	st := ctrl.State()
+	switch o.Action {
+	case model.RTPActionSetIndex:
+		if err := checkIndex(o.Index, ctrl.Count()); err != nil {
+			return nil, err
+		}
+		return &change{
+			oldValue: indexValue{Index: st.Index, Name: ctrl.Name(st.Index), Paused: st.Paused},
+			newValue: indexValue{Index: o.Index, Name: ctrl.Name(o.Index), Paused: true},
+			// Закреплённый конфиг: автокорректировка на паузе
+			apply: func(state *rtpModel.State) {
+				state.Index = o.Index
+				state.Paused = true
+			},
+		}, nil
+	case model.RTPActionSetTargetRTP:
+		if o.Target < validator.MinTargetRTP || o.Target > validator.MaxTargetRTP {
+			return nil, fmt.Errorf("%w: target rtp must be in [%d, %d]", service.ErrInvalidRTPOverride, validator.MinTargetRTP, validator.MaxTargetRTP)
+		}
+		return &change{
+			oldValue: targetValue{TargetRTP: st.TargetRTP},
+			newValue: targetValue{TargetRTP: o.Target},
+			apply:    func(state *rtpModel.State) { state.TargetRTP = o.Target },
+		}, nil
+	case model.RTPActionSetWindowSize:
+		if err := checkWindow(o.Window); err != nil {
+			return nil, err
+		}
+		return &change{
+			oldValue: windowValue{WindowSize: st.WindowSize},
+			newValue: windowValue{WindowSize: o.Window},
+			apply:    func(state *rtpModel.State) { state.WindowSize = o.Window },
+		}, nil
+	case model.RTPActionSetPaused:
+		return &change{
+			oldValue: pausedValue{Paused: st.Paused},
+			newValue: pausedValue{Paused: o.Paused},
+			apply:    func(state *rtpModel.State) { state.Paused = o.Paused },
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown action %q", service.ErrInvalidRTPOverride, o.Action)
+}
+
+func checkIndex(idx, count int) error {
+	if idx < 0 || idx >= count {
+		return fmt.Errorf("%w: index must be in [0, %d)", service.ErrInvalidRTPOverride, count)
+	}
+	return nil
+}
+
+func checkWindow(size int) error {
+	if size < validator.MinWindowSize || size > validator.MaxWindowSize {
+		return fmt.Errorf("%w: window size must be in [%d, %d]", service.ErrInvalidRTPOverride, validator.MinWindowSize, validator.MaxWindowSize)
+	}
+	return nil
+}
+
+// AuditLog возвращает страницу журнала изменений от новых к старым (game пустой — все игры)
+func (s *serv) AuditLog(ctx context.Context, game model.Game, cursor int64, limit int) (*model.RTPAuditPage, error) {
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	items, err := s.auditRepo.ListEntries(ctx, game, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.RTPAuditPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = page.Items[limit-1].ID
+	}
+
+	return page, nil
+}
@@ -0,0 +1,169 @@
+package rtpstate
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+)
+
+// finalSyncTimeout Сколько ждём финальную синхронизацию при остановке сервиса
+const finalSyncTimeout = 10 * time.Second
+
+// Проверка соответствия интерфейсу
+var _ service.RTPStateService = (*serv)(nil)
+
+type serv struct {
+	txManager trm.Manager
+	stateRepo repository.RTPStateRepository
+	interval  time.Duration
+	games     map[model.Game]repository.RTPSegmentsRepository
+}
+
+// NewService Создать сервис синхронизации регуляторов RTP.
+// games — регуляторы в памяти по играм и сегментам, interval — период фоновой синхронизации
+func NewService(
+	txManager trm.Manager,
+	stateRepo repository.RTPStateRepository,
+	interval time.Duration,
+	games map[model.Game]repository.RTPSegmentsRepository,
+) *serv {
+	return &serv{
+		txManager: txManager,
+		stateRepo: stateRepo,
+		interval:  interval,
+		games:     games,
+	}
+}
+
+// Sync синхронизирует все регуляторы. Ошибка одной игры не мешает синхронизировать остальные
+func (s *serv) Sync(ctx context.Context) error {
+	var errs []error
+	for game := range s.games {
+		commit, err := s.SyncGame(ctx, game)
+		// Каждый сегмент синхронизируется в своей транзакции, уже закоммиченной к этому моменту
+		commit()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SyncGame синхронизирует регуляторы всех сегментов одной игры.
+// commit заменяет локальное состояние успешно записанных сегментов снимками из БД;
+// его нужно вызвать только после коммита транзакции — своей или внешней из ctx.
+// Если внешняя транзакция откатилась, commit не вызывается: изменения попадут в следующую синхронизацию
+func (s *serv) SyncGame(ctx context.Context, game model.Game) (commit func(), err error) {
+	segments, ok := s.games[game]
+	if !ok {
+		return func() {}, fmt.Errorf("unknown game %q", game)
+	}
+
+	var (
+		commits []func()
+		errs    []error
+	)
+	for _, segment := range segments.Segments() {
+		stats, _ := segments.Segment(segment)
+		c, err := s.syncSegment(ctx, game, segment, stats, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sync %s/%s rtp state: %w", game, segment, err))
+			continue
+		}
+		commits = append(commits, c)
+	}
+
+	return func() {
+		for _, c := range commits {
+			c()
+		}
+	}, errors.Join(errs...)
+}
+
+// OverrideSegment синхронизирует регулятор сегмента и записывает в снимок изменение администратора.
+// Регулятор в памяти получает изменение только через commit, который вызывают после коммита транзакции:
+// если она откатилась, ни снимок, ни локальное состояние не меняются
+func (s *serv) OverrideSegment(ctx context.Context, game model.Game, segment string, override repository.RTPOverride) (commit func(), err error) {
+	segments, ok := s.games[game]
+	if !ok {
+		return nil, fmt.Errorf("unknown game %q", game)
+	}
+	stats, ok := segments.Segment(segment)
+	if !ok {
+		return nil, fmt.Errorf("unknown segment %q of game %q", segment, game)
+	}
+
+	commit, err = s.syncSegment(ctx, game, segment, stats, override)
+	if err != nil {
+		return nil, fmt.Errorf("override %s/%s rtp state: %w", game, segment, err)
+	}
+	return commit, nil
+}
+
+// syncSegment под блокировкой строки сливает локальные изменения и override со снимком в БД и записывает результат.
+// Возвращает commit, заменяющий локальное состояние снимком: вызывающий применяет его только после коммита,
+// иначе изменения остаются в регуляторе и попадут в следующую синхронизацию
+func (s *serv) syncSegment(
+	ctx context.Context,
+	game model.Game,
+	segment string,
+	stats repository.SyncableStats,
+	override repository.RTPOverride,
+) (func(), error) {
+	var commit func()
+	err := s.txManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.stateRepo.EnsureState(ctx, game, segment); err != nil {
+			return err
+		}
+
+		stored, err := s.stateRepo.GetStateForUpdate(ctx, game, segment)
+		if err != nil {
+			return err
+		}
+
+		snapshot, c, err := stats.MergeSnapshot(stored, override)
+		if err != nil {
+			return err
+		}
+
+		if err := s.stateRepo.SaveState(ctx, game, segment, snapshot); err != nil {
+			return err
+		}
+		commit = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// Run фоновая синхронизация. После отмены ctx выполняет финальную синхронизацию,
+// чтобы при остановке сервиса не потерять спины с последнего тика
+func (s *serv) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			finalCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalSyncTimeout)
+			defer cancel()
+			if err := s.Sync(finalCtx); err != nil {
+				log.Printf("final rtp state sync failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				log.Printf("rtp state sync failed: %v", err)
+			}
+		}
+	}
+}
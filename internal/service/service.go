@@ -2,27 +2,216 @@ package service
 
 import (
 	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/pkg/rng"
 	"context"
+	"errors"
+	"fmt"
+	"time"
 )
 
+var (
+	// ErrInvalidClientSeed возвращается, если клиентский сид пустой или слишком длинный
+	ErrInvalidClientSeed = errors.New("client seed must be 1-64 characters")
+	// ErrInvalidVerifyRequest возвращается, если параметры проверки спина не соответствуют игре
+	ErrInvalidVerifyRequest = errors.New("invalid verify request")
+	// ErrInvalidRTPOverride возвращается, если изменение регулятора RTP недопустимо для игры
+	ErrInvalidRTPOverride = errors.New("invalid rtp override")
+	// ErrInvalidRoleChange возвращается при неизвестной роли или попытке сменить роль самому себе
+	ErrInvalidRoleChange = errors.New("invalid role change")
+	// ErrRefreshTokenReused возвращается, если предъявлен уже заменённый refresh токен; сессия при этом удаляется
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrInvalidCredentials возвращается при неверном пароле и при неизвестном логине — одинаково,
+	// чтобы по ответу нельзя было узнать, существует ли аккаунт
+	ErrInvalidCredentials = errors.New("invalid login or password")
+	// ErrLoginLocked возвращается, пока вход заблокирован после серии неудачных попыток (см. LoginLockedError)
+	ErrLoginLocked = errors.New("too many login attempts")
+	// ErrInvalidPreAuthToken возвращается, если токен второго шага входа неверен или истёк
+	ErrInvalidPreAuthToken = errors.New("invalid pre-auth token")
+	// ErrInvalidTwoFactorCode возвращается при неверном, просроченном или уже использованном коде второго фактора
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorAlreadyEnabled возвращается при повторном подключении двухфакторной аутентификации
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrTwoFactorNotEnrolled возвращается при подтверждении или отключении, если секрет не выдавался
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication not enrolled")
+	// ErrTwoFactorRequired возвращается при попытке администратора отключить двухфакторную аутентификацию
+	ErrTwoFactorRequired = errors.New("two-factor authentication is required for this account")
+	// ErrWrongPassword возвращается при смене пароля, если текущий пароль указан неверно
+	ErrWrongPassword = errors.New("wrong password")
+	// ErrInvalidPassword возвращается, если новый пароль не подходит
+	ErrInvalidPassword = errors.New("invalid password")
+	// ErrInvalidResetToken возвращается, если токен сброса пароля неизвестен, истёк или уже использован
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	// ErrInvalidLogin возвращается при регистрации с недопустимым логином
+	ErrInvalidLogin = errors.New("invalid login")
+	// ErrInvalidName возвращается при регистрации с недопустимым именем
+	ErrInvalidName = errors.New("invalid name")
+	// ErrInvalidAmount возвращается, если сумма операции не положительна
+	ErrInvalidAmount = errors.New("amount must be positive")
+)
+
+// LoginLockedError вход временно заблокирован; RetryAfter — сколько ещё ждать
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLoginLocked, e.RetryAfter)
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
 type LineService interface {
 	Spin(ctx context.Context, spinReq model.LineSpin) (*model.SpinResult, error)
 	BuyBonus(ctx context.Context, bonusReq model.BonusSpin) (*model.BonusSpinResult, error)
+	VerifyBoard(req model.FairVerify) ([5][3]string, error)
 }
 
 type CascadeService interface {
 	Spin(ctx context.Context, req model.CascadeSpin) (*model.CascadeSpinResult, error)
 	BuyBonus(ctx context.Context, amount int) error
+	VerifyBoard(req model.FairVerify) ([7][7]int, error)
 }
 
 type AuthService interface {
-	Register(ctx context.Context, user *model.User) (*model.AuthData, error)
-	Login(ctx context.Context, user *model.User) (*model.AuthData, error)
-	Refresh(ctx context.Context, data *model.AuthData) (newAccessToken string, err error)
+	Register(ctx context.Context, user *model.User, client model.ClientInfo) (*model.AuthData, error)
+	Login(ctx context.Context, user *model.User, client model.ClientInfo) (*model.AuthData, error)
+	Refresh(ctx context.Context, data *model.AuthData, client model.ClientInfo) (*model.AuthData, error)
 	Logout(ctx context.Context, sessionID string) error
+	// LoginTwoFactor завершает вход с включённой двухфакторной аутентификацией
+	LoginTwoFactor(ctx context.Context, preAuthToken, code string, client model.ClientInfo) (*model.AuthData, error)
+	Sessions(ctx context.Context, userID int) ([]model.Session, error)
+	RevokeSession(ctx context.Context, userID int, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID int) error
 }
 
 type PaymentService interface {
 	Deposit(ctx context.Context, userID, amount int) error
+	Withdraw(ctx context.Context, userID, amount int) error
 	GetBalance(ctx context.Context, userID int) (int, error)
+	Transactions(ctx context.Context, userID int, cursor int64, limit int) (*model.TransactionPage, error)
+}
+
+type HistoryService interface {
+	Rounds(ctx context.Context, userID int, cursor int64, limit int) (*model.GameRoundPage, error)
+	Round(ctx context.Context, userID int, roundID string) (*model.GameRound, error)
+}
+
+type WalletService interface {
+	Debit(ctx context.Context, userID, amount int, txType model.TransactionType, roundID string) (balance int, err error)
+	Credit(ctx context.Context, userID, amount int, txType model.TransactionType, roundID string) (balance int, err error)
+}
+
+type FairnessService interface {
+	Seed(ctx context.Context, userID int) (*model.FairSeed, error)
+	SetClientSeed(ctx context.Context, userID int, clientSeed string) (*model.FairSeed, error)
+	Rotate(ctx context.Context, userID int) (*model.FairRotation, error)
+}
+
+// RNGProvider выдаёт генератор случайных чисел для очередного спина игрока.
+// Вне provably-fair режима proof равен nil
+type RNGProvider interface {
+	SpinRNG(ctx context.Context, userID int) (r rng.RNG, proof *model.FairnessProof, err error)
+}
+
+// RTPStateService синхронизирует состояние регуляторов RTP со снимком в БД
+type RTPStateService interface {
+	// Sync сливает изменения всех регуляторов со снимками в БД и загружает изменения других реплик
+	Sync(ctx context.Context) error
+	// SyncGame синхронизирует регуляторы всех сегментов одной игры.
+	// commit применяет результат к регуляторам в памяти; вызывать его только после коммита транзакции
+	SyncGame(ctx context.Context, game model.Game) (commit func(), err error)
+	// OverrideSegment записывает в снимок сегмента изменение администратора.
+	// commit применяет его к регулятору в памяти; вызывать его только после коммита транзакции
+	OverrideSegment(ctx context.Context, game model.Game, segment string, override repository.RTPOverride) (commit func(), err error)
+	// Run синхронизирует состояние с заданным интервалом, пока не отменён ctx, и выполняет финальную синхронизацию
+	Run(ctx context.Context)
+}
+
+// TwoFactorService двухфакторная аутентификация (TOTP) и коды восстановления
+type TwoFactorService interface {
+	// Enroll выдаёт новый секрет; он начинает действовать после Confirm
+	Enroll(ctx context.Context, userID int) (*model.TOTPEnrollment, error)
+	// Confirm включает двухфакторную аутентификацию по коду из приложения и возвращает коды восстановления.
+	// Сессия sessionID считается прошедшей второй фактор
+	Confirm(ctx context.Context, userID int, sessionID, code string) (recoveryCodes []string, err error)
+	Disable(ctx context.Context, userID int, code string) error
+	Enabled(ctx context.Context, userID int) (bool, error)
+	// Verify проверяет код из приложения или код восстановления; принятый код повторно не действует
+	Verify(ctx context.Context, userID int, code string) error
+}
+
+// LoginProtectionService защита от перебора паролей и кодов второго фактора:
+// неудачи считаются по логину и по IP, после бесплатных попыток вход блокируется с нарастающей паузой
+type LoginProtectionService interface {
+	// Check возвращает *LoginLockedError, если вход по логину или с IP заблокирован. Пустой ip не проверяется
+	Check(ctx context.Context, login, ip string) error
+	// RecordFailure учитывает неудачную попытку и при необходимости блокирует вход. Пустой ip не учитывается
+	RecordFailure(ctx context.Context, login, ip string) error
+	// Reset сбрасывает счётчик неудач по логину
+	Reset(ctx context.Context, login string) error
+}
+
+// PasswordService смена и сброс пароля
+type PasswordService interface {
+	// ChangePassword меняет пароль по текущему и завершает все сессии пользователя, кроме sessionID
+	ChangePassword(ctx context.Context, userID int, sessionID, oldPassword, newPassword string) error
+	// RequestReset отправляет пользователю токен сброса пароля в фоне. Для неизвестного логина ничего не делает
+	RequestReset(ctx context.Context, login string)
+	// ResetPassword задаёт новый пароль по токену сброса и завершает все сессии пользователя
+	ResetPassword(ctx context.Context, resetToken, newPassword string) error
+}
+
+// Notifier доставляет сообщения пользователю
+type Notifier interface {
+	Notify(ctx context.Context, msg model.Notification) error
+}
+
+// SessionCleanupService удаляет истекшие сессии
+type SessionCleanupService interface {
+	// Cleanup удаляет истекшие сессии и возвращает их количество
+	Cleanup(ctx context.Context) (deleted int64, err error)
+	// Run удаляет истекшие сессии с заданным интервалом, пока не отменён ctx
+	Run(ctx context.Context)
+}
+
+// IdempotencyCleanupService удаляет старые ключи идемпотентности
+type IdempotencyCleanupService interface {
+	// Cleanup удаляет старые ключи и возвращает их количество
+	Cleanup(ctx context.Context) (deleted int64, err error)
+	// Run удаляет старые ключи с заданным интервалом, пока не отменён ctx
+	Run(ctx context.Context)
+}
+
+// LoginAttemptCleanupService удаляет устаревшие счётчики неудачных попыток входа
+type LoginAttemptCleanupService interface {
+	// Cleanup удаляет счётчики без недавних неудач и действующей блокировки и возвращает их количество
+	Cleanup(ctx context.Context) (deleted int64, err error)
+	// Run удаляет устаревшие счётчики с заданным интервалом, пока не отменён ctx
+	Run(ctx context.Context)
+}
+
+// RateLimitCleanupService удаляет пополнившиеся корзины лимитов запросов
+type RateLimitCleanupService interface {
+	// Cleanup удаляет корзины, успевшие пополниться до конца, и возвращает их количество
+	Cleanup(ctx context.Context) (deleted int64, err error)
+	// Run удаляет корзины с заданным интервалом, пока не отменён ctx
+	Run(ctx context.Context)
+}
+
+// RTPAdminService просмотр и ручное управление регуляторами RTP
+type RTPAdminService interface {
+	State(ctx context.Context, game model.Game, segment string) (*model.RTPControllerState, error)
+	Override(ctx context.Context, override model.RTPOverride) error
+	AuditLog(ctx context.Context, game model.Game, cursor int64, limit int) (*model.RTPAuditPage, error)
+}
+
+// UserAdminService просмотр аккаунтов поддержкой и управление ролями
+type UserAdminService interface {
+	GetUser(ctx context.Context, userID int) (*model.User, error)
+	SetRole(ctx context.Context, actorID, userID int, role model.Role) error
+	// UnlockLogin снимает блокировку входа и сбрасывает счётчик неудачных попыток пользователя
+	UnlockLogin(ctx context.Context, actorID, userID int) error
 }
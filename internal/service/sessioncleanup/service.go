@@ -0,0 +1,54 @@
+package sessioncleanup
+
+import (
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.SessionCleanupService = (*serv)(nil)
+
+type serv struct {
+	authRepo repository.AuthRepository
+	interval time.Duration
+}
+
+// NewService Создать сервис очистки истекших сессий.
+// interval — период фонового удаления
+func NewService(authRepo repository.AuthRepository, interval time.Duration) *serv {
+	return &serv{
+		authRepo: authRepo,
+		interval: interval,
+	}
+}
+
+// Cleanup удаляет истекшие сессии. Их использованные refresh токены удаляются каскадно
+func (s *serv) Cleanup(ctx context.Context) (int64, error) {
+	return s.authRepo.DeleteExpiredSessions(ctx)
+}
+
+// Run фоновая очистка. Истекшие сессии и так не принимаются при проверке,
+// поэтому при остановке сервиса финальная очистка не нужна
+func (s *serv) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Cleanup(ctx)
+			if err != nil {
+				log.Printf("expired sessions cleanup failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d expired sessions", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package twofactor
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"casino_backend/pkg/totp"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+)
+
+// issuer Название сервиса в приложении-аутентификаторе
+const issuer = "Casino"
+
+// Проверка соответствия интерфейсу
+var _ service.TwoFactorService = (*serv)(nil)
+
+type serv struct {
+	txManager       trm.Manager
+	totpRepo        repository.TOTPRepository
+	userRepo        repository.UserRepository
+	authRepo        repository.AuthRepository
+	loginProtection service.LoginProtectionService
+}
+
+func NewService(
+	txManager trm.Manager,
+	totpRepo repository.TOTPRepository,
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	loginProtection service.LoginProtectionService,
+) *serv {
+	return &serv{
+		txManager:       txManager,
+		totpRepo:        totpRepo,
+		userRepo:        userRepo,
+		authRepo:        authRepo,
+		loginProtection: loginProtection,
+	}
+}
+
+// Enroll выдаёт новый секрет вместо неподтверждённого прежнего
+func (s *serv) Enroll(ctx context.Context, userID int) (*model.TOTPEnrollment, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.Enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, service.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.totpRepo.SavePendingTOTP(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(issuer, user.Login, secret),
+	}, nil
+}
+
+// Confirm включает двухфакторную аутентификацию, если код сходится с выданным секретом.
+// Текущая сессия сразу считается прошедшей второй фактор: новый access токен — после /auth/refresh.
+// Остальные сессии завершаются: они открыты без второго фактора и иначе могли бы его отключить
+func (s *serv) Confirm(ctx context.Context, userID int, sessionID, code string) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context) error {
+		credentials, err := s.totpRepo.GetTOTP(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return service.ErrTwoFactorNotEnrolled
+			}
+			return err
+		}
+		if credentials.Enabled {
+			return service.ErrTwoFactorAlreadyEnabled
+		}
+
+		step, ok := totp.Validate(credentials.Secret, normalizeCode(code), time.Now())
+		if !ok {
+			return service.ErrInvalidTwoFactorCode
+		}
+
+		if err = s.totpRepo.EnableTOTP(ctx, userID, step); err != nil {
+			return err
+		}
+		if err = s.totpRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+			return err
+		}
+		if err = s.authRepo.SetSessionTwoFactor(ctx, sessionID); err != nil {
+			return err
+		}
+		return s.authRepo.DeleteOtherUserSessions(ctx, userID, sessionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("user %d enabled two-factor authentication", userID)
+	return codes, nil
+}
+
+// Disable отключает двухфакторную аутентификацию по действующему коду.
+// Неверные коды учитываются вместе с неудачными входами и так же приводят к блокировке.
+// Администраторам отключать её нельзя
+func (s *serv) Disable(ctx context.Context, userID int, code string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Role == model.RoleAdmin {
+		return service.ErrTwoFactorRequired
+	}
+	if err = s.loginProtection.Check(ctx, user.Login, ""); err != nil {
+		return err
+	}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.Verify(ctx, userID, code); err != nil {
+			return err
+		}
+		return s.totpRepo.DeleteTOTP(ctx, userID)
+	})
+	if err != nil {
+		// Неудача учитывается вне транзакции, иначе её откатило бы вместе с ней
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			if err := s.loginProtection.RecordFailure(ctx, user.Login, ""); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	log.Printf("user %d disabled two-factor authentication", userID)
+	return nil
+}
+
+// Enabled включена ли двухфакторная аутентификация (выданный, но не подтверждённый секрет не считается)
+func (s *serv) Enabled(ctx context.Context, userID int) (bool, error) {
+	credentials, err := s.totpRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return credentials.Enabled, nil
+}
+
+// Verify принимает код из приложения (6 цифр) или код восстановления
+func (s *serv) Verify(ctx context.Context, userID int, code string) error {
+	credentials, err := s.totpRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return service.ErrTwoFactorNotEnrolled
+		}
+		return err
+	}
+	if !credentials.Enabled {
+		return service.ErrTwoFactorNotEnrolled
+	}
+
+	code = normalizeCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(credentials.Secret, code, time.Now())
+		if !ok {
+			return service.ErrInvalidTwoFactorCode
+		}
+		used, err := s.totpRepo.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return service.ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.totpRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return service.ErrInvalidTwoFactorCode
+	}
+	log.Printf("user %d used a recovery code", userID)
+	return nil
+}
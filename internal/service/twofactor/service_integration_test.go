@@ -0,0 +1,85 @@
+package twofactor
+
+import (
+	"casino_backend/internal/pgtest"
+	"casino_backend/internal/repository/totp_repo"
+	"casino_backend/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+)
+
+// TestUseTOTPStepRejectsReplay один и тот же код не принимается дважды: условие last_step < step в UPDATE
+func TestUseTOTPStepRejectsReplay(t *testing.T) {
+	pool := pgtest.New(t)
+	repo := totp_repo.NewTOTPRepository(pool, trmpgx.DefaultCtxGetter)
+	s := NewService(nil, repo, nil, nil, nil)
+	userID := pgtest.CreateUser(t, pool, 0)
+	ctx := context.Background()
+
+	if err := repo.SavePendingTOTP(ctx, userID, testSecret); err != nil {
+		t.Fatalf("SavePendingTOTP: %v", err)
+	}
+	if err := repo.EnableTOTP(ctx, userID, 0); err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+
+	code := testCode(t, testSecret, time.Now())
+	if err := s.Verify(ctx, userID, code); err != nil {
+		t.Fatalf("first Verify: %v", err)
+	}
+	if err := s.Verify(ctx, userID, code); !errors.Is(err, service.ErrInvalidTwoFactorCode) {
+		t.Fatalf("replayed Verify = %v, want %v", err, service.ErrInvalidTwoFactorCode)
+	}
+
+	step := time.Now().Unix() / 30
+	for _, tt := range []struct {
+		step int64
+		want bool
+	}{
+		{step - 1, false},
+		{step + 1, true},
+		{step + 1, false},
+	} {
+		used, err := repo.UseTOTPStep(ctx, userID, tt.step)
+		if err != nil {
+			t.Fatalf("UseTOTPStep(%d): %v", tt.step, err)
+		}
+		if used != tt.want {
+			t.Fatalf("UseTOTPStep(%d) = %v, want %v", tt.step, used, tt.want)
+		}
+	}
+}
+
+// TestUseRecoveryCodeOnce код восстановления удаляется при использовании
+func TestUseRecoveryCodeOnce(t *testing.T) {
+	pool := pgtest.New(t)
+	repo := totp_repo.NewTOTPRepository(pool, trmpgx.DefaultCtxGetter)
+	s := NewService(nil, repo, nil, nil, nil)
+	userID := pgtest.CreateUser(t, pool, 0)
+	ctx := context.Background()
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+	if err = repo.SavePendingTOTP(ctx, userID, testSecret); err != nil {
+		t.Fatalf("SavePendingTOTP: %v", err)
+	}
+	if err = repo.EnableTOTP(ctx, userID, 0); err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+	if err = repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		t.Fatalf("ReplaceRecoveryCodes: %v", err)
+	}
+
+	if err = s.Verify(ctx, userID, codes[0]); err != nil {
+		t.Fatalf("Verify(%q): %v", codes[0], err)
+	}
+	if err = s.Verify(ctx, userID, codes[0]); !errors.Is(err, service.ErrInvalidTwoFactorCode) {
+		t.Fatalf("reused recovery code = %v, want %v", err, service.ErrInvalidTwoFactorCode)
+	}
+}
@@ -0,0 +1,159 @@
+package twofactor
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testUserID = 1
+
+// fakeTOTPRepo TOTPRepository в памяти с теми же условиями, что и запросы totp_repo
+type fakeTOTPRepo struct {
+	totp          model.TOTP
+	recoveryCodes map[string]bool
+}
+
+func newFakeTOTPRepo(secret string, hashes []string) *fakeTOTPRepo {
+	r := &fakeTOTPRepo{
+		totp:          model.TOTP{UserID: testUserID, Secret: secret, Enabled: true},
+		recoveryCodes: make(map[string]bool),
+	}
+	for _, h := range hashes {
+		r.recoveryCodes[h] = true
+	}
+	return r
+}
+
+func (r *fakeTOTPRepo) GetTOTP(_ context.Context, userID int) (*model.TOTP, error) {
+	if userID != r.totp.UserID {
+		return nil, repository.ErrNotFound
+	}
+	t := r.totp
+	return &t, nil
+}
+
+func (r *fakeTOTPRepo) SavePendingTOTP(context.Context, int, string) error { return nil }
+func (r *fakeTOTPRepo) EnableTOTP(context.Context, int, int64) error       { return nil }
+func (r *fakeTOTPRepo) DeleteTOTP(context.Context, int) error              { return nil }
+func (r *fakeTOTPRepo) ReplaceRecoveryCodes(context.Context, int, []string) error {
+	return nil
+}
+
+// UseTOTPStep как в totp_repo: UPDATE ... WHERE enabled AND last_step < step
+func (r *fakeTOTPRepo) UseTOTPStep(_ context.Context, userID int, step int64) (bool, error) {
+	if userID != r.totp.UserID || !r.totp.Enabled || r.totp.LastStep >= step {
+		return false, nil
+	}
+	r.totp.LastStep = step
+	return true, nil
+}
+
+// UseRecoveryCode как в totp_repo: DELETE ... WHERE code_hash = $1
+func (r *fakeTOTPRepo) UseRecoveryCode(_ context.Context, userID int, codeHash string) (bool, error) {
+	if userID != r.totp.UserID || !r.recoveryCodes[codeHash] {
+		return false, nil
+	}
+	delete(r.recoveryCodes, codeHash)
+	return true, nil
+}
+
+// testCode код из приложения-аутентификатора на момент t (HOTP по RFC 4226, как в pkg/totp)
+func testCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+const testSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestVerifyRejectsReplayedCode(t *testing.T) {
+	repo := newFakeTOTPRepo(testSecret, nil)
+	s := NewService(nil, repo, nil, nil, nil)
+	ctx := context.Background()
+	code := testCode(t, testSecret, time.Now())
+
+	if err := s.Verify(ctx, testUserID, code); err != nil {
+		t.Fatalf("first Verify: %v", err)
+	}
+	if err := s.Verify(ctx, testUserID, code); !errors.Is(err, service.ErrInvalidTwoFactorCode) {
+		t.Fatalf("replayed Verify = %v, want %v", err, service.ErrInvalidTwoFactorCode)
+	}
+
+	// Код предыдущего периода ещё в допуске по времени, но старше принятого — тоже отклоняется
+	previous := testCode(t, testSecret, time.Now().Add(-30*time.Second))
+	if previous != code {
+		if err := s.Verify(ctx, testUserID, previous); !errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			t.Fatalf("Verify with an older step = %v, want %v", err, service.ErrInvalidTwoFactorCode)
+		}
+	}
+}
+
+func TestVerifyRecoveryCode(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	repo := newFakeTOTPRepo(testSecret, hashes)
+	s := NewService(nil, repo, nil, nil, nil)
+	ctx := context.Background()
+
+	// Код вводят в верхнем регистре, с пробелами и без дефиса
+	typed := " " + strings.ToUpper(strings.Replace(codes[0], "-", " ", 1)) + " "
+	if err := s.Verify(ctx, testUserID, typed); err != nil {
+		t.Fatalf("Verify(%q): %v", typed, err)
+	}
+
+	// Код одноразовый в любом написании
+	if err := s.Verify(ctx, testUserID, codes[0]); !errors.Is(err, service.ErrInvalidTwoFactorCode) {
+		t.Fatalf("reused recovery code = %v, want %v", err, service.ErrInvalidTwoFactorCode)
+	}
+
+	// Остальные коды остаются действующими
+	if err := s.Verify(ctx, testUserID, codes[1]); err != nil {
+		t.Fatalf("Verify(%q): %v", codes[1], err)
+	}
+	if len(repo.recoveryCodes) != recoveryCodeCount-2 {
+		t.Fatalf("%d recovery codes left, want %d", len(repo.recoveryCodes), recoveryCodeCount-2)
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"abcd-efgh", "abcdefgh"},
+		{" ABCD EFGH ", "abcdefgh"},
+		{"Ab-Cd Ef-Gh", "abcdefgh"},
+		{"123 456", "123456"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeCode(tt.in); got != tt.want {
+			t.Errorf("normalizeCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package useradmin
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.UserAdminService = (*serv)(nil)
+
+type serv struct {
+	txManager   trm.Manager
+	userRepo    repository.UserRepository
+	authRepo    repository.AuthRepository
+	attemptRepo repository.LoginAttemptRepository
+}
+
+func NewService(
+	txManager trm.Manager,
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	attemptRepo repository.LoginAttemptRepository,
+) *serv {
+	return &serv{
+		txManager:   txManager,
+		userRepo:    userRepo,
+		authRepo:    authRepo,
+		attemptRepo: attemptRepo,
+	}
+}
+
+// GetUser возвращает аккаунт пользователя (без хэша пароля)
+func (s *serv) GetUser(ctx context.Context, userID int) (*model.User, error) {
+	return s.userRepo.GetUserByID(ctx, userID)
+}
+
+// SetRole меняет роль пользователя и завершает все его сессии:
+// роль зашита в access токен, поэтому старые токены не должны продолжать действовать.
+// Сменить роль самому себе нельзя, чтобы администратор случайно не лишился доступа
+func (s *serv) SetRole(ctx context.Context, actorID, userID int, role model.Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("%w: unknown role %q", service.ErrInvalidRoleChange, role)
+	}
+	if actorID == userID {
+		return fmt.Errorf("%w: cannot change own role", service.ErrInvalidRoleChange)
+	}
+
+	err := s.txManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.SetRole(ctx, userID, role); err != nil {
+			return err
+		}
+		return s.authRepo.DeleteUserSessions(ctx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("user %d set role of user %d to %s", actorID, userID, role)
+	return nil
+}
+
+// UnlockLogin снимает блокировку входа по логину пользователя.
+// Блокировки по IP не снимаются: они не привязаны к аккаунту и истекают сами
+func (s *serv) UnlockLogin(ctx context.Context, actorID, userID int) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.attemptRepo.Reset(ctx, model.LoginAttemptKey(user.Login)); err != nil {
+		return err
+	}
+
+	log.Printf("user %d unlocked login of user %d", actorID, userID)
+	return nil
+}
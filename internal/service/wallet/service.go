@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/service"
+	"context"
+	"errors"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+)
+
+// Проверка соответствия интерфейсу
+var _ service.WalletService = (*serv)(nil)
+
+type serv struct {
+	txManager       trm.Manager
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+}
+
+func NewService(
+	txManager trm.Manager,
+	userRepo repository.UserRepository,
+	transactionRepo repository.TransactionRepository,
+) *serv {
+	return &serv{
+		txManager:       txManager,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// Debit списывает amount с кошелька игрока и записывает операцию в журнал.
+// Возвращает баланс после списания
+func (s *serv) Debit(ctx context.Context, userID, amount int, txType model.TransactionType, roundID string) (int, error) {
+	if amount <= 0 {
+		return 0, errors.New("amount must be positive")
+	}
+
+	var balance int
+	// Если вызов уже внутри транзакции, txManager присоединится к ней
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		var err error
+		balance, err = s.userRepo.Debit(txCtx, userID, amount)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.transactionRepo.CreateTransaction(txCtx, &model.Transaction{
+			UserID:        userID,
+			Type:          txType,
+			DebitAccount:  model.UserAccount(userID),
+			CreditAccount: counterAccount(txType),
+			Amount:        amount,
+			BalanceAfter:  balance,
+			RoundID:       roundID,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// Credit начисляет amount на кошелёк игрока и записывает операцию в журнал.
+// Возвращает баланс после начисления
+func (s *serv) Credit(ctx context.Context, userID, amount int, txType model.TransactionType, roundID string) (int, error) {
+	if amount <= 0 {
+		return 0, errors.New("amount must be positive")
+	}
+
+	var balance int
+	// Если вызов уже внутри транзакции, txManager присоединится к ней
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		var err error
+		balance, err = s.userRepo.Credit(txCtx, userID, amount)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.transactionRepo.CreateTransaction(txCtx, &model.Transaction{
+			UserID:        userID,
+			Type:          txType,
+			DebitAccount:  counterAccount(txType),
+			CreditAccount: model.UserAccount(userID),
+			Amount:        amount,
+			BalanceAfter:  balance,
+			RoundID:       roundID,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// counterAccount возвращает встречный счёт для операции с кошельком игрока:
+// пополнения и выводы проходят через платёжный шлюз, остальное — через кассу казино
+func counterAccount(txType model.TransactionType) string {
+	switch txType {
+	case model.TransactionDeposit, model.TransactionWithdraw:
+		return model.CashierAccount
+	}
+	return model.HouseAccount
+}
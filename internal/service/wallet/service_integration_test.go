@@ -0,0 +1,116 @@
+package wallet
+
+import (
+	"casino_backend/internal/model"
+	"casino_backend/internal/pgtest"
+	"casino_backend/internal/repository"
+	"casino_backend/internal/repository/transaction_repo"
+	"casino_backend/internal/repository/user_repo"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+)
+
+// TestConcurrentDebitCredit параллельные списания и начисления одному игроку:
+// итоговый баланс сходится с успешными операциями, а баланс ни разу не уходит в минус
+func TestConcurrentDebitCredit(t *testing.T) {
+	const (
+		startBalance = 500
+		debits       = 60
+		debitAmount  = 25
+		credits      = 20
+		creditAmount = 10
+	)
+
+	pool := pgtest.New(t)
+	userRepo := user_repo.NewUserRepository(pool, trmpgx.DefaultCtxGetter)
+	s := NewService(
+		manager.Must(trmpgx.NewDefaultFactory(pool)),
+		userRepo,
+		transaction_repo.NewTransactionRepository(pool, trmpgx.DefaultCtxGetter),
+	)
+	userID := pgtest.CreateUser(t, pool, startBalance)
+	ctx := context.Background()
+
+	var (
+		wg              sync.WaitGroup
+		okDebits        atomic.Int64
+		rejectedDebits  atomic.Int64
+		negativeBalance atomic.Int64
+		unexpected      = make(chan error, debits+credits)
+	)
+
+	for i := 0; i < debits+credits; i++ {
+		wg.Add(1)
+		go func(debit bool) {
+			defer wg.Done()
+
+			var (
+				balance int
+				err     error
+			)
+			if debit {
+				balance, err = s.Debit(ctx, userID, debitAmount, model.TransactionBet, "")
+			} else {
+				balance, err = s.Credit(ctx, userID, creditAmount, model.TransactionWin, "")
+			}
+
+			switch {
+			case debit && errors.Is(err, repository.ErrNotEnoughBalance):
+				rejectedDebits.Add(1)
+				return
+			case err != nil:
+				unexpected <- err
+				return
+			case debit:
+				okDebits.Add(1)
+			}
+			if balance < 0 {
+				negativeBalance.Add(1)
+			}
+		}(i < debits)
+	}
+	wg.Wait()
+	close(unexpected)
+
+	for err := range unexpected {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n := negativeBalance.Load(); n > 0 {
+		t.Errorf("%d operations returned a negative balance", n)
+	}
+	// Ставок больше, чем денег: часть списаний обязана упереться в проверку баланса
+	if rejectedDebits.Load() == 0 {
+		t.Errorf("no debit was rejected, the test did not exercise the balance guard")
+	}
+
+	want := startBalance - int(okDebits.Load())*debitAmount + credits*creditAmount
+
+	balance, err := userRepo.GetBalance(ctx, userID)
+	if err != nil {
+		t.Fatalf("get balance: %v", err)
+	}
+	if balance != want {
+		t.Fatalf("final balance = %d, want %d (%d debits of %d ok, %d credits of %d)",
+			balance, want, okDebits.Load(), debitAmount, credits, creditAmount)
+	}
+
+	// Журнал операций сходится с балансом
+	var journal int
+	err = pool.QueryRow(ctx, `
+		SELECT coalesce(sum(CASE WHEN credit_account = $1 THEN amount ELSE -amount END), 0)
+		FROM transactions WHERE user_id = $2`,
+		model.UserAccount(userID), userID,
+	).Scan(&journal)
+	if err != nil {
+		t.Fatalf("sum transactions: %v", err)
+	}
+	if startBalance+journal != balance {
+		t.Fatalf("journal sums to %d, balance changed by %d", journal, balance-startBalance)
+	}
+}
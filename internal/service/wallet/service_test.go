@@ -0,0 +1,26 @@
+package wallet
+
+import (
+	"casino_backend/internal/model"
+	"testing"
+)
+
+func TestCounterAccount(t *testing.T) {
+	tests := []struct {
+		txType model.TransactionType
+		want   string
+	}{
+		{model.TransactionDeposit, model.CashierAccount},
+		{model.TransactionWithdraw, model.CashierAccount},
+		{model.TransactionBet, model.HouseAccount},
+		{model.TransactionWin, model.HouseAccount},
+		{model.TransactionBonusBuy, model.HouseAccount},
+		{model.TransactionAdjustment, model.HouseAccount},
+	}
+
+	for _, tt := range tests {
+		if got := counterAccount(tt.txType); got != tt.want {
+			t.Errorf("counterAccount(%s) = %s, want %s", tt.txType, got, tt.want)
+		}
+	}
+}
@@ -1,29 +1,56 @@
+-- Схема БД. Файл можно применять повторно: новые таблицы создаются, если их нет,
+-- а базы, созданные прежней версией схемы, обновляются в конце файла
+
 -- 1. Пользователи (login/password/balance без jwt_token)
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
                        id SERIAL PRIMARY KEY,
                        name TEXT NOT NULL,
                        login VARCHAR(50) UNIQUE NOT NULL,
                        password_hash VARCHAR(255) NOT NULL,
     -- баланс в центах/копейках
-                       balance BIGINT NOT NULL DEFAULT 0
+                       balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    -- роль: player, support или admin. Первого администратора назначают вручную:
+    -- UPDATE users SET role = 'admin' WHERE login = '...';
+                       role VARCHAR(16) NOT NULL DEFAULT 'player' CHECK (role IN ('player', 'support', 'admin'))
 );
 
--- Новая таблица для сессий
-CREATE TABLE sessions (
+-- Новая таблица для сессий.
+-- Здесь и ниже время хранится как TIMESTAMPTZ: expired_time задаётся из кода (time.Now() приложения),
+-- а сравнивается с now() БД, и без часового пояса результат зависел бы от настроек TZ сервера и базы
+CREATE TABLE IF NOT EXISTS sessions (
                           session_id TEXT PRIMARY KEY,  -- Задается из кода, не SERIAL
                           user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
                           refresh_hash TEXT NOT NULL,
-                          expired_time TIMESTAMP NOT NULL
+                          expired_time TIMESTAMPTZ NOT NULL,
+                          -- Откуда выполнен вход: показывается игроку в списке его сессий
+                          device TEXT NOT NULL DEFAULT '',
+                          ip TEXT NOT NULL DEFAULT '',
+                          user_agent TEXT NOT NULL DEFAULT '',
+                          created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                          last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                          two_factor BOOLEAN NOT NULL DEFAULT false  -- При входе пройдена двухфакторная аутентификация
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+-- Для фонового удаления истекших сессий
+CREATE INDEX IF NOT EXISTS sessions_expired_time_idx ON sessions (expired_time);
+
+-- Refresh токены, заменённые при ротации. Повторное предъявление такого токена — признак кражи:
+-- сессия, к которой он относится, удаляется целиком (вместе со всеми её токенами)
+CREATE TABLE IF NOT EXISTS used_refresh_tokens (
+                                     refresh_hash TEXT PRIMARY KEY,
+                                     session_id TEXT NOT NULL REFERENCES sessions(session_id) ON DELETE CASCADE,
+                                     rotated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
 -- 2. Состояние игры «Line Slots» (обычные 5x3 слоты)
-CREATE TABLE line_game_state (
+CREATE TABLE IF NOT EXISTS line_game_state (
                                  user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
                                  free_spins_count INT NOT NULL DEFAULT 0
 );
 
 -- 3. Состояние игры «Sugar Rush» (cascade-механика с множителями 7x7)
-CREATE TABLE sugar_rush_state (
+CREATE TABLE IF NOT EXISTS sugar_rush_state (
                                   user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
                                   free_spins_count INT NOT NULL DEFAULT 0,
 
@@ -31,3 +58,191 @@ CREATE TABLE sugar_rush_state (
                                   multipliers JSONB NOT NULL DEFAULT '[[1,1,1,1,1,1,1],[1,1,1,1,1,1,1],[1,1,1,1,1,1,1],[1,1,1,1,1,1,1],[1,1,1,1,1,1,1],[1,1,1,1,1,1,1],[1,1,1,1,1,1,1]]'::jsonb,
                                   hits JSONB NOT NULL DEFAULT '[[0,0,0,0,0,0,0],[0,0,0,0,0,0,0],[0,0,0,0,0,0,0],[0,0,0,0,0,0,0],[0,0,0,0,0,0,0],[0,0,0,0,0,0,0],[0,0,0,0,0,0,0]]'::jsonb
 );
+
+-- 4. Кошелёк: журнал операций по балансу.
+-- Двойная запись: каждая операция переводит amount со счёта debit_account на счёт credit_account
+-- (счета: user:<id> — кошелёк игрока, house — касса казино, cashier — платёжный шлюз)
+CREATE TABLE IF NOT EXISTS transactions (
+                              id BIGSERIAL PRIMARY KEY,
+                              user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                              type VARCHAR(20) NOT NULL CHECK (type IN ('bet', 'win', 'deposit', 'bonus_buy', 'adjustment', 'withdraw')),
+                              debit_account TEXT NOT NULL,
+                              credit_account TEXT NOT NULL,
+                              amount BIGINT NOT NULL CHECK (amount > 0),
+    -- баланс игрока после операции
+                              balance_after BIGINT NOT NULL,
+    -- ID игрового раунда, к которому относится операция (NULL для депозитов и корректировок)
+                              round_id TEXT,
+                              created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS transactions_user_id_id_idx ON transactions (user_id, id DESC);
+
+-- 5. Ключи идемпотентности: первый ответ на запрос с Idempotency-Key сохраняется и отдаётся на повторы
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+                                  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                  idempotency_key VARCHAR(255) NOT NULL,
+    -- sha256 от метода, пути и тела запроса: повтор с другим телом считается конфликтом
+                                  request_hash TEXT NOT NULL,
+    -- NULL, пока первый запрос ещё обрабатывается
+                                  status_code INT,
+                                  content_type TEXT,
+                                  response_body BYTEA,
+                                  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- когда запрос взят в обработку: резерв без ответа старше IDEMPOTENCY_LOCK_TIMEOUT считается брошенным
+                                  locked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                  PRIMARY KEY (user_id, idempotency_key)
+);
+
+-- Для фонового удаления ключей старше IDEMPOTENCY_KEY_TTL
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+
+-- 6. История игровых раундов (для повтора анимации и разбора спорных ситуаций)
+CREATE TABLE IF NOT EXISTS game_rounds (
+                             id TEXT PRIMARY KEY,  -- ID раунда, задается из кода (на него же ссылаются transactions.round_id)
+                             seq BIGSERIAL NOT NULL UNIQUE,  -- порядок раундов для курсорной пагинации
+                             user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                             game VARCHAR(20) NOT NULL CHECK (game IN ('line', 'cascade')),
+                             bet BIGINT NOT NULL,
+                             payout BIGINT NOT NULL,
+                             in_free_spin BOOLEAN NOT NULL DEFAULT false,
+                             bonus_buy BOOLEAN NOT NULL DEFAULT false,
+    -- пресет / конфиг RTP, на котором сыгран раунд
+                             config_index INT NOT NULL,
+                             config_name TEXT NOT NULL DEFAULT '',
+    -- полный результат спина: доска, выигрышные линии / каскады с кластерами и новыми символами
+                             result JSONB NOT NULL,
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS game_rounds_user_id_seq_idx ON game_rounds (user_id, seq DESC);
+
+-- 7. Provably-fair: активная пара сидов игрока.
+-- Серверный сид не раскрывается, пока его не заменят ротацией; игроку заранее публикуется его SHA-256
+CREATE TABLE IF NOT EXISTS fair_seeds (
+                            user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                            server_seed TEXT NOT NULL,
+                            server_seed_hash TEXT NOT NULL,
+                            client_seed TEXT NOT NULL,
+    -- количество спинов, сыгранных на этой паре сидов (nonce последнего спина)
+                            nonce INT NOT NULL DEFAULT 0,
+                            created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- 8. Раскрытые после ротации пары сидов
+CREATE TABLE IF NOT EXISTS fair_seeds_revealed (
+                                     id BIGSERIAL PRIMARY KEY,
+                                     user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                     server_seed TEXT NOT NULL,
+                                     server_seed_hash TEXT NOT NULL,
+                                     client_seed TEXT NOT NULL,
+                                     nonce INT NOT NULL,
+                                     created_at TIMESTAMPTZ NOT NULL,
+                                     revealed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS fair_seeds_revealed_user_id_idx ON fair_seeds_revealed (user_id, id DESC);
+
+-- 9. Снимок состояния регуляторов RTP (line, cascade) по сегментам игроков — общий для всех реплик бэкенда.
+-- state пуст, пока первая реплика не запишет снимок
+CREATE TABLE IF NOT EXISTS rtp_controller_state (
+                                      game VARCHAR(20) NOT NULL CHECK (game IN ('line', 'cascade')),
+                                      segment VARCHAR(32) NOT NULL DEFAULT 'default',
+                                      state JSONB,
+                                      updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                      PRIMARY KEY (game, segment)
+);
+
+-- 10. Журнал изменений регуляторов RTP администраторами
+CREATE TABLE IF NOT EXISTS rtp_audit_log (
+                               id BIGSERIAL PRIMARY KEY,
+                               game VARCHAR(20) NOT NULL CHECK (game IN ('line', 'cascade')),
+                               segment VARCHAR(32) NOT NULL DEFAULT 'default',
+                               action VARCHAR(32) NOT NULL,
+                               old_value JSONB NOT NULL,
+                               new_value JSONB NOT NULL,
+                               actor TEXT NOT NULL,
+                               created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS rtp_audit_log_game_id_idx ON rtp_audit_log (game, id DESC);
+
+-- 11. Неудачные попытки входа по логину (key = 'login:<login>') и по IP (key = 'ip:<ip>').
+-- Общие для всех реплик: блокировка действует, на какую бы реплику ни пришёл запрос.
+-- Счётчик сбрасывается после успешного входа или разблокировки поддержкой, а также после долгой паузы
+CREATE TABLE IF NOT EXISTS login_attempts (
+                                key TEXT PRIMARY KEY,
+                                failures INT NOT NULL DEFAULT 0,
+                                last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                locked_until TIMESTAMPTZ
+);
+
+-- 12. Корзины token bucket для ограничения частоты запросов (при RATE_LIMIT_STORE=postgres).
+-- key = '<маршрут>:user:<id>' или '<маршрут>:ip:<ip>'; tokens пополняются по времени с updated_at
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+                                    key TEXT PRIMARY KEY,
+                                    tokens DOUBLE PRECISION NOT NULL,
+                                    allowed BOOLEAN NOT NULL,  -- Был ли выдан токен на последний запрос
+                                    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- 13. Двухфакторная аутентификация (TOTP). Пока enabled = false, секрет выдан, но не подтверждён кодом.
+-- last_step — период последнего принятого кода: один и тот же код нельзя использовать дважды
+CREATE TABLE IF NOT EXISTS totp_credentials (
+                                  user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                                  secret TEXT NOT NULL,
+                                  enabled BOOLEAN NOT NULL DEFAULT false,
+                                  last_step BIGINT NOT NULL DEFAULT 0,
+                                  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- 14. Одноразовые коды восстановления на случай потери телефона (хранятся SHA-256 хэши)
+CREATE TABLE IF NOT EXISTS totp_recovery_codes (
+                                     user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                     code_hash TEXT NOT NULL,
+                                     PRIMARY KEY (user_id, code_hash)
+);
+
+-- 15. Токены сброса пароля (хранятся SHA-256 хэши). Токен одноразовый: после сброса заполняется used_at
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+                                       token_hash TEXT PRIMARY KEY,
+                                       user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                       expires_at TIMESTAMPTZ NOT NULL,
+                                       used_at TIMESTAMPTZ,
+                                       created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
+
+-- 16. Обновление баз, созданных прежней версией схемы (users и sessions без новых колонок, TIMESTAMP вместо TIMESTAMPTZ).
+-- На свежей базе ничего не меняет
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'player' CHECK (role IN ('player', 'support', 'admin'));
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = 'users'::regclass AND conname = 'users_balance_check') THEN
+        ALTER TABLE users ADD CONSTRAINT users_balance_check CHECK (balance >= 0);
+    END IF;
+END $$;
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS device TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS ip TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS two_factor BOOLEAN NOT NULL DEFAULT false;
+
+-- Прежняя версия записывала в expired_time время приложения без часового пояса; контейнеры работают в UTC.
+-- Тип меняется только у колонки TIMESTAMP: повторное преобразование TIMESTAMPTZ сдвинуло бы время
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'sessions'
+          AND column_name = 'expired_time' AND data_type = 'timestamp without time zone'
+    ) THEN
+        ALTER TABLE sessions ALTER COLUMN expired_time TYPE TIMESTAMPTZ USING expired_time AT TIME ZONE 'UTC';
+    END IF;
+END $$;
@@ -1,11 +1,60 @@
 package pass
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// Алгоритмы хэширования паролей
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// Параметры argon2id (рекомендация OWASP для 64 MiB памяти)
+const (
+	argon2Memory  = 64 * 1024
+	argon2Time    = 3
+	argon2Threads = 2
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+var ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+
+// Hasher хэширует пароли текущим алгоритмом и определяет устаревшие хэши
+type Hasher struct {
+	algorithm  string
+	bcryptCost int
+}
+
+func NewHasher(algorithm string, bcryptCost int) (*Hasher, error) {
+	switch algorithm {
+	case AlgorithmBcrypt:
+		if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case AlgorithmArgon2id:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, algorithm)
+	}
+
+	return &Hasher{algorithm: algorithm, bcryptCost: bcryptCost}, nil
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmArgon2id {
+		return hashArgon2id(password)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
 	if err != nil {
 		return "", err
 	}
@@ -13,7 +62,93 @@ func HashPassword(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
+// NeedsRehash true, если хэш получен другим алгоритмом или с другими параметрами
+func (h *Hasher) NeedsRehash(hashedPassword string) bool {
+	if h.algorithm == AlgorithmArgon2id {
+		params, _, _, err := decodeArgon2id(hashedPassword)
+		return err != nil || params != currentArgon2Params
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != h.bcryptCost
+}
+
+// VerifyPassword сверяет пароль с хэшем любого из поддерживаемых алгоритмов
 func VerifyPassword(hashedPassword, candidatePassword string) bool {
+	if strings.HasPrefix(hashedPassword, "$"+AlgorithmArgon2id+"$") {
+		return verifyArgon2id(hashedPassword, candidatePassword)
+	}
+
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(candidatePassword))
 	return err == nil
 }
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+var currentArgon2Params = argon2Params{memory: argon2Memory, time: argon2Time, threads: argon2Threads}
+
+// hashArgon2id возвращает хэш в формате PHC: $argon2id$v=19$m=65536,t=3,p=2$<соль>$<ключ>
+func hashArgon2id(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := currentArgon2Params
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, argon2KeyLen)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func verifyArgon2id(hashedPassword, candidatePassword string) bool {
+	p, salt, key, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return false
+	}
+
+	candidateKey := argon2.IDKey([]byte(candidatePassword), salt, p.time, p.memory, p.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidateKey) == 1
+}
+
+func decodeArgon2id(hashedPassword string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return p, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, err
+	}
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	if len(key) == 0 {
+		return p, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,77 @@
+package pass
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength Больше bcrypt не учитывает: всё после 72 байт отбрасывается
+const MaxLength = 72
+
+var (
+	ErrTooShort       = errors.New("password is too short")
+	ErrTooLong        = fmt.Errorf("password is longer than %d bytes", MaxLength)
+	ErrBreached       = errors.New("password is too common or has appeared in a data breach")
+	ErrContainsLogin  = errors.New("password must not contain the login")
+	ErrInvalidUnicode = errors.New("password is not valid UTF-8")
+)
+
+// Policy требования к новым паролям. На вход с уже заданным паролем не влияет
+type Policy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+// NewPolicy breachedListFile — файл со скомпрометированными паролями, по одному в строке;
+// пустой путь — без проверки по списку
+func NewPolicy(minLength int, breachedListFile string) (*Policy, error) {
+	p := &Policy{minLength: minLength, breached: map[string]struct{}{}}
+	if breachedListFile == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(breachedListFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			p.breached[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+
+	return p, nil
+}
+
+// Validate проверяет пароль пользователя с логином login. Длина считается в символах, предел — в байтах
+func (p *Policy) Validate(login, password string) error {
+	if !utf8.ValidString(password) {
+		return ErrInvalidUnicode
+	}
+	if utf8.RuneCountInString(password) < p.minLength {
+		return fmt.Errorf("%w: at least %d characters required", ErrTooShort, p.minLength)
+	}
+	if len(password) > MaxLength {
+		return ErrTooLong
+	}
+
+	lower := strings.ToLower(password)
+	if login != "" && strings.Contains(lower, strings.ToLower(login)) {
+		return ErrContainsLogin
+	}
+	if _, ok := p.breached[lower]; ok {
+		return ErrBreached
+	}
+
+	return nil
+}
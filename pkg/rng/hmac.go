@@ -0,0 +1,95 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+)
+
+// hmacStream детерминированный поток случайных чисел для provably-fair режима.
+// Блоки потока: HMAC-SHA256(serverSeed, "clientSeed:nonce:round"), round = 0, 1, 2...
+// Зная serverSeed, clientSeed и nonce, игрок может воспроизвести все числа спина.
+// Не потокобезопасен: создаётся на один спин.
+type hmacStream struct {
+	key        []byte
+	clientSeed string
+	nonce      int
+
+	round int
+	buf   []byte
+	pos   int
+}
+
+// NewHMAC Создать provably-fair поток для спина с указанными сидами и nonce
+func NewHMAC(serverSeed, clientSeed string, nonce int) RNG {
+	return &hmacStream{
+		key:        []byte(serverSeed),
+		clientSeed: clientSeed,
+		nonce:      nonce,
+	}
+}
+
+// GenerateSeed возвращает случайный сид (hex) заданной длины в байтах
+func GenerateSeed(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashSeed возвращает публикуемый заранее хэш серверного сида: hex(SHA-256(serverSeed))
+func HashSeed(serverSeed string) string {
+	h := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(h[:])
+}
+
+func (h *hmacStream) Intn(n int) int {
+	if n <= 0 {
+		panic("rng: invalid argument to Intn")
+	}
+	// Отбрасываем значения из неполного последнего диапазона, чтобы не было смещения по модулю
+	bound := uint64(n)
+	limit := ^uint64(0) - (^uint64(0) % bound)
+	for {
+		v := h.next64()
+		if v < limit {
+			return int(v % bound)
+		}
+	}
+}
+
+func (h *hmacStream) Float64() float64 {
+	// 53 старших бита -> [0, 1)
+	return float64(h.next64()>>11) / (1 << 53)
+}
+
+func (h *hmacStream) Perm(n int) []int {
+	// Тасование Фишера-Йетса
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := h.Intn(i + 1)
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// next64 возвращает следующие 8 байт потока
+func (h *hmacStream) next64() uint64 {
+	if h.pos+8 > len(h.buf) {
+		mac := hmac.New(sha256.New, h.key)
+		mac.Write([]byte(h.clientSeed + ":" + strconv.Itoa(h.nonce) + ":" + strconv.Itoa(h.round)))
+		h.buf = mac.Sum(nil)
+		h.pos = 0
+		h.round++
+	}
+	v := binary.BigEndian.Uint64(h.buf[h.pos : h.pos+8])
+	h.pos += 8
+	return v
+}
@@ -0,0 +1,59 @@
+package rng
+
+import "testing"
+
+// Эталоны посчитаны независимо от пакета (Python: hmac, hashlib):
+// блок потока = HMAC-SHA256("server-seed", "client-seed:7:<round>"), числа — big-endian uint64 подряд
+const (
+	testServerSeed = "server-seed"
+	testClientSeed = "client-seed"
+	testNonce      = 7
+)
+
+func TestHashSeed(t *testing.T) {
+	const want = "91024ec49c5bec0b689e42892526320fce08337205c91de94c7a588c20d08eeb"
+	if got := HashSeed(testServerSeed); got != want {
+		t.Fatalf("HashSeed() = %s, want %s", got, want)
+	}
+}
+
+func TestHMACStreamKnownVector(t *testing.T) {
+	// Пятое число — первое из блока round = 1
+	want := []uint64{0x11d52cc1f80611aa, 0x1234ea30f07dd789, 0x05ad1d409880426f, 0xb6d3654422cfe1f0, 0x8cc398596566f222}
+
+	h := NewHMAC(testServerSeed, testClientSeed, testNonce).(*hmacStream)
+	for i, w := range want {
+		if got := h.next64(); got != w {
+			t.Fatalf("value %d = %#x, want %#x", i, got, w)
+		}
+	}
+}
+
+func TestHMACStreamDerivedValues(t *testing.T) {
+	if got, want := NewHMAC(testServerSeed, testClientSeed, testNonce).Float64(), 0.06965903983657307; got != want {
+		t.Fatalf("Float64() = %v, want %v", got, want)
+	}
+
+	want := []int{2, 1, 5, 2, 2, 1, 0, 1}
+	rnd := NewHMAC(testServerSeed, testClientSeed, testNonce)
+	for i, w := range want {
+		if got := rnd.Intn(6); got != w {
+			t.Fatalf("Intn(6) #%d = %d, want %d", i, got, w)
+		}
+	}
+}
+
+func TestHMACStreamDependsOnEverySeed(t *testing.T) {
+	base := NewHMAC(testServerSeed, testClientSeed, testNonce).(*hmacStream).next64()
+
+	others := map[string]RNG{
+		"server seed": NewHMAC(testServerSeed+"x", testClientSeed, testNonce),
+		"client seed": NewHMAC(testServerSeed, testClientSeed+"x", testNonce),
+		"nonce":       NewHMAC(testServerSeed, testClientSeed, testNonce+1),
+	}
+	for name, rnd := range others {
+		if rnd.(*hmacStream).next64() == base {
+			t.Errorf("changing %s did not change the stream", name)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package rng
+
+import (
+	crand "crypto/rand"
+	"math/rand/v2"
+	"sync"
+)
+
+// RNG Источник случайных чисел для игровой логики
+type RNG interface {
+	// Intn возвращает случайное число в [0, n). Паникует при n <= 0
+	Intn(n int) int
+	// Float64 возвращает случайное число в [0, 1)
+	Float64() float64
+	// Perm возвращает случайную перестановку чисел [0, n)
+	Perm(n int) []int
+}
+
+// source потокобезопасная обёртка над *rand.Rand
+type source struct {
+	mtx sync.Mutex
+	r   *rand.Rand
+}
+
+// NewCrypto Создать генератор для продакшена: ChaCha8, сид которого берётся из crypto/rand
+func NewCrypto() RNG {
+	var seed [32]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		panic("rng: failed to read crypto seed: " + err.Error())
+	}
+	return &source{r: rand.New(rand.NewChaCha8(seed))}
+}
+
+// NewSeeded Создать детерминированный генератор для тестов и симуляций:
+// одинаковый seed даёт одинаковую последовательность
+func NewSeeded(seed uint64) RNG {
+	return &source{r: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+func (s *source) Intn(n int) int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.r.IntN(n)
+}
+
+func (s *source) Float64() float64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.r.Float64()
+}
+
+func (s *source) Perm(n int) []int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.r.Perm(n)
+}
@@ -9,10 +9,15 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateAccessToken(userID int, sessionID string, secretKey []byte, ttl time.Duration) (string, error) {
+// preAuthAudience Назначение токена промежуточного шага входа: access токеном он не принимается
+const preAuthAudience = "two_factor"
+
+func GenerateAccessToken(userID int, sessionID string, role model.Role, twoFactor bool, secretKey []byte, ttl time.Duration) (string, error) {
 	claims := model.UserClaims{
 		UserID:    userID,
 		SessionID: sessionID,
+		Role:      role,
+		TwoFactor: twoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -40,6 +45,50 @@ func VerifyAccessToken(tokenStr string, secretKey []byte) (*model.UserClaims, er
 		return nil, fmt.Errorf("parse token: %w", err)
 	}
 
+	if !token.Valid {
+		return nil, errors.New("token is not valid")
+	}
+	if len(claims.Audience) != 0 {
+		return nil, errors.New("not an access token")
+	}
+
+	return claims, nil
+}
+
+// GeneratePreAuthToken токен, подтверждающий проверку пароля, для второго шага входа
+func GeneratePreAuthToken(userID int, secretKey []byte, ttl time.Duration) (string, error) {
+	claims := model.PreAuthClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{preAuthAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secretKey)
+}
+
+func VerifyPreAuthToken(tokenStr string, secretKey []byte) (*model.PreAuthClaims, error) {
+	claims := &model.PreAuthClaims{}
+
+	token, err := jwt.ParseWithClaims(
+		tokenStr,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			return secretKey, nil
+		},
+		jwt.WithAudience(preAuthAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+
 	if !token.Valid {
 		return nil, errors.New("token is not valid")
 	}
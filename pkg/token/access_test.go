@@ -0,0 +1,66 @@
+package token
+
+import (
+	"casino_backend/internal/model"
+	"testing"
+	"time"
+)
+
+var testSecretKey = []byte("test-secret")
+
+func TestVerifyAccessToken(t *testing.T) {
+	access, err := GenerateAccessToken(7, "session", model.RolePlayer, true, testSecretKey, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+
+	claims, err := VerifyAccessToken(access, testSecretKey)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if claims.UserID != 7 || claims.SessionID != "session" || claims.Role != model.RolePlayer || !claims.TwoFactor {
+		t.Fatalf("VerifyAccessToken() claims = %+v", claims)
+	}
+
+	if _, err = VerifyAccessToken(access, []byte("other-secret")); err == nil {
+		t.Fatal("VerifyAccessToken accepted a token signed with another key")
+	}
+
+	expired, err := GenerateAccessToken(7, "session", model.RolePlayer, true, testSecretKey, -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	if _, err = VerifyAccessToken(expired, testSecretKey); err == nil {
+		t.Fatal("VerifyAccessToken accepted an expired token")
+	}
+}
+
+// TestVerifyAccessTokenRejectsPreAuth токен второго шага входа (aud two_factor) подписан тем же ключом,
+// но вместо access токена не принимается
+func TestVerifyAccessTokenRejectsPreAuth(t *testing.T) {
+	preAuth, err := GeneratePreAuthToken(7, testSecretKey, time.Minute)
+	if err != nil {
+		t.Fatalf("GeneratePreAuthToken: %v", err)
+	}
+
+	if _, err = VerifyAccessToken(preAuth, testSecretKey); err == nil {
+		t.Fatal("VerifyAccessToken accepted a pre-auth token")
+	}
+
+	claims, err := VerifyPreAuthToken(preAuth, testSecretKey)
+	if err != nil {
+		t.Fatalf("VerifyPreAuthToken: %v", err)
+	}
+	if claims.UserID != 7 {
+		t.Fatalf("VerifyPreAuthToken() user = %d, want 7", claims.UserID)
+	}
+
+	// И наоборот: access токен не подходит для второго шага входа
+	access, err := GenerateAccessToken(7, "session", model.RolePlayer, false, testSecretKey, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	if _, err = VerifyPreAuthToken(access, testSecretKey); err == nil {
+		t.Fatal("VerifyPreAuthToken accepted an access token")
+	}
+}
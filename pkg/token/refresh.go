@@ -29,3 +29,12 @@ func VerifyRefreshToken(token string, hash string) bool {
 		[]byte(hash),
 	) == 1
 }
+
+// GenerateResetToken одноразовый токен сброса пароля; в БД хранится только его хэш (HashResetToken)
+func GenerateResetToken() (string, error) {
+	return GenerateRefreshToken()
+}
+
+func HashResetToken(token string) string {
+	return HashRefreshToken(token)
+}
@@ -0,0 +1,103 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret ключ SHA1 из приложения B RFC 6238 ("12345678901234567890") в base32
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+// TestGenerateRFC6238 векторы из приложения B RFC 6238 (SHA1), усечённые до 6 цифр
+func TestGenerateRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string // 8 цифр из RFC: 94287082, 07081804, ...
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	key := []byte("12345678901234567890")
+	for _, tt := range tests {
+		step := tt.unix / int64(Period.Seconds())
+		if got := generate(key, step); got != tt.want {
+			t.Errorf("generate(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+
+		got, ok := Validate(rfcSecret, tt.want, time.Unix(tt.unix, 0))
+		if !ok || got != step {
+			t.Errorf("Validate(T=%d) = %d, %v, want %d, true", tt.unix, got, ok, step)
+		}
+	}
+}
+
+// TestValidateSkew код принимается в соседнем периоде и отклоняется через один
+func TestValidateSkew(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / int64(Period.Seconds())
+
+	tests := []struct {
+		name  string
+		shift int64
+		ok    bool
+	}{
+		{"previous period", -1, true},
+		{"current period", 0, true},
+		{"next period", 1, true},
+		{"two periods ago", -2, false},
+		{"two periods ahead", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := generate(key, current+tt.shift)
+			step, ok := Validate(rfcSecret, code, now)
+			if ok != tt.ok {
+				t.Fatalf("Validate() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && step != current+tt.shift {
+				t.Fatalf("Validate() step = %d, want %d", step, current+tt.shift)
+			}
+		})
+	}
+
+	// Граница периода: код предыдущего периода ещё принимается в последнюю секунду следующего
+	edge := time.Unix((current+1)*int64(Period.Seconds())+int64(Period.Seconds())-1, 0)
+	if _, ok := Validate(rfcSecret, generate(key, current), edge); !ok {
+		t.Error("code of the previous period rejected at the end of the next one")
+	}
+	if _, ok := Validate(rfcSecret, generate(key, current), edge.Add(time.Second)); ok {
+		t.Error("code accepted two periods later")
+	}
+}
+
+func TestValidateRejectsMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name, secret, code string
+	}{
+		{"short code", rfcSecret, "28708"},
+		{"8 digit code", rfcSecret, "94287082"},
+		{"bad secret", "not base32!", "287082"},
+		{"wrong code", rfcSecret, "287083"},
+	}
+
+	for _, tt := range tests {
+		if _, ok := Validate(tt.secret, tt.code, now); ok {
+			t.Errorf("%s: Validate() accepted %q", tt.name, tt.code)
+		}
+	}
+
+	// Секрет вводят и в нижнем регистре
+	if _, ok := Validate(strings.ToLower(rfcSecret), "287082", now); !ok {
+		t.Error("lower-case secret rejected")
+	}
+}